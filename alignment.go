@@ -0,0 +1,89 @@
+package quickfilter
+
+// Alignment maps the indices of two slices that contain the same elements in
+// different orders, such as differently sorted copies of a dataset, by a key
+// shared by the corresponding elements. It allows translating a selection
+// computed on one slice into the corresponding selection on the other.
+type Alignment struct {
+	aToB []int
+	bToA []int
+}
+
+// NewAlignment returns a new Alignment between slice A of length lenA and
+// slice B of length lenB, using keyA and keyB to extract the key of the
+// element at index i of each slice.
+//
+// The keys must be comparable, as they are used as map keys, and are
+// expected to be unique within each slice. If a key occurs more than once,
+// its first occurrence is used. Elements whose key doesn't occur in the other
+// slice have no counterpart.
+func NewAlignment(lenA int, keyA func(i int) interface{}, lenB int, keyB func(i int) interface{}) Alignment {
+	al := Alignment{
+		aToB: make([]int, lenA),
+		bToA: make([]int, lenB),
+	}
+	indices := make(map[interface{}]int, lenA)
+	for i := lenA - 1; i >= 0; i-- {
+		indices[keyA(i)] = i
+		al.aToB[i] = -1
+	}
+	for j := 0; j < lenB; j++ {
+		al.bToA[j] = -1
+		key := keyB(j)
+		i, ok := indices[key]
+		if !ok {
+			continue
+		}
+		delete(indices, key)
+		al.aToB[i] = j
+		al.bToA[j] = i
+	}
+	return al
+}
+
+// IndexInB returns the index in slice B corresponding to index i of slice A,
+// and a boolean indicating whether there is one.
+func (al Alignment) IndexInB(i int) (int, bool) {
+	j := al.aToB[i]
+	return j, j >= 0
+}
+
+// IndexInA returns the index in slice A corresponding to index j of slice B,
+// and a boolean indicating whether there is one.
+func (al Alignment) IndexInA(j int) (int, bool) {
+	i := al.bToA[j]
+	return i, i >= 0
+}
+
+// AToB fills dst with the indices of slice B corresponding to the indices of
+// slice A set in qf. Indices without a counterpart are left out. dst is
+// resized to the length of slice B.
+//
+// The passed qf must have a Cap() equal to the length of slice A or this will
+// panic.
+func (al Alignment) AToB(dst, qf QuickFilter) QuickFilter {
+	return translate(dst, qf, al.aToB, len(al.bToA))
+}
+
+// BToA fills dst with the indices of slice A corresponding to the indices of
+// slice B set in qf. Indices without a counterpart are left out. dst is
+// resized to the length of slice A.
+//
+// The passed qf must have a Cap() equal to the length of slice B or this will
+// panic.
+func (al Alignment) BToA(dst, qf QuickFilter) QuickFilter {
+	return translate(dst, qf, al.bToA, len(al.aToB))
+}
+
+func translate(dst, qf QuickFilter, mapping []int, dstLen int) QuickFilter {
+	if qf.sourceLen != len(mapping) {
+		panic("QuickFilter must be the same size as the aligned slice")
+	}
+	dst = dst.Resize(dstLen).Clear()
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		if index := mapping[it.Value()]; index >= 0 {
+			dst = dst.Add(index)
+		}
+	}
+	return dst
+}
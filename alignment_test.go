@@ -0,0 +1,49 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestAlignment(t *testing.T) {
+	a := []string{"x", "y", "z", "w"}
+	b := []string{"z", "v", "x", "y"}
+	al := quickfilter.NewAlignment(
+		len(a), func(i int) interface{} { return a[i] },
+		len(b), func(j int) interface{} { return b[j] },
+	)
+
+	t.Run("AToB", func(t *testing.T) {
+		expected := []int{0, 2}
+
+		received := collect(al.AToB(quickfilter.New(0), quickfilter.New(len(a)).Add(0).Add(2).Add(3)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("BToA", func(t *testing.T) {
+		expected := []int{1, 2}
+
+		received := collect(al.BToA(quickfilter.New(0), quickfilter.New(len(b)).Add(0).Add(1).Add(3)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("IndexInB and IndexInA", func(t *testing.T) {
+		if j, ok := al.IndexInB(1); !ok || j != 3 {
+			t.Errorf("expected (3, true), got (%d, %v)", j, ok)
+		}
+		if _, ok := al.IndexInB(3); ok {
+			t.Error("expected no counterpart")
+		}
+		if i, ok := al.IndexInA(0); !ok || i != 2 {
+			t.Errorf("expected (2, true), got (%d, %v)", i, ok)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package quickfilter
+
+import (
+	"math"
+	"math/bits"
+)
+
+// analyzeSampleWords is the maximum number of words that Analyze reads.
+const analyzeSampleWords = 1024
+
+// Backend is a representation of a set of indices.
+type Backend int
+
+const (
+	// BackendWords is a QuickFilter, with one bit per index.
+	BackendWords Backend = iota
+	// BackendRuns is an IntervalSet, with two ints per run of set indices.
+	BackendRuns
+	// BackendSparse is a sorted array of 32-bit indices, such as produced by
+	// AppendSelection32.
+	BackendSparse
+	// BackendRoaring divides the indices into chunks of 65536 that are each
+	// stored as either a sorted array of 16-bit offsets or a bitmap, like
+	// QuickFilter64. The projected size includes a 16 byte header per chunk
+	// and assumes that the set indices are spread evenly over the chunks.
+	BackendRoaring
+)
+
+func (b Backend) String() string {
+	switch b {
+	case BackendWords:
+		return "words"
+	case BackendRuns:
+		return "runs"
+	case BackendSparse:
+		return "sparse"
+	case BackendRoaring:
+		return "roaring"
+	default:
+		return "unknown"
+	}
+}
+
+// Analysis is an estimate of the structure of a QuickFilter and the memory
+// it would use in each Backend.
+type Analysis struct {
+	// Sampled reports whether the estimates are extrapolated from a sample
+	// of the words rather than computed from all of them.
+	Sampled bool
+	// Len is the estimated number of set values.
+	Len int
+	// Runs is the estimated number of runs of set values.
+	Runs int
+	// EntropyBytes is the size in bytes of an ideal encoding of the
+	// QuickFilter, assuming that each index is set independently with the
+	// same probability.
+	EntropyBytes int
+	// Bytes is the projected size in bytes of the QuickFilter in each
+	// Backend.
+	Bytes [4]int
+	// Recommended is the Backend with the smallest projected size,
+	// preferring BackendWords, which is the fastest to operate on, in case
+	// of a tie.
+	Recommended Backend
+}
+
+// Analyze estimates the structure of the QuickFilter and recommends the
+// Backend that would use the least memory for it, for choosing a
+// representation grounded in real data.
+//
+// At most 1024 words are read, spread evenly over the QuickFilter, so the
+// cost is constant regardless of the size of the QuickFilter.
+func (qf QuickFilter) Analyze() Analysis {
+	var a Analysis
+	n := len(qf.bits)
+	stride := 1
+	if n > analyzeSampleWords {
+		stride = (n + analyzeSampleWords - 1) / analyzeSampleWords
+		a.Sampled = true
+	}
+	ones, runs, sampled := 0, 0, 0
+	last := n - 1
+	for i := 0; i < n; i += stride {
+		word := qf.bits[i]
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		carry := uint(0)
+		if i > 0 {
+			carry = qf.bits[i-1] >> (bits.UintSize - 1)
+		}
+		ones += bits.OnesCount(word)
+		runs += bits.OnesCount(word &^ (word<<1 | carry))
+		sampled++
+	}
+	scale := float64(n) / float64(sampled)
+	a.Len = int(math.Round(float64(ones) * scale))
+	if a.Len > qf.sourceLen {
+		a.Len = qf.sourceLen
+	}
+	a.Runs = int(math.Round(float64(runs) * scale))
+	if a.Runs > a.Len {
+		a.Runs = a.Len
+	}
+	if qf.sourceLen > 0 {
+		p := float64(a.Len) / float64(qf.sourceLen)
+		a.EntropyBytes = int(math.Ceil(float64(qf.sourceLen) * binaryEntropy(p) / 8))
+	}
+	a.Bytes[BackendWords] = n * bits.UintSize / 8
+	a.Bytes[BackendRuns] = a.Runs * 2 * bits.UintSize / 8
+	a.Bytes[BackendSparse] = a.Len * 4
+	chunks := (qf.sourceLen + 1<<16 - 1) >> 16
+	if chunks > 0 {
+		chunkBytes := 2 * a.Len / chunks
+		if chunkBytes > 1<<13 {
+			chunkBytes = 1 << 13
+		}
+		a.Bytes[BackendRoaring] = chunks * (16 + chunkBytes)
+	}
+	for b, size := range a.Bytes {
+		if size < a.Bytes[a.Recommended] {
+			a.Recommended = Backend(b)
+		}
+	}
+	return a
+}
+
+// binaryEntropy returns the entropy in bits of an event with probability p.
+func binaryEntropy(p float64) float64 {
+	if p <= 0 || p >= 1 {
+		return 0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
@@ -0,0 +1,56 @@
+package quickfilter_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestAnalyze(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name     string
+		qf       quickfilter.QuickFilter
+		expected quickfilter.Backend
+	}{
+		{"dense", quickfilter.NewRandom(10000, 0.5, rng), quickfilter.BackendWords},
+		{"long run", quickfilter.NewRange(100000, 1000, 90000), quickfilter.BackendRuns},
+		{"very sparse", quickfilter.New(60000).Add(10).Add(3000).Add(50000), quickfilter.BackendSparse},
+		{"sparse", quickfilter.NewEveryNth(60000, 300, 7), quickfilter.BackendRoaring},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := tt.qf.Analyze().Recommended
+
+			if tt.expected != received {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		qf := quickfilter.New(1000).Add(1).Add(2).Add(3).Add(63).Add(64).Add(500)
+		expectedLen, expectedRuns, expectedEntropyBytes := 6, 3, 7
+		expectedSparse, expectedRoaring := 24, 28
+
+		received := qf.Analyze()
+
+		if received.Sampled || expectedLen != received.Len || expectedRuns != received.Runs || expectedEntropyBytes != received.EntropyBytes {
+			t.Errorf("expected exact estimates, got %+v", received)
+		}
+		if expectedSparse != received.Bytes[quickfilter.BackendSparse] || expectedRoaring != received.Bytes[quickfilter.BackendRoaring] {
+			t.Errorf("expected %d and %d, got %v", expectedSparse, expectedRoaring, received.Bytes)
+		}
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		qf := quickfilter.NewRandom(1<<20, 0.3, rng)
+
+		received := qf.Analyze()
+
+		if !received.Sampled || received.Len < qf.Len()*95/100 || received.Len > qf.Len()*105/100 {
+			t.Errorf("expected a sampled estimate of about %d, got %+v", qf.Len(), received)
+		}
+	})
+}
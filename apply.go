@@ -0,0 +1,65 @@
+package quickfilter
+
+import (
+	"strings"
+)
+
+// ApplyBytes returns the elements of src whose index is set in qf, in
+// ascending order. The returned elements share their underlying byte data
+// with src, so only the outer slice is allocated, with an exact size.
+//
+// src must have a length equal to Cap() or this will panic.
+func ApplyBytes(qf QuickFilter, src [][]byte) [][]byte {
+	if len(src) != qf.sourceLen {
+		panic("src must be the same size as the QuickFilter")
+	}
+	result := make([][]byte, 0, qf.len)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		result = append(result, src[it.Value()])
+	}
+	return result
+}
+
+// ApplyStrings returns the elements of src whose index is set in qf, in
+// ascending order, like ApplyBytes.
+//
+// src must have a length equal to Cap() or this will panic.
+func ApplyStrings(qf QuickFilter, src []string) []string {
+	if len(src) != qf.sourceLen {
+		panic("src must be the same size as the QuickFilter")
+	}
+	result := make([]string, 0, qf.len)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		result = append(result, src[it.Value()])
+	}
+	return result
+}
+
+// JoinFiltered concatenates the elements of parts whose index is set in qf,
+// in ascending order, with sep between them, like strings.Join.
+//
+// The length of the result is computed in a first pass, so the result is
+// written with a single allocation.
+//
+// parts must have a length equal to Cap() or this will panic.
+func JoinFiltered(qf QuickFilter, parts []string, sep string) string {
+	if len(parts) != qf.sourceLen {
+		panic("parts must be the same size as the QuickFilter")
+	}
+	if qf.len == 0 {
+		return ""
+	}
+	n := len(sep) * (qf.len - 1)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		n += len(parts[it.Value()])
+	}
+	var b strings.Builder
+	b.Grow(n)
+	it := qf.Iterate()
+	b.WriteString(parts[it.Value()])
+	for it = it.Next(); !it.Done(); it = it.Next() {
+		b.WriteString(sep)
+		b.WriteString(parts[it.Value()])
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestApplyBytes(t *testing.T) {
+	t.Run("should share the underlying data", func(t *testing.T) {
+		data := []byte("zeroonetwo")
+		src := [][]byte{data[0:4], data[4:7], data[7:10]}
+		qf := quickfilter.New(3).Add(0).Add(2)
+		expected := [][]byte{[]byte("zero"), []byte("two")}
+
+		received := quickfilter.ApplyBytes(qf, src)
+		data[7] = 'T'
+
+		if len(received) != 2 || cap(received) != 2 || string(received[1]) != "Two" {
+			t.Errorf("expected %q sharing data, got %q", expected, received)
+		}
+	})
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.ApplyBytes(quickfilter.New(3), [][]byte{nil})
+	})
+}
+
+func TestApplyStrings(t *testing.T) {
+	src := []string{"zero", "one", "two", "three"}
+	qf := quickfilter.New(4).Add(1).Add(3)
+	expected := []string{"one", "three"}
+
+	received := quickfilter.ApplyStrings(qf, src)
+
+	if !reflect.DeepEqual(expected, received) || cap(received) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, received)
+	}
+}
+
+func TestJoinFiltered(t *testing.T) {
+	parts := []string{"zero", "one", "two", "three"}
+	tests := []struct {
+		name     string
+		qf       quickfilter.QuickFilter
+		expected string
+	}{
+		{"empty", quickfilter.New(4), ""},
+		{"one", quickfilter.New(4).Add(2), "two"},
+		{"many", quickfilter.New(4).Add(0).Add(1).Add(3), "zero, one, three"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := quickfilter.JoinFiltered(tt.qf, parts, ", ")
+
+			if tt.expected != received {
+				t.Errorf("expected %q, got %q", tt.expected, received)
+			}
+		})
+	}
+
+	t.Run("should allocate once", func(t *testing.T) {
+		qf := quickfilter.New(4).Add(0).Add(1).Add(3)
+		expected := 1.0
+
+		received := testing.AllocsPerRun(100, func() {
+			quickfilter.JoinFiltered(qf, parts, ", ")
+		})
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
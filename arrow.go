@@ -0,0 +1,46 @@
+package quickfilter
+
+import "math"
+
+// AppendArrowBitmap appends the QuickFilter to dst in the bit-packed layout
+// of the data buffer of an Apache Arrow boolean array, where the value at
+// index i is bit i%8 of byte i/8. The result can be wrapped in a boolean
+// array of length Cap() and passed to the filter kernel of an Arrow compute
+// library to produce a filtered record batch, for example with the Go Arrow
+// implementation:
+//
+//	buf := memory.NewBufferBytes(qf.AppendArrowBitmap(nil))
+//	data := array.NewData(arrow.FixedWidthTypes.Boolean, qf.Cap(), []*memory.Buffer{nil, buf}, nil, 0, 0)
+//	filtered, err := compute.FilterRecordBatch(ctx, batch, array.NewBooleanData(data), compute.DefaultFilterOptions())
+//
+// The bits beyond Cap() in the last byte are zeroed. Arrow recommends that
+// buffers are padded to 64 bytes, which is left to the caller.
+func (qf QuickFilter) AppendArrowBitmap(dst []byte) []byte {
+	return appendBits(dst, qf)
+}
+
+// AppendSelection32 appends the set indices to dst as 32-bit integers, in
+// ascending order. This is the layout of an Arrow Int32 array and of the
+// selection vectors used by Arrow-based engines for taking rows from a
+// record batch.
+//
+// The Cap() of the QuickFilter must not exceed the range of int32 or this
+// will panic.
+func (qf QuickFilter) AppendSelection32(dst []int32) []int32 {
+	if int64(qf.sourceLen)-1 > math.MaxInt32 {
+		panic("Cap() must not exceed the range of int32")
+	}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		dst = append(dst, int32(it.Value()))
+	}
+	return dst
+}
+
+// AppendSelection64 appends the set indices to dst as 64-bit integers, in
+// ascending order, like AppendSelection32.
+func (qf QuickFilter) AppendSelection64(dst []int64) []int64 {
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		dst = append(dst, int64(it.Value()))
+	}
+	return dst
+}
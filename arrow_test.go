@@ -0,0 +1,74 @@
+package quickfilter_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestArrow(t *testing.T) {
+	newQuickFilter := func() quickfilter.QuickFilter {
+		return quickfilter.New(10).Add(0).Add(3).Add(8).Add(9)
+	}
+
+	t.Run("AppendArrowBitmap", func(t *testing.T) {
+		qf := newQuickFilter()
+		expected := []byte{0xff, 0x09, 0x03}
+
+		received := qf.AppendArrowBitmap([]byte{0xff})
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AppendArrowBitmap masks trailing bits", func(t *testing.T) {
+		qf := quickfilter.NewFilled(10)
+		expected := []byte{0xff, 0x03}
+
+		received := qf.AppendArrowBitmap(nil)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AppendSelection32", func(t *testing.T) {
+		qf := newQuickFilter()
+		expected := []int32{0, 3, 8, 9}
+
+		received := qf.AppendSelection32(nil)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AppendSelection32 with Cap beyond int32 should panic", func(t *testing.T) {
+		sourceLen := int64(math.MaxInt32) + 2
+		if int64(int(sourceLen)) != sourceLen {
+			t.Skip("int is 32 bits")
+		}
+		qf := quickfilter.New(int(sourceLen))
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		qf.AppendSelection32(nil)
+	})
+
+	t.Run("AppendSelection64", func(t *testing.T) {
+		qf := newQuickFilter()
+		expected := []int64{0, 3, 8, 9}
+
+		received := qf.AppendSelection64(nil)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package quickfilter
+
+// BitmapIndex is an in-memory index over the fields of a source slice. For
+// every distinct value of an indexed field, it stores a QuickFilter of the
+// elements that have that value, so queries can be answered by combining
+// these bitmaps with Expr rather than by scanning the source slice.
+//
+// BitmapIndex is intended for read-mostly datasets: it is built once from
+// the source slice and needs to be rebuilt when the slice changes.
+type BitmapIndex struct {
+	sourceLen int
+	empty     QuickFilter
+	fields    map[string]map[interface{}]QuickFilter
+}
+
+// NewBitmapIndex returns a new BitmapIndex without any fields for a source
+// slice of length sourceLen.
+func NewBitmapIndex(sourceLen int) BitmapIndex {
+	return BitmapIndex{
+		sourceLen: sourceLen,
+		empty:     New(sourceLen),
+		fields:    make(map[string]map[interface{}]QuickFilter),
+	}
+}
+
+// Cap returns the length of the indexed source slice.
+func (bi BitmapIndex) Cap() int {
+	return bi.sourceLen
+}
+
+// AddField indexes a field using value to extract the value of the field for
+// the element at index i of the source slice, for example:
+//
+//	bi = bi.AddField("status", func(i int) interface{} { return users[i].Status })
+//
+// The returned values must be comparable, as they are used as map keys.
+// Indexing a field with an existing name replaces the old one.
+//
+// The original BitmapIndex is no longer usable and must be replaced with the
+// returned one.
+func (bi BitmapIndex) AddField(name string, value func(i int) interface{}) BitmapIndex {
+	values := make(map[interface{}]QuickFilter)
+	for i := 0; i < bi.sourceLen; i++ {
+		v := value(i)
+		qf, ok := values[v]
+		if !ok {
+			qf = New(bi.sourceLen)
+		}
+		values[v] = qf.Add(i)
+	}
+	bi.fields[name] = values
+	return bi
+}
+
+// Values returns the distinct values of the field in unspecified order.
+//
+// Passing a field name that is not indexed will panic.
+func (bi BitmapIndex) Values(field string) []interface{} {
+	values := bi.mustField(field)
+	result := make([]interface{}, 0, len(values))
+	for v := range values {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Get returns the QuickFilter of the elements whose field has the given
+// value. The returned QuickFilter is owned by the BitmapIndex and must not be
+// modified.
+//
+// Passing a field name that is not indexed will panic.
+func (bi BitmapIndex) Get(field string, value interface{}) QuickFilter {
+	if qf, ok := bi.mustField(field)[value]; ok {
+		return qf
+	}
+	return bi.empty
+}
+
+// Eq returns an Expr that evaluates to the elements whose field has the
+// given value.
+//
+// Passing a field name that is not indexed will panic.
+func (bi BitmapIndex) Eq(field string, value interface{}) Expr {
+	return Leaf(bi.Get(field, value))
+}
+
+// In returns an Expr that evaluates to the elements whose field has any of
+// the given values.
+//
+// Passing a field name that is not indexed will panic.
+func (bi BitmapIndex) In(field string, values ...interface{}) Expr {
+	operands := make([]Expr, 0, len(values))
+	for _, value := range values {
+		operands = append(operands, bi.Eq(field, value))
+	}
+	return Or(operands...)
+}
+
+func (bi BitmapIndex) mustField(field string) map[interface{}]QuickFilter {
+	values, ok := bi.fields[field]
+	if !ok {
+		panic("unknown field in BitmapIndex: " + field)
+	}
+	return values
+}
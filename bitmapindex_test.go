@@ -0,0 +1,111 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+type mockUser struct {
+	name   string
+	status string
+	region string
+	tier   int
+}
+
+var mockUsers = []mockUser{
+	{"alice", "active", "eu", 1},
+	{"bob", "inactive", "eu", 2},
+	{"carol", "active", "us", 2},
+	{"dave", "active", "eu", 3},
+	{"erin", "inactive", "us", 1},
+}
+
+func newUserIndex() quickfilter.BitmapIndex {
+	bi := quickfilter.NewBitmapIndex(len(mockUsers))
+	bi = bi.AddField("status", func(i int) interface{} { return mockUsers[i].status })
+	bi = bi.AddField("region", func(i int) interface{} { return mockUsers[i].region })
+	bi = bi.AddField("tier", func(i int) interface{} { return mockUsers[i].tier })
+	return bi
+}
+
+func TestBitmapIndex(t *testing.T) {
+	t.Run("Eq", func(t *testing.T) {
+		bi := newUserIndex()
+		expected := []int{0, 2, 3}
+
+		received := collect(bi.Eq("status", "active").Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Eq with unknown value", func(t *testing.T) {
+		bi := newUserIndex()
+		expectedLen := 0
+
+		receivedLen := bi.Eq("region", "apac").Eval(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("In", func(t *testing.T) {
+		bi := newUserIndex()
+		expected := []int{1, 2, 3}
+
+		received := collect(bi.In("tier", 2, 3).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Eq and Eq", func(t *testing.T) {
+		bi := newUserIndex()
+		expected := []int{0, 3}
+
+		received := collect(bi.Eq("status", "active").And(bi.Eq("region", "eu")).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		bi := newUserIndex()
+		expectedLen := 3
+
+		receivedLen := len(bi.Values("tier"))
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("unknown field should panic", func(t *testing.T) {
+		bi := newUserIndex()
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		bi.Eq("email", "alice@example.com")
+	})
+}
+
+func ExampleBitmapIndex() {
+	bi := newUserIndex()
+	qf := bi.Eq("status", "active").And(bi.Eq("region", "eu")).Eval(quickfilter.New(0))
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		fmt.Println(mockUsers[it.Value()].name)
+	}
+	// Output:
+	// alice
+	// dave
+}
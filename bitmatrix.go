@@ -0,0 +1,246 @@
+package quickfilter
+
+// BitMatrix is a two-dimensional filter of rows × cols bits, such as a
+// (user × feature) or (document × label) relation. The bits are stored in a
+// single flat backing array with each row aligned to a word boundary, which
+// allows the rows to be viewed as QuickFilters without copying.
+type BitMatrix struct {
+	rows     int
+	cols     int
+	rowWords int
+	bits     []uint
+}
+
+// NewBitMatrix returns a new BitMatrix with enough space reserved to store
+// rows × cols bits.
+func NewBitMatrix(rows, cols int) BitMatrix {
+	lastIndex, _ := offsets(cols - 1)
+	rowWords := lastIndex + 1
+	return BitMatrix{
+		rows:     rows,
+		cols:     cols,
+		rowWords: rowWords,
+		bits:     make([]uint, rows*rowWords),
+	}
+}
+
+// Rows returns the number of rows in the BitMatrix.
+func (m BitMatrix) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns in the BitMatrix.
+func (m BitMatrix) Cols() int {
+	return m.cols
+}
+
+// Add sets the bit at the given row and column.
+//
+// The row and column must be within Rows() and Cols() or this will panic
+// with an *IndexError.
+//
+// The original BitMatrix is no longer usable and must be replaced with the
+// returned one. This approach prevents the BitMatrix from escaping to the
+// heap.
+func (m BitMatrix) Add(row, col int) BitMatrix {
+	index, mask := m.offsets(row, col)
+	m.bits[index] |= mask
+	return m
+}
+
+// Delete clears the bit at the given row and column.
+//
+// The row and column must be within Rows() and Cols() or this will panic
+// with an *IndexError.
+//
+// The original BitMatrix is no longer usable and must be replaced with the
+// returned one. This approach prevents the BitMatrix from escaping to the
+// heap.
+func (m BitMatrix) Delete(row, col int) BitMatrix {
+	index, mask := m.offsets(row, col)
+	m.bits[index] &^= mask
+	return m
+}
+
+// Has returns a boolean indicating whether the BitMatrix has the bit at the
+// given row and column set.
+//
+// The row and column must be within Rows() and Cols() or this will panic
+// with an *IndexError.
+func (m BitMatrix) Has(row, col int) bool {
+	index, mask := m.offsets(row, col)
+	return m.bits[index]&mask > 0
+}
+
+// Clear all the bits in the BitMatrix.
+//
+// The original BitMatrix is no longer usable and must be replaced with the
+// returned one. This approach prevents the BitMatrix from escaping to the
+// heap.
+func (m BitMatrix) Clear() BitMatrix {
+	for i := range m.bits {
+		m.bits[i] = 0
+	}
+	return m
+}
+
+// Row returns a QuickFilter view of the given row. The view shares its
+// storage with the BitMatrix, so changes made through either are visible in
+// both, but the Len() of the view is only kept up to date for changes made
+// through the view itself.
+//
+// Resizing the view detaches it from the BitMatrix.
+//
+// The row must be within Rows() or this will panic with an *IndexError.
+func (m BitMatrix) Row(row int) QuickFilter {
+	m.mustRow(row)
+	start := row * m.rowWords
+	end := start + m.rowWords
+	return QuickFilter{
+		sourceLen: m.cols,
+		bits:      m.bits[start:end:end],
+	}.Recount()
+}
+
+// SetRow replaces the contents of the given row with the set values of qf.
+//
+// The row must be within Rows() or this will panic with an *IndexError, and
+// the passed QuickFilter must have a Cap() equal to Cols() or this will
+// panic.
+//
+// The original BitMatrix is no longer usable and must be replaced with the
+// returned one. This approach prevents the BitMatrix from escaping to the
+// heap.
+func (m BitMatrix) SetRow(row int, qf QuickFilter) BitMatrix {
+	if qf.sourceLen != m.cols {
+		panic("QuickFilter must be the same size as the BitMatrix rows")
+	}
+	m.mustRow(row)
+	start := row * m.rowWords
+	copy(m.bits[start:start+m.rowWords], qf.bits)
+	m.bits[start+m.rowWords-1] &= lastWordMask(m.cols)
+	return m
+}
+
+// Column fills dst with the rows that have the given column set. dst is
+// resized to Rows().
+//
+// The column must be within Cols() or this will panic with an *IndexError.
+func (m BitMatrix) Column(dst QuickFilter, col int) QuickFilter {
+	m.mustCol(col)
+	dst = dst.Resize(m.rows).Clear()
+	wordIndex, mask := offsets(col)
+	for row := 0; row < m.rows; row++ {
+		if m.bits[row*m.rowWords+wordIndex]&mask > 0 {
+			dst = dst.Add(row)
+		}
+	}
+	return dst
+}
+
+// UnionOfRows fills dst with the columns set in any of the rows set in rows.
+// dst is resized to Cols().
+func (m BitMatrix) UnionOfRows(dst, rows QuickFilter) QuickFilter {
+	dst = dst.Resize(m.cols).Clear()
+	for it := rows.Iterate(); !it.Done(); it = it.Next() {
+		row := m.rowBits(it.Value())
+		for i := range dst.bits {
+			dst.bits[i] |= row[i]
+		}
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(m.cols)
+	return dst.Recount()
+}
+
+// IntersectionOfRows fills dst with the columns set in all of the rows set in
+// rows. dst is resized to Cols(). If rows is empty, so is the result.
+func (m BitMatrix) IntersectionOfRows(dst, rows QuickFilter) QuickFilter {
+	dst = dst.Resize(m.cols)
+	if rows.Len() == 0 {
+		return dst.Clear()
+	}
+	dst = dst.Fill()
+	for it := rows.Iterate(); !it.Done(); it = it.Next() {
+		row := m.rowBits(it.Value())
+		for i := range dst.bits {
+			dst.bits[i] &= row[i]
+		}
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(m.cols)
+	return dst.Recount()
+}
+
+// UnionOfColumns fills dst with the rows that have any of the columns set in
+// cols set. dst is resized to Rows().
+//
+// The passed cols QuickFilter must have a Cap() equal to Cols() or this will
+// panic.
+func (m BitMatrix) UnionOfColumns(dst, cols QuickFilter) QuickFilter {
+	if cols.sourceLen != m.cols {
+		panic("QuickFilter must be the same size as the BitMatrix rows")
+	}
+	dst = dst.Resize(m.rows).Clear()
+	lastMask := lastWordMask(m.cols)
+	for r := 0; r < m.rows; r++ {
+		row := m.rowBits(r)
+		last := len(row) - 1
+		found := row[last]&cols.bits[last]&lastMask != 0
+		for i := 0; !found && i < last; i++ {
+			found = row[i]&cols.bits[i] != 0
+		}
+		if found {
+			dst = dst.Add(r)
+		}
+	}
+	return dst
+}
+
+// IntersectionOfColumns fills dst with the rows that have all of the columns
+// set in cols set. dst is resized to Rows().
+//
+// The passed cols QuickFilter must have a Cap() equal to Cols() or this will
+// panic.
+func (m BitMatrix) IntersectionOfColumns(dst, cols QuickFilter) QuickFilter {
+	if cols.sourceLen != m.cols {
+		panic("QuickFilter must be the same size as the BitMatrix rows")
+	}
+	dst = dst.Resize(m.rows).Clear()
+	lastMask := lastWordMask(m.cols)
+	for r := 0; r < m.rows; r++ {
+		row := m.rowBits(r)
+		last := len(row) - 1
+		want := cols.bits[last] & lastMask
+		found := row[last]&want == want
+		for i := 0; found && i < last; i++ {
+			found = row[i]&cols.bits[i] == cols.bits[i]
+		}
+		if found {
+			dst = dst.Add(r)
+		}
+	}
+	return dst
+}
+
+func (m BitMatrix) rowBits(row int) []uint {
+	start := row * m.rowWords
+	return m.bits[start : start+m.rowWords]
+}
+
+func (m BitMatrix) offsets(row, col int) (index int, mask uint) {
+	m.mustRow(row)
+	m.mustCol(col)
+	index, mask = offsets(col)
+	return row*m.rowWords + index, mask
+}
+
+func (m BitMatrix) mustRow(row int) {
+	if uint(row) >= uint(m.rows) {
+		panic(&IndexError{Index: row, Cap: m.rows})
+	}
+}
+
+func (m BitMatrix) mustCol(col int) {
+	if uint(col) >= uint(m.cols) {
+		panic(&IndexError{Index: col, Cap: m.cols})
+	}
+}
@@ -0,0 +1,181 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBitMatrix(t *testing.T) {
+	newMatrix := func() quickfilter.BitMatrix {
+		m := quickfilter.NewBitMatrix(4, 70)
+		m = m.Add(0, 1).Add(0, 65)
+		m = m.Add(1, 1).Add(1, 2)
+		m = m.Add(2, 65)
+		m = m.Add(3, 1).Add(3, 2).Add(3, 65)
+		return m
+	}
+
+	t.Run("Add, Delete and Has", func(t *testing.T) {
+		m := quickfilter.NewBitMatrix(3, 100)
+
+		m = m.Add(1, 99).Add(2, 0).Delete(2, 0)
+
+		if !m.Has(1, 99) {
+			t.Error("expected Has(1, 99) to return true")
+		}
+		if m.Has(2, 0) {
+			t.Error("expected Has(2, 0) to return false")
+		}
+		if m.Has(0, 99) {
+			t.Error("expected Has(0, 99) to return false")
+		}
+	})
+
+	t.Run("Row", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{1, 2, 65}
+
+		received := collect(m.Row(3))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Row view writes through", func(t *testing.T) {
+		m := newMatrix()
+
+		row := m.Row(2)
+		row = row.Add(3)
+
+		if !m.Has(2, 3) {
+			t.Error("expected Has(2, 3) to return true")
+		}
+		if row.Len() != 2 {
+			t.Errorf("expected %d, got %d", 2, row.Len())
+		}
+	})
+
+	t.Run("SetRow", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{0, 69}
+
+		m = m.SetRow(1, quickfilter.New(m.Cols()).Add(0).Add(69))
+		received := collect(m.Row(1))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Column", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{0, 2, 3}
+
+		received := collect(m.Column(quickfilter.New(0), 65))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("UnionOfRows", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{1, 2, 65}
+
+		received := collect(m.UnionOfRows(quickfilter.New(0), quickfilter.New(m.Rows()).Add(1).Add(2)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("IntersectionOfRows", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{1, 65}
+
+		received := collect(m.IntersectionOfRows(quickfilter.New(0), quickfilter.New(m.Rows()).Add(0).Add(3)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("UnionOfColumns", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{1, 3}
+
+		received := collect(m.UnionOfColumns(quickfilter.New(0), quickfilter.New(m.Cols()).Add(2)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("IntersectionOfColumns", func(t *testing.T) {
+		m := newMatrix()
+		expected := []int{0, 3}
+
+		received := collect(m.IntersectionOfColumns(quickfilter.New(0), quickfilter.New(m.Cols()).Add(1).Add(65)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("out of range should panic with an IndexError", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fn   func(m quickfilter.BitMatrix)
+		}{
+			{"Add column spilling into the next row", func(m quickfilter.BitMatrix) { m.Add(0, 128) }},
+			{"Add negative column", func(m quickfilter.BitMatrix) { m.Add(0, -1) }},
+			{"Add row", func(m quickfilter.BitMatrix) { m.Add(2, 0) }},
+			{"Delete", func(m quickfilter.BitMatrix) { m.Delete(0, 70) }},
+			{"Has", func(m quickfilter.BitMatrix) { m.Has(-1, 0) }},
+			{"Row", func(m quickfilter.BitMatrix) { m.Row(2) }},
+			{"SetRow", func(m quickfilter.BitMatrix) { m.SetRow(-1, quickfilter.New(70)) }},
+			{"Column", func(m quickfilter.BitMatrix) { m.Column(quickfilter.New(0), 70) }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				defer func() {
+					if _, ok := recover().(*quickfilter.IndexError); !ok {
+						t.Error("expected a panic with an IndexError")
+					}
+				}()
+
+				tt.fn(quickfilter.NewBitMatrix(2, 70))
+			})
+		}
+	})
+}
+
+func ExampleBitMatrix() {
+	users := []string{"alice", "bob", "carol"}
+	features := []string{"search", "export", "share"}
+	m := quickfilter.NewBitMatrix(len(users), len(features))
+	m = m.Add(0, 0).Add(0, 1)
+	m = m.Add(1, 1)
+	m = m.Add(2, 1).Add(2, 2)
+	used := m.UnionOfRows(quickfilter.New(0), quickfilter.NewFilled(len(users)))
+	usedByAll := m.IntersectionOfRows(quickfilter.New(0), quickfilter.NewFilled(len(users)))
+	for it := used.Iterate(); !it.Done(); it = it.Next() {
+		fmt.Println(features[it.Value()], usedByAll.Has(it.Value()))
+	}
+	// Output:
+	// search false
+	// export true
+	// share false
+}
+
+func collect(qf quickfilter.QuickFilter) []int {
+	result := make([]int, 0, qf.Len())
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		result = append(result, it.Value())
+	}
+	return result
+}
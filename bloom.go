@@ -0,0 +1,124 @@
+package quickfilter
+
+import (
+	"math"
+)
+
+// BloomFilter is a probabilistic set of uint64 keys, for key spaces too large
+// for an exact QuickFilter, such as IDs in a space of billions. It can answer
+// whether a key might be in the set or definitely is not, which makes it
+// useful for pre-filtering before a more expensive exact probe.
+//
+// The bits are stored in a QuickFilter. Unlike a QuickFilter, the keys of a
+// BloomFilter can't be iterated over or converted back into indices.
+type BloomFilter struct {
+	qf     QuickFilter
+	hashes int
+}
+
+// NewBloomFilter returns a new empty BloomFilter of the given number of bits
+// using the given number of hash functions per key.
+func NewBloomFilter(bits, hashes int) BloomFilter {
+	if bits < 1 || hashes < 1 {
+		panic("BloomFilter must have at least one bit and one hash function")
+	}
+	return BloomFilter{qf: New(bits), hashes: hashes}
+}
+
+// NewBloomFilterFor returns a new empty BloomFilter sized to hold
+// expectedKeys keys with the given false positive rate.
+func NewBloomFilterFor(expectedKeys int, falsePositiveRate float64) BloomFilter {
+	n := math.Max(float64(expectedKeys), 1)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(math.Round(m/n*math.Ln2), 1)
+	return NewBloomFilter(int(m), int(k))
+}
+
+// Cap returns the number of bits in the BloomFilter.
+func (bf BloomFilter) Cap() int {
+	return bf.qf.Cap()
+}
+
+// Hashes returns the number of hash functions used per key.
+func (bf BloomFilter) Hashes() int {
+	return bf.hashes
+}
+
+// Add a key to the BloomFilter.
+//
+// The original BloomFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the BloomFilter from escaping to the
+// heap.
+func (bf BloomFilter) Add(key uint64) BloomFilter {
+	h1, h2 := bloomHashes(key)
+	m := uint64(bf.qf.sourceLen)
+	for i := 0; i < bf.hashes; i++ {
+		index := int((h1 + uint64(i)*h2) % m)
+		if !bf.qf.Has(index) {
+			bf.qf = bf.qf.Add(index)
+		}
+	}
+	return bf
+}
+
+// MightContain returns false if the key is definitely not in the
+// BloomFilter, and true if it might be.
+func (bf BloomFilter) MightContain(key uint64) bool {
+	h1, h2 := bloomHashes(key)
+	m := uint64(bf.qf.sourceLen)
+	for i := 0; i < bf.hashes; i++ {
+		if !bf.qf.Has(int((h1 + uint64(i)*h2) % m)) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnionOf fills the BloomFilter with the keys in one or both of the provided
+// BloomFilters.
+//
+// The receiver and passed BloomFilters must all be the same size and use the
+// same number of hash functions or this will panic.
+//
+// The original BloomFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the BloomFilter from escaping to the
+// heap.
+func (bf BloomFilter) UnionOf(bf1, bf2 BloomFilter) BloomFilter {
+	if bf.hashes != bf1.hashes || bf.hashes != bf2.hashes {
+		panic("receiver and passed BloomFilters must use the same number of hash functions")
+	}
+	bf.qf = bf.qf.UnionOf(bf1.qf, bf2.qf)
+	return bf
+}
+
+// Clear the keys in the BloomFilter.
+//
+// The original BloomFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the BloomFilter from escaping to the
+// heap.
+func (bf BloomFilter) Clear() BloomFilter {
+	bf.qf = bf.qf.Clear()
+	return bf
+}
+
+// FalsePositiveRate returns the estimated probability of MightContain
+// returning true for a key that was not added, given the current fill ratio.
+func (bf BloomFilter) FalsePositiveRate() float64 {
+	fill := float64(bf.qf.Len()) / float64(bf.qf.Cap())
+	return math.Pow(fill, float64(bf.hashes))
+}
+
+// bloomHashes derives the two hashes used for double hashing of the key
+// using the SplitMix64 finalizer.
+func bloomHashes(key uint64) (h1, h2 uint64) {
+	h1 = splitMix64(key)
+	h2 = splitMix64(h1) | 1
+	return h1, h2
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
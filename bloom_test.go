@@ -0,0 +1,52 @@
+package quickfilter_test
+
+import (
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBloomFilter(t *testing.T) {
+	t.Run("added keys should be found", func(t *testing.T) {
+		bf := quickfilter.NewBloomFilterFor(1000, 0.01)
+
+		for key := uint64(0); key < 1000; key++ {
+			bf = bf.Add(key * 7919)
+		}
+
+		for key := uint64(0); key < 1000; key++ {
+			if !bf.MightContain(key * 7919) {
+				t.Fatalf("expected MightContain(%d) to return true", key*7919)
+			}
+		}
+	})
+
+	t.Run("false positive rate", func(t *testing.T) {
+		bf := quickfilter.NewBloomFilterFor(1000, 0.01)
+		for key := uint64(0); key < 1000; key++ {
+			bf = bf.Add(key)
+		}
+
+		falsePositives := 0
+		for key := uint64(1000); key < 101000; key++ {
+			if bf.MightContain(key) {
+				falsePositives++
+			}
+		}
+
+		if falsePositives > 2000 {
+			t.Errorf("expected at most %d false positives, got %d", 2000, falsePositives)
+		}
+	})
+
+	t.Run("UnionOf", func(t *testing.T) {
+		bf1 := quickfilter.NewBloomFilter(1024, 3).Add(1)
+		bf2 := quickfilter.NewBloomFilter(1024, 3).Add(2)
+
+		bf := quickfilter.NewBloomFilter(1024, 3).UnionOf(bf1, bf2)
+
+		if !bf.MightContain(1) || !bf.MightContain(2) {
+			t.Error("expected MightContain to return true")
+		}
+	})
+}
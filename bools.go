@@ -0,0 +1,97 @@
+package quickfilter
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unsafe"
+)
+
+// AndBools keeps only the values that are also true in mask, for interop
+// with code that produces plain bool masks, such as vectorized comparisons.
+//
+// mask must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AndBools(mask []bool) QuickFilter {
+	qf.mustBoolMask(mask)
+	qf.len = 0
+	for i := range qf.bits {
+		qf.bits[i] &= packBools(mask, i)
+		qf.len += bits.OnesCount(qf.bits[i])
+	}
+	return qf
+}
+
+// OrBools adds the values that are true in mask, like AndBools.
+//
+// mask must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) OrBools(mask []bool) QuickFilter {
+	qf.mustBoolMask(mask)
+	for i := range qf.bits {
+		word := packBools(mask, i)
+		qf.len += bits.OnesCount(word &^ qf.bits[i])
+		qf.bits[i] |= word
+	}
+	return qf
+}
+
+// AndNotBools deletes the values that are true in mask, like AndBools.
+//
+// mask must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AndNotBools(mask []bool) QuickFilter {
+	qf.mustBoolMask(mask)
+	for i := range qf.bits {
+		word := packBools(mask, i)
+		qf.len -= bits.OnesCount(word & qf.bits[i])
+		qf.bits[i] &^= word
+	}
+	return qf
+}
+
+func (qf QuickFilter) mustBoolMask(mask []bool) {
+	if len(mask) != qf.sourceLen {
+		panic("mask must be the same size as the QuickFilter")
+	}
+}
+
+// packBools returns the bools of mask for the word at index as a word,
+// packing eight bools at a time. Bools past the end of mask are false.
+func packBools(mask []bool, index int) uint {
+	start := index * bits.UintSize
+	if start >= len(mask) {
+		return 0
+	}
+	chunk := mask[start:]
+	if len(chunk) > bits.UintSize {
+		chunk = chunk[:bits.UintSize]
+	}
+	// Go stores bools as single bytes of 0 or 1, so the mask can be read
+	// as bytes eight at a time.
+	b := (*[bits.UintSize]byte)(unsafe.Pointer(&chunk[0]))[:len(chunk):len(chunk)]
+	var word uint
+	shift := uint(0)
+	for ; len(b) >= 8; b, shift = b[8:], shift+8 {
+		word |= uint(pack8(binary.LittleEndian.Uint64(b))) << shift
+	}
+	for i, v := range b {
+		word |= uint(v) << (shift + uint(i))
+	}
+	return word
+}
+
+// pack8 packs eight bytes of 0 or 1 into the bits of a byte, with the first
+// byte as the lowest bit, using a multiplication that gathers the low bit of
+// each byte into the top byte.
+func pack8(x uint64) byte {
+	return byte((x * 0x0102040810204080) >> 56)
+}
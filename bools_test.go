@@ -0,0 +1,57 @@
+package quickfilter_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBools(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	mask := make([]bool, 203)
+	for i := range mask {
+		mask[i] = rng.Intn(2) == 0
+	}
+	qf := quickfilter.NewRandom(len(mask), 0.5, rng)
+	tests := []struct {
+		name  string
+		apply func(qf quickfilter.QuickFilter) quickfilter.QuickFilter
+		keep  func(has, masked bool) bool
+	}{
+		{"AndBools", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.AndBools(mask) }, func(has, masked bool) bool { return has && masked }},
+		{"OrBools", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.OrBools(mask) }, func(has, masked bool) bool { return has || masked }},
+		{"AndNotBools", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.AndNotBools(mask) }, func(has, masked bool) bool { return has && !masked }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := []int{}
+			for i := range mask {
+				if tt.keep(qf.Has(i), mask[i]) {
+					expected = append(expected, i)
+				}
+			}
+
+			result := tt.apply(qf.Copy())
+			received := collect(result)
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+			if err := result.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.New(3).AndBools([]bool{true})
+	})
+}
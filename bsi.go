@@ -0,0 +1,137 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// BitSlicedIndex is an index over an unsigned integer field of a source
+// slice, stored as one QuickFilter per bit of the values: plane b holds the
+// elements whose value has bit b set. Range predicates are answered by
+// combining the planes a word at a time, at a cost proportional to the
+// number of bits in the values rather than to the number of distinct values.
+//
+// Like BitmapIndex, BitSlicedIndex is built once from the source slice and
+// needs to be rebuilt when the slice changes.
+type BitSlicedIndex struct {
+	sourceLen int
+	planes    []QuickFilter
+}
+
+// NewBitSlicedIndex returns a new BitSlicedIndex for a source slice of length
+// sourceLen, using value to extract the value of the element at index i, for
+// example:
+//
+//	bsi := quickfilter.NewBitSlicedIndex(len(orders), func(i int) uint64 { return orders[i].Cents })
+func NewBitSlicedIndex(sourceLen int, value func(i int) uint64) BitSlicedIndex {
+	values := make([]uint64, sourceLen)
+	var all uint64
+	for i := range values {
+		values[i] = value(i)
+		all |= values[i]
+	}
+	planes := make([]QuickFilter, bits.Len64(all))
+	for b := range planes {
+		planes[b] = New(sourceLen)
+	}
+	for i, v := range values {
+		for ; v != 0; v &= v - 1 {
+			b := bits.TrailingZeros64(v)
+			planes[b] = planes[b].Add(i)
+		}
+	}
+	return BitSlicedIndex{sourceLen: sourceLen, planes: planes}
+}
+
+// Cap returns the length of the indexed source slice.
+func (bsi BitSlicedIndex) Cap() int {
+	return bsi.sourceLen
+}
+
+// BitDepth returns the number of bit planes, which is the number of bits
+// needed to represent the largest indexed value.
+func (bsi BitSlicedIndex) BitDepth() int {
+	return len(bsi.planes)
+}
+
+// Value returns the value of the element at index.
+func (bsi BitSlicedIndex) Value(index int) uint64 {
+	var v uint64
+	for b, plane := range bsi.planes {
+		if plane.Has(index) {
+			v |= 1 << uint(b)
+		}
+	}
+	return v
+}
+
+// GreaterThan fills dst with the elements whose value is greater than v. dst
+// is resized to the Cap() of the BitSlicedIndex.
+func (bsi BitSlicedIndex) GreaterThan(dst QuickFilter, v uint64) QuickFilter {
+	dst = dst.Resize(bsi.sourceLen)
+	for i := range dst.bits {
+		_, _, dst.bits[i] = bsi.compareWord(i, v)
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(bsi.sourceLen)
+	return dst.Recount()
+}
+
+// LessThan fills dst with the elements whose value is less than v. dst is
+// resized to the Cap() of the BitSlicedIndex.
+func (bsi BitSlicedIndex) LessThan(dst QuickFilter, v uint64) QuickFilter {
+	dst = dst.Resize(bsi.sourceLen)
+	for i := range dst.bits {
+		dst.bits[i], _, _ = bsi.compareWord(i, v)
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(bsi.sourceLen)
+	return dst.Recount()
+}
+
+// Between fills dst with the elements whose value is between lo and hi,
+// inclusive. dst is resized to the Cap() of the BitSlicedIndex.
+func (bsi BitSlicedIndex) Between(dst QuickFilter, lo, hi uint64) QuickFilter {
+	dst = dst.Resize(bsi.sourceLen)
+	for i := range dst.bits {
+		loLT, _, _ := bsi.compareWord(i, lo)
+		_, _, hiGT := bsi.compareWord(i, hi)
+		dst.bits[i] = ^(loLT | hiGT)
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(bsi.sourceLen)
+	return dst.Recount()
+}
+
+// Sum returns the sum of the values of the elements set in selection. The sum
+// wraps around on overflow.
+//
+// The passed QuickFilter must have a Cap() equal to the Cap() of the
+// BitSlicedIndex or this will panic.
+func (bsi BitSlicedIndex) Sum(selection QuickFilter) uint64 {
+	if selection.sourceLen != bsi.sourceLen {
+		panic("QuickFilter must be the same size as the BitSlicedIndex")
+	}
+	var sum uint64
+	for b, plane := range bsi.planes {
+		sum += uint64(plane.IntersectionCount(selection)) << uint(b)
+	}
+	return sum
+}
+
+// compareWord returns the bits of the word at index i of the elements whose
+// value is less than, equal to and greater than v, walking the planes from
+// the most significant bit down.
+func (bsi BitSlicedIndex) compareWord(i int, v uint64) (lt, eq, gt uint) {
+	if v>>uint(len(bsi.planes)) != 0 {
+		return ^uint(0), 0, 0
+	}
+	eq = ^uint(0)
+	for b := len(bsi.planes) - 1; b >= 0 && eq != 0; b-- {
+		word := bsi.planes[b].bits[i]
+		if v&(1<<uint(b)) == 0 {
+			gt |= eq & word
+			eq &^= word
+		} else {
+			lt |= eq &^ word
+			eq &= word
+		}
+	}
+	return lt, eq, gt
+}
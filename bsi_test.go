@@ -0,0 +1,102 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBitSlicedIndex(t *testing.T) {
+	values := make([]uint64, 150)
+	for i := range values {
+		values[i] = uint64(i*37) % 101
+	}
+	newBitSlicedIndex := func() quickfilter.BitSlicedIndex {
+		return quickfilter.NewBitSlicedIndex(len(values), func(i int) uint64 { return values[i] })
+	}
+	where := func(pred func(v uint64) bool) []int {
+		result := []int{}
+		for i, v := range values {
+			if pred(v) {
+				result = append(result, i)
+			}
+		}
+		return result
+	}
+
+	t.Run("BitDepth", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		expected := 7
+
+		received := bsi.BitDepth()
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		expected := values[123]
+
+		received := bsi.Value(123)
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("GreaterThan", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		for _, v := range []uint64{0, 1, 50, 99, 100, 1000} {
+			expected := where(func(x uint64) bool { return x > v })
+
+			received := collect(bsi.GreaterThan(quickfilter.New(0), v))
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+
+	t.Run("LessThan", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		for _, v := range []uint64{0, 1, 50, 99, 100, 1000} {
+			expected := where(func(x uint64) bool { return x < v })
+
+			received := collect(bsi.LessThan(quickfilter.New(0), v))
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+
+	t.Run("Between", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		expected := where(func(x uint64) bool { return x >= 20 && x <= 40 })
+
+		received := collect(bsi.Between(quickfilter.New(0), 20, 40))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Sum", func(t *testing.T) {
+		bsi := newBitSlicedIndex()
+		selection := quickfilter.New(len(values))
+		expected := uint64(0)
+		for i := 0; i < len(values); i += 3 {
+			selection = selection.Add(i)
+			expected += values[i]
+		}
+
+		received := bsi.Sum(selection)
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package quickfilter
+
+// Builder builds a QuickFilter with chainable pointer-receiver methods, for
+// setup and configuration code where reassigning the returned value after
+// every call is noisy:
+//
+//	qf := quickfilter.NewBuilder(len(items)).
+//		AddRange(0, 10).
+//		AddWhere(func(i int) bool { return items[i].Active }).
+//		Add(42).
+//		Build()
+type Builder struct {
+	qf QuickFilter
+}
+
+// NewBuilder returns a new Builder of a QuickFilter for a source slice of
+// length sourceLen.
+func NewBuilder(sourceLen int) *Builder {
+	return &Builder{qf: New(sourceLen)}
+}
+
+// Add the indices. Adding an index that is already set has no effect.
+func (b *Builder) Add(indices ...int) *Builder {
+	for _, index := range indices {
+		if !b.qf.Has(index) {
+			b.qf = b.qf.Add(index)
+		}
+	}
+	return b
+}
+
+// AddRange adds the indices from lo (inclusive) to hi (exclusive).
+func (b *Builder) AddRange(lo, hi int) *Builder {
+	if lo < hi {
+		b.qf.mustIndex(lo)
+		b.qf.mustIndex(hi - 1)
+	}
+	b.qf = b.qf.addRange(lo, hi)
+	return b
+}
+
+// AddWhere adds the indices for which pred returns true.
+func (b *Builder) AddWhere(pred func(i int) bool) *Builder {
+	for i := 0; i < b.qf.sourceLen; i++ {
+		if pred(i) && !b.qf.Has(i) {
+			b.qf = b.qf.Add(i)
+		}
+	}
+	return b
+}
+
+// Fill adds all the indices.
+func (b *Builder) Fill() *Builder {
+	b.qf = b.qf.Fill()
+	return b
+}
+
+// Build returns the built QuickFilter. The Builder must not be used after
+// calling Build.
+func (b *Builder) Build() QuickFilter {
+	qf := b.qf
+	b.qf = QuickFilter{}
+	return qf
+}
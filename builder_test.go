@@ -0,0 +1,46 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("Add, AddRange and AddWhere", func(t *testing.T) {
+		expected := []int{0, 1, 2, 3, 9, 30, 60, 65, 66, 67, 68, 69, 70, 90, 99}
+
+		qf := quickfilter.NewBuilder(100).
+			AddRange(0, 4).
+			AddRange(65, 71).
+			AddWhere(func(i int) bool { return i%30 == 0 }).
+			Add(9, 99, 3).
+			Build()
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Fill", func(t *testing.T) {
+		expectedLen := 100
+
+		receivedLen := quickfilter.NewBuilder(100).Add(3).Fill().Build().Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("AddRange out of range should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.NewBuilder(100).AddRange(90, 101)
+	})
+}
@@ -0,0 +1,72 @@
+package quickfilter
+
+import (
+	"time"
+)
+
+// Cascade is a multi-level filter made of a chain of predicate stages. Each
+// stage is only evaluated for the elements that passed all of the previous
+// stages, so cheap and selective predicates should come first. Run records
+// the selectivity and duration of every stage to help with finding the best
+// order.
+type Cascade struct {
+	stages []cascadeStage
+}
+
+type cascadeStage struct {
+	name      string
+	predicate func(i int) bool
+}
+
+// StageStats contains the statistics of a single Cascade stage run.
+type StageStats struct {
+	// Name of the stage.
+	Name string
+	// In is the number of elements the stage was evaluated for.
+	In int
+	// Out is the number of elements that passed the stage.
+	Out int
+	// Duration is the time spent in the stage.
+	Duration time.Duration
+}
+
+// Selectivity returns the fraction of the elements that passed the stage.
+func (s StageStats) Selectivity() float64 {
+	if s.In == 0 {
+		return 0
+	}
+	return float64(s.Out) / float64(s.In)
+}
+
+// Stage returns a new Cascade with a stage appended that keeps the elements
+// whose index satisfies predicate.
+func (c Cascade) Stage(name string, predicate func(i int) bool) Cascade {
+	stages := make([]cascadeStage, len(c.stages), len(c.stages)+1)
+	copy(stages, c.stages)
+	c.stages = append(stages, cascadeStage{name: name, predicate: predicate})
+	return c
+}
+
+// Run the stages of the Cascade for the elements set in qf, deleting the
+// elements that fail a stage. The statistics of each stage are appended to
+// stats, which may be nil.
+//
+// To run the Cascade over a whole slice, pass NewFilled(len(slice)).
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one.
+func (c Cascade) Run(qf QuickFilter, stats []StageStats) (QuickFilter, []StageStats) {
+	for _, stage := range c.stages {
+		s := StageStats{Name: stage.name, In: qf.Len()}
+		start := time.Now()
+		for it := qf.Iterate(); !it.Done(); it = it.Next() {
+			if !stage.predicate(it.Value()) {
+				qf = qf.Delete(it.Value())
+			}
+		}
+		s.Duration = time.Since(start)
+		s.Out = qf.Len()
+		stats = append(stats, s)
+	}
+	return qf, stats
+}
@@ -0,0 +1,64 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestCascade(t *testing.T) {
+	t.Run("Run", func(t *testing.T) {
+		evaluated := 0
+		c := quickfilter.Cascade{}.
+			Stage("even", func(i int) bool { return i%2 == 0 }).
+			Stage("multiple of 3", func(i int) bool {
+				evaluated++
+				return i%3 == 0
+			})
+		expected := []int{0, 6, 12, 18}
+
+		qf, stats := c.Run(quickfilter.NewFilled(20), nil)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if evaluated != 10 {
+			t.Errorf("expected %d, got %d", 10, evaluated)
+		}
+		if len(stats) != 2 {
+			t.Fatalf("expected %d, got %d", 2, len(stats))
+		}
+		if stats[0].In != 20 || stats[0].Out != 10 || stats[1].In != 10 || stats[1].Out != 4 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("Stage should not modify the receiver", func(t *testing.T) {
+		base := quickfilter.Cascade{}.Stage("even", func(i int) bool { return i%2 == 0 })
+		expectedStats := 1
+
+		base.Stage("odd", func(i int) bool { return i%2 != 0 })
+		_, stats := base.Run(quickfilter.NewFilled(10), nil)
+		receivedStats := len(stats)
+
+		if expectedStats != receivedStats {
+			t.Errorf("expected %d, got %d", expectedStats, receivedStats)
+		}
+	})
+}
+
+func ExampleCascade() {
+	c := quickfilter.Cascade{}.
+		Stage("even", func(i int) bool { return i%2 == 0 }).
+		Stage("multiple of 3", func(i int) bool { return i%3 == 0 })
+	_, stats := c.Run(quickfilter.NewFilled(60), nil)
+	for _, s := range stats {
+		fmt.Printf("%s: %d -> %d (%.2f)\n", s.Name, s.In, s.Out, s.Selectivity())
+	}
+	// Output:
+	// even: 60 -> 30 (0.50)
+	// multiple of 3: 30 -> 10 (0.33)
+}
@@ -0,0 +1,185 @@
+package quickfilter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	changelogSet byte = iota + 1
+	changelogClear
+	changelogSetRange
+	changelogClearRange
+	changelogResize
+)
+
+// ChangelogWriter encodes mutations of a QuickFilter as a compact
+// append-only stream, so that a QuickFilter maintained on one node can be
+// mirrored to followers with a ChangelogReader without shipping full
+// snapshots.
+//
+// Each entry is an operation byte followed by its arguments as unsigned
+// varints.
+type ChangelogWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewChangelogWriter returns a new ChangelogWriter writing to w. Each entry
+// is written to w with a single Write call.
+func NewChangelogWriter(w io.Writer) *ChangelogWriter {
+	return &ChangelogWriter{w: w, buf: make([]byte, 0, 1+2*binary.MaxVarintLen64)}
+}
+
+// Set records that the index was added.
+func (cw *ChangelogWriter) Set(index int) error {
+	return cw.write(changelogSet, index)
+}
+
+// Clear records that the index was deleted.
+func (cw *ChangelogWriter) Clear(index int) error {
+	return cw.write(changelogClear, index)
+}
+
+// SetRange records that the indices from lo (inclusive) to hi (exclusive)
+// were added.
+func (cw *ChangelogWriter) SetRange(lo, hi int) error {
+	return cw.write(changelogSetRange, lo, hi)
+}
+
+// ClearRange records that the indices from lo (inclusive) to hi (exclusive)
+// were deleted.
+func (cw *ChangelogWriter) ClearRange(lo, hi int) error {
+	return cw.write(changelogClearRange, lo, hi)
+}
+
+// Resize records that the QuickFilter was resized to a new source length.
+// When replayed, values beyond the new source length are cleared, and
+// growing exposes no stale values.
+func (cw *ChangelogWriter) Resize(sourceLen int) error {
+	return cw.write(changelogResize, sourceLen)
+}
+
+func (cw *ChangelogWriter) write(op byte, args ...int) error {
+	cw.buf = append(cw.buf[:0], op)
+	for _, arg := range args {
+		cw.buf = appendUvarint(cw.buf, uint64(arg))
+	}
+	_, err := cw.w.Write(cw.buf)
+	return err
+}
+
+// ChangelogReader replays a stream of mutations written by a
+// ChangelogWriter.
+type ChangelogReader struct {
+	r io.ByteReader
+}
+
+// NewChangelogReader returns a new ChangelogReader reading from r. If r is
+// not an io.ByteReader, it is buffered.
+func NewChangelogReader(r io.Reader) *ChangelogReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &ChangelogReader{r: br}
+}
+
+// Apply reads the next entry of the stream and applies it to qf. At the end
+// of the stream, io.EOF is returned. An entry cut short by the end of the
+// stream results in io.ErrUnexpectedEOF, and an invalid entry in
+// ErrInvalidEncoding.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one.
+func (cr *ChangelogReader) Apply(qf QuickFilter) (QuickFilter, error) {
+	op, err := cr.r.ReadByte()
+	if err != nil {
+		return qf, err
+	}
+	switch op {
+	case changelogSet, changelogClear, changelogResize:
+		arg, err := cr.readArg()
+		if err != nil {
+			return qf, err
+		}
+		return applyChangelog(qf, op, arg, 0)
+	case changelogSetRange, changelogClearRange:
+		lo, err := cr.readArg()
+		if err != nil {
+			return qf, err
+		}
+		hi, err := cr.readArg()
+		if err != nil {
+			return qf, err
+		}
+		return applyChangelog(qf, op, lo, hi)
+	default:
+		return qf, ErrInvalidEncoding
+	}
+}
+
+// ApplyAll applies all the remaining entries of the stream to qf.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one.
+func (cr *ChangelogReader) ApplyAll(qf QuickFilter) (QuickFilter, error) {
+	for {
+		var err error
+		if qf, err = cr.Apply(qf); err != nil {
+			if err == io.EOF {
+				return qf, nil
+			}
+			return qf, err
+		}
+	}
+}
+
+func (cr *ChangelogReader) readArg() (int, error) {
+	v, err := binary.ReadUvarint(cr.r)
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	if v > uint64(maxInt) {
+		return 0, ErrInvalidEncoding
+	}
+	return int(v), nil
+}
+
+func applyChangelog(qf QuickFilter, op byte, a, b int) (QuickFilter, error) {
+	switch op {
+	case changelogSet:
+		if a >= qf.sourceLen {
+			return qf, ErrInvalidEncoding
+		}
+		if !qf.Has(a) {
+			qf = qf.Add(a)
+		}
+	case changelogClear:
+		if a >= qf.sourceLen {
+			return qf, ErrInvalidEncoding
+		}
+		qf = qf.Delete(a)
+	case changelogSetRange, changelogClearRange:
+		if a > b || b > qf.sourceLen {
+			return qf, ErrInvalidEncoding
+		}
+		if op == changelogSetRange {
+			qf = qf.addRange(a, b)
+		} else {
+			qf = qf.deleteRange(a, b)
+		}
+	case changelogResize:
+		if a >= qf.sourceLen {
+			return qf.Grow(a), nil
+		}
+		qf = qf.Resize(a)
+		qf.bits[len(qf.bits)-1] &= lastWordMask(a)
+		qf = qf.Recount()
+	}
+	return qf, nil
+}
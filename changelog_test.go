@@ -0,0 +1,92 @@
+package quickfilter_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestChangelog(t *testing.T) {
+	t.Run("replay", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := quickfilter.NewChangelogWriter(&buf)
+		leader := quickfilter.New(100)
+		_ = cw.Set(3)
+		leader = leader.Add(3)
+		_ = cw.SetRange(10, 80)
+		for i := 10; i < 80; i++ {
+			if !leader.Has(i) {
+				leader = leader.Add(i)
+			}
+		}
+		_ = cw.Clear(11)
+		leader = leader.Delete(11)
+		_ = cw.ClearRange(20, 70)
+		for i := 20; i < 70; i++ {
+			leader = leader.Delete(i)
+		}
+		expected := collect(leader)
+
+		follower, err := quickfilter.NewChangelogReader(&buf).ApplyAll(quickfilter.New(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := collect(follower)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if leader.Len() != follower.Len() {
+			t.Errorf("expected %d, got %d", leader.Len(), follower.Len())
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := quickfilter.NewChangelogWriter(&buf)
+		expected := []int{1}
+		expectedCap := 200
+
+		_ = cw.SetRange(0, 100)
+		_ = cw.Resize(2)
+		_ = cw.Clear(0)
+		_ = cw.Resize(expectedCap)
+		follower, err := quickfilter.NewChangelogReader(&buf).ApplyAll(quickfilter.New(100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := collect(follower)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if expectedCap != follower.Cap() {
+			t.Errorf("expected %d, got %d", expectedCap, follower.Cap())
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = quickfilter.NewChangelogWriter(&buf).SetRange(0, 1000)
+
+		_, err := quickfilter.NewChangelogReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1])).ApplyAll(quickfilter.New(1000))
+
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = quickfilter.NewChangelogWriter(&buf).Set(100)
+
+		_, err := quickfilter.NewChangelogReader(&buf).ApplyAll(quickfilter.New(100))
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
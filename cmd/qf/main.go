@@ -0,0 +1,206 @@
+// Command qf inspects and converts serialized QuickFilters.
+//
+// Usage:
+//
+//	qf stats [-format FORMAT] [-cap N] FILE...
+//	qf convert [-from FORMAT] [-to FORMAT] [-cap N] IN OUT
+//	qf union [-format FORMAT] [-cap N] [-out FILE] FILE...
+//	qf intersect [-format FORMAT] [-cap N] [-out FILE] FILE...
+//
+// The formats are:
+//
+//	binary     QuickFilter.MarshalBinary
+//	json       QuickFilter.MarshalJSON
+//	rice       QuickFilter.AppendRice
+//	eliasfano  QuickFilter.AppendEliasFano
+//	tagged     QuickFilter.AppendFormat in any Format; read only
+//	roaring    the portable Roaring bitmap serialization
+//
+// A roaring bitmap doesn't store the source length, so it is taken from -cap,
+// or from the largest value plus one if -cap is not given. A FILE of "-"
+// refers to the standard input or output.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "qf:", err)
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("usage: qf stats|convert|union|intersect [flags] FILE...")
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	files := fileSystem{stdin: stdin, stdout: stdout}
+	switch args[0] {
+	case "stats":
+		return stats(args[1:], files)
+	case "convert":
+		return convert(args[1:], files)
+	case "union":
+		return combine(args[1:], files, func(dst, qf1, qf2 quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return dst.UnionOf(qf1, qf2)
+		})
+	case "intersect":
+		return combine(args[1:], files, func(dst, qf1, qf2 quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return dst.IntersectionOf(qf1, qf2)
+		})
+	default:
+		return errUsage
+	}
+}
+
+func stats(args []string, files fileSystem) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	format := fs.String("format", "binary", "input format")
+	fs.IntVar(&files.cap, "cap", 0, "source length of roaring input")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	for _, name := range fs.Args() {
+		qf, err := files.read(name, *format)
+		if err != nil {
+			return err
+		}
+		density := 0.0
+		if qf.Cap() > 0 {
+			density = float64(qf.Len()) / float64(qf.Cap())
+		}
+		runs := len(quickfilter.IntervalSetOf(qf).Intervals())
+		fmt.Fprintf(files.stdout, "%s: cap=%d len=%d density=%.4f runs=%d\n", name, qf.Cap(), qf.Len(), density, runs)
+	}
+	return nil
+}
+
+func convert(args []string, files fileSystem) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	from := fs.String("from", "binary", "input format")
+	to := fs.String("to", "json", "output format")
+	fs.IntVar(&files.cap, "cap", 0, "source length of roaring input")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	qf, err := files.read(fs.Arg(0), *from)
+	if err != nil {
+		return err
+	}
+	return files.write(fs.Arg(1), *to, qf)
+}
+
+func combine(args []string, files fileSystem, op func(dst, qf1, qf2 quickfilter.QuickFilter) quickfilter.QuickFilter) error {
+	fs := flag.NewFlagSet("combine", flag.ContinueOnError)
+	format := fs.String("format", "binary", "input and output format")
+	fs.IntVar(&files.cap, "cap", 0, "source length of roaring input")
+	out := fs.String("out", "-", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	result, err := files.read(fs.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	for _, name := range fs.Args()[1:] {
+		qf, err := files.read(name, *format)
+		if err != nil {
+			return err
+		}
+		if qf.Cap() != result.Cap() {
+			return fmt.Errorf("%s: cap %d does not match %d", name, qf.Cap(), result.Cap())
+		}
+		result = op(result, result, qf)
+	}
+	return files.write(*out, *format, result)
+}
+
+type fileSystem struct {
+	stdin  io.Reader
+	stdout io.Writer
+	cap    int
+}
+
+func (files fileSystem) read(name, format string) (quickfilter.QuickFilter, error) {
+	var qf quickfilter.QuickFilter
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = ioutil.ReadAll(files.stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return qf, err
+	}
+	switch format {
+	case "binary":
+		err = qf.UnmarshalBinary(data)
+	case "json":
+		err = qf.UnmarshalJSON(data)
+	case "rice":
+		err = qf.UnmarshalRice(data)
+	case "eliasfano":
+		err = qf.UnmarshalEliasFano(data)
+	case "tagged":
+		err = qf.UnmarshalFormat(data)
+	case "roaring":
+		qf, err = readRoaring(data, files.cap)
+	default:
+		return qf, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return qf, fmt.Errorf("%s: %v", name, err)
+	}
+	return qf, nil
+}
+
+func (files fileSystem) write(name, format string, qf quickfilter.QuickFilter) error {
+	var data []byte
+	var err error
+	switch format {
+	case "binary":
+		data, err = qf.MarshalBinary()
+	case "json":
+		data, err = qf.MarshalJSON()
+		data = append(data, '\n')
+	case "rice":
+		data = qf.AppendRice(nil)
+	case "eliasfano":
+		data = qf.AppendEliasFano(nil)
+	case "roaring":
+		data = appendRoaring(nil, qf)
+	case "tagged":
+		return errors.New("format \"tagged\" can only be read")
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if name == "-" {
+		_, err = files.stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(name, data, 0644)
+}
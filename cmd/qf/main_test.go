@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := writeFile("a.json", `{"cap":100,"indices":[1,2,3,50]}`)
+	b := writeFile("b.json", `{"cap":100,"indices":[3,50,99]}`)
+
+	t.Run("stats", func(t *testing.T) {
+		var stdout bytes.Buffer
+		expected := a + ": cap=100 len=4 density=0.0400 runs=2\n"
+
+		err := run([]string{"stats", "-format", "json", a}, nil, &stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("convert round trip", func(t *testing.T) {
+		var stdout bytes.Buffer
+		bin := filepath.Join(dir, "a.bin")
+		expected := `{"cap":100,"indices":[1,2,3,50]}` + "\n"
+
+		if err := run([]string{"convert", "-from", "json", "-to", "binary", a, bin}, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := run([]string{"convert", "-from", "binary", "-to", "json", bin, "-"}, nil, &stdout); err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("union", func(t *testing.T) {
+		var stdout bytes.Buffer
+		expected := `{"cap":100,"indices":[1,2,3,50,99]}` + "\n"
+
+		err := run([]string{"union", "-format", "json", a, b}, nil, &stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("intersect from stdin", func(t *testing.T) {
+		var stdout bytes.Buffer
+		expected := `{"cap":100,"indices":[3,50]}` + "\n"
+
+		err := run([]string{"intersect", "-format", "json", "-", b}, strings.NewReader(`{"cap":100,"indices":[1,2,3,50]}`), &stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	for _, format := range []string{"binary", "rice", "eliasfano", "roaring"} {
+		t.Run("convert round trip "+format, func(t *testing.T) {
+			var stdout bytes.Buffer
+			encoded := filepath.Join(dir, "a."+format)
+			expected := `{"cap":100,"indices":[1,2,3,50]}` + "\n"
+
+			if err := run([]string{"convert", "-from", "json", "-to", format, a, encoded}, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := run([]string{"convert", "-from", format, "-to", "json", "-cap", "100", encoded, "-"}, nil, &stdout); err != nil {
+				t.Fatal(err)
+			}
+			received := stdout.String()
+
+			if expected != received {
+				t.Errorf("expected %q, got %q", expected, received)
+			}
+		})
+	}
+
+	t.Run("tagged", func(t *testing.T) {
+		var stdout bytes.Buffer
+		qf := quickfilter.NewFromIndices(100, []int{1, 2, 3, 50})
+		tagged := writeFile("a.tagged", string(qf.AppendFormat(nil, quickfilter.FormatRice)))
+		expected := `{"cap":100,"indices":[1,2,3,50]}` + "\n"
+
+		if err := run([]string{"convert", "-from", "tagged", "-to", "json", tagged, "-"}, nil, &stdout); err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("roaring bitmap containers", func(t *testing.T) {
+		qf := quickfilter.NewRange(70000, 100, 5100).Add(69999)
+		data := appendRoaring(nil, qf)
+
+		received, err := readRoaring(data, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if qf.Compare(received) != 0 || received.Cap() != qf.Cap() {
+			t.Errorf("expected %#v, got %#v", qf, received)
+		}
+	})
+
+	t.Run("roaring run containers", func(t *testing.T) {
+		data := []byte{
+			0x3b, 0x30, 0x00, 0x00, // cookie with runs, one container
+			0x01,       // run container bitset
+			0x00, 0x00, // key
+			0x02, 0x00, // cardinality - 1
+			0x01, 0x00, // number of runs
+			0x05, 0x00, 0x02, 0x00, // run from 5 of length 3
+		}
+		expected := `{"cap":10,"indices":[5,6,7]}` + "\n"
+		var stdout bytes.Buffer
+
+		err := run([]string{"convert", "-from", "roaring", "-cap", "10", "-", "-"}, bytes.NewReader(data), &stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := stdout.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("truncated roaring", func(t *testing.T) {
+		data := appendRoaring(nil, quickfilter.NewFromIndices(100, []int{1, 2, 3}))
+
+		_, err := readRoaring(data[:len(data)-1], 0)
+
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := run([]string{"stats", a}, nil, ioutil.Discard)
+
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+// The roaring format is the portable serialization of Roaring bitmaps
+// specified at https://github.com/RoaringBitmap/RoaringFormatSpec, as read
+// and written by the Roaring libraries of most languages.
+const (
+	roaringSerialCookieNoRuns = 12346
+	roaringSerialCookie       = 12347
+	roaringNoOffsetThreshold  = 4
+	roaringMaxArrayLen        = 4096
+	roaringBitmapBytes        = 8192
+)
+
+var errRoaringTruncated = errors.New("truncated roaring bitmap")
+
+// appendRoaring appends the roaring encoding of the set values of qf to dst,
+// using array containers for sparse chunks and bitmap containers for dense
+// ones.
+func appendRoaring(dst []byte, qf quickfilter.QuickFilter) []byte {
+	var keys []uint16
+	var containers [][]uint16
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		index := it.Value()
+		key := uint16(index >> 16)
+		if len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+			containers = append(containers, nil)
+		}
+		last := len(containers) - 1
+		containers[last] = append(containers[last], uint16(index))
+	}
+	dst = appendUint32(dst, roaringSerialCookieNoRuns)
+	dst = appendUint32(dst, uint32(len(keys)))
+	for i, key := range keys {
+		dst = appendUint16(dst, key)
+		dst = appendUint16(dst, uint16(len(containers[i])-1))
+	}
+	offset := len(dst) + 4*len(keys)
+	for _, values := range containers {
+		dst = appendUint32(dst, uint32(offset))
+		if len(values) > roaringMaxArrayLen {
+			offset += roaringBitmapBytes
+		} else {
+			offset += 2 * len(values)
+		}
+	}
+	for _, values := range containers {
+		if len(values) > roaringMaxArrayLen {
+			var bitmap [roaringBitmapBytes / 8]uint64
+			for _, v := range values {
+				bitmap[v/64] |= 1 << (v % 64)
+			}
+			for _, word := range bitmap {
+				dst = appendUint64(dst, word)
+			}
+			continue
+		}
+		for _, v := range values {
+			dst = appendUint16(dst, v)
+		}
+	}
+	return dst
+}
+
+// readRoaring decodes a roaring bitmap into a QuickFilter of sourceLen
+// offsets, or of the largest value plus one if sourceLen is 0.
+func readRoaring(data []byte, sourceLen int) (quickfilter.QuickFilter, error) {
+	var qf quickfilter.QuickFilter
+	r := roaringReader{data: data}
+	cookie := r.readUint32()
+	var size int
+	var runs []byte
+	switch {
+	case cookie&0xFFFF == roaringSerialCookie:
+		size = int(cookie>>16) + 1
+		runs = r.read((size + 7) / 8)
+	case cookie == roaringSerialCookieNoRuns:
+		size = int(r.readUint32())
+	default:
+		if r.err != nil {
+			return qf, r.err
+		}
+		return qf, errors.New("not a roaring bitmap")
+	}
+	if size > 1<<16 {
+		return qf, errors.New("too many roaring containers")
+	}
+	header := r.read(4 * size)
+	if cookie == roaringSerialCookieNoRuns || size >= roaringNoOffsetThreshold {
+		r.read(4 * size)
+	}
+	var indices []int
+	for i := 0; i < size && r.err == nil; i++ {
+		high := int(binary.LittleEndian.Uint16(header[4*i:])) << 16
+		card := int(binary.LittleEndian.Uint16(header[4*i+2:])) + 1
+		switch {
+		case runs != nil && runs[i/8]&(1<<uint(i%8)) != 0:
+			n := int(r.readUint16())
+			for j := 0; j < n && r.err == nil; j++ {
+				start := high + int(r.readUint16())
+				end := start + int(r.readUint16())
+				for v := start; v <= end; v++ {
+					indices = append(indices, v)
+				}
+			}
+		case card > roaringMaxArrayLen:
+			for w := 0; w < roaringBitmapBytes/8 && r.err == nil; w++ {
+				word := r.readUint64()
+				for word != 0 {
+					indices = append(indices, high+w*64+bits.TrailingZeros64(word))
+					word &= word - 1
+				}
+			}
+		default:
+			for j := 0; j < card && r.err == nil; j++ {
+				indices = append(indices, high+int(r.readUint16()))
+			}
+		}
+	}
+	if r.err != nil {
+		return qf, r.err
+	}
+	if sourceLen == 0 && len(indices) > 0 {
+		sourceLen = indices[len(indices)-1] + 1
+	}
+	for _, index := range indices {
+		if index < 0 || index >= sourceLen {
+			return qf, fmt.Errorf("value %d out of range for cap %d", index, sourceLen)
+		}
+	}
+	return quickfilter.NewFromIndices(sourceLen, indices), nil
+}
+
+// roaringReader reads little-endian values from data, recording an error
+// instead of panicking if data is too short.
+type roaringReader struct {
+	data []byte
+	err  error
+}
+
+func (r *roaringReader) read(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = errRoaringTruncated
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *roaringReader) readUint16() uint16 {
+	return binary.LittleEndian.Uint16(r.read(2))
+}
+
+func (r *roaringReader) readUint32() uint32 {
+	return binary.LittleEndian.Uint32(r.read(4))
+}
+
+func (r *roaringReader) readUint64() uint64 {
+	return binary.LittleEndian.Uint64(r.read(8))
+}
+
+func appendUint16(dst []byte, v uint16) []byte {
+	return append(dst, byte(v), byte(v>>8))
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(dst []byte, v uint64) []byte {
+	return appendUint32(appendUint32(dst, uint32(v)), uint32(v>>32))
+}
@@ -0,0 +1,65 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"reflect"
+)
+
+// KeepMarked moves the elements of the slice whose index is set in qf to the
+// front of the slice, preserving their order, and returns the number of
+// them, so that the survivors can be resliced in place:
+//
+//	items = items[:quickfilter.KeepMarked(items, qf)]
+//
+// The removed elements are left after the survivors in an unspecified order.
+// Like sort.Slice, the slice is accessed through reflect.Swapper, as the
+// module predates type parameters.
+//
+// slice must be a slice with a length equal to Cap() or this will panic.
+func KeepMarked(slice interface{}, qf QuickFilter) int {
+	swap := mustSwapper(slice, qf)
+	n := 0
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		if i := it.Value(); i != n {
+			swap(n, i)
+		}
+		n++
+	}
+	return n
+}
+
+// RemoveMarked moves the elements of the slice whose index is not set in qf
+// to the front of the slice, preserving their order, and returns the number
+// of them, so that the elements marked for deletion can be removed in place:
+//
+//	items = items[:quickfilter.RemoveMarked(items, qf)]
+//
+// It is the same as KeepMarked with the complement of qf, without building
+// the complement.
+//
+// slice must be a slice with a length equal to Cap() or this will panic.
+func RemoveMarked(slice interface{}, qf QuickFilter) int {
+	swap := mustSwapper(slice, qf)
+	n := 0
+	last := len(qf.bits) - 1
+	for i, word := range qf.bits {
+		word = ^word
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		for ; word != 0; word &= word - 1 {
+			if index := i*bits.UintSize + bits.TrailingZeros(word); index != n {
+				swap(n, index)
+			}
+			n++
+		}
+	}
+	return n
+}
+
+func mustSwapper(slice interface{}, qf QuickFilter) func(i, j int) {
+	if reflect.ValueOf(slice).Len() != qf.sourceLen {
+		panic("slice must be the same size as the QuickFilter")
+	}
+	return reflect.Swapper(slice)
+}
@@ -0,0 +1,69 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestKeepMarked(t *testing.T) {
+	t.Run("should keep the marked elements in order", func(t *testing.T) {
+		items := []string{"a", "b", "c", "d", "e"}
+		qf := quickfilter.New(5).Add(1).Add(3).Add(4)
+		expected := []string{"b", "d", "e"}
+
+		received := items[:quickfilter.KeepMarked(items, qf)]
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.KeepMarked([]int{1, 2}, quickfilter.New(3))
+	})
+}
+
+func TestRemoveMarked(t *testing.T) {
+	t.Run("should remove the marked elements", func(t *testing.T) {
+		items := make([]int, 130)
+		for i := range items {
+			items[i] = i
+		}
+		qf := quickfilter.New(130)
+		for i := 1; i < 130; i++ {
+			if i != 64 && i != 129 {
+				qf = qf.Add(i)
+			}
+		}
+		expected := []int{0, 64, 129}
+
+		received := items[:quickfilter.RemoveMarked(items, qf)]
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should ignore trailing bits", func(t *testing.T) {
+		items := []int{0, 1, 2}
+		qf := quickfilter.New(3).Add(0).Add(2)
+		// Set bits 5 and 9 beyond the Cap() of qf through a grown copy that
+		// shares its last word.
+		qf.Resize(10).Add(5).Add(9)
+		expected := []int{1}
+
+		received := items[:quickfilter.RemoveMarked(items, qf)]
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// Compare returns an integer comparing two QuickFilters, defining a total
+// order over QuickFilters that allows sorting and deduplicating them
+// deterministically. The result is 0 if they are equal, -1 if qf is less
+// than qf2 and +1 if qf is greater than qf2.
+//
+// QuickFilters are ordered by Cap() first. QuickFilters of the same Cap() are
+// ordered by their bits as strings starting from offset 0, so the
+// QuickFilter that has the first differing offset set is the greater one.
+func (qf QuickFilter) Compare(qf2 QuickFilter) int {
+	switch {
+	case qf.sourceLen < qf2.sourceLen:
+		return -1
+	case qf.sourceLen > qf2.sourceLen:
+		return 1
+	}
+	last := len(qf.bits) - 1
+	for i := range qf.bits {
+		a, b := qf.bits[i], qf2.bits[i]
+		if i == last {
+			mask := lastWordMask(qf.sourceLen)
+			a, b = a&mask, b&mask
+		}
+		if a == b {
+			continue
+		}
+		if a&(1<<uint(bits.TrailingZeros(a^b))) != 0 {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// Key returns a comparable value identifying the Cap() and set values of the
+// QuickFilter, so that QuickFilters can be used as map keys, such as for
+// memoizing computations that depend on a filter. Two QuickFilters have the
+// same Key if and only if they are equal.
+//
+// The Key is the binary encoding of the QuickFilter, so it takes about
+// Cap()/8 bytes.
+func (qf QuickFilter) Key() string {
+	data, _ := qf.MarshalBinary()
+	return string(data)
+}
@@ -0,0 +1,89 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestCompare(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		qf1 := quickfilter.NewFilled(70)
+		qf2 := quickfilter.NewFromFunc(70, func(i int) bool { return true })
+		expected := 0
+
+		received := qf1.Compare(qf2)
+
+		if expected != received {
+			t.Errorf("expected %d, got %d", expected, received)
+		}
+	})
+
+	t.Run("by Cap", func(t *testing.T) {
+		qf1 := quickfilter.NewFilled(10)
+		qf2 := quickfilter.New(11)
+		expected := -1
+
+		received := qf1.Compare(qf2)
+
+		if expected != received {
+			t.Errorf("expected %d, got %d", expected, received)
+		}
+	})
+
+	t.Run("by first differing offset", func(t *testing.T) {
+		qf1 := quickfilter.NewFromIndices(100, []int{1, 70})
+		qf2 := quickfilter.NewFromIndices(100, []int{1, 2, 99})
+		expected := 1
+
+		received := qf2.Compare(qf1)
+
+		if expected != received {
+			t.Errorf("expected %d, got %d", expected, received)
+		}
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		qfs := []quickfilter.QuickFilter{
+			quickfilter.NewFromIndices(100, []int{1}),
+			quickfilter.NewFromIndices(100, []int{0}),
+			quickfilter.NewFromIndices(100, []int{}),
+			quickfilter.NewFromIndices(100, []int{1, 99}),
+		}
+		expected := []string{
+			"quickfilter.NewFromIndices(100, []int{})",
+			"quickfilter.NewFromIndices(100, []int{1})",
+			"quickfilter.NewFromIndices(100, []int{1, 99})",
+			"quickfilter.NewFromIndices(100, []int{0})",
+		}
+
+		sort.Slice(qfs, func(i, j int) bool { return qfs[i].Compare(qfs[j]) < 0 })
+		received := make([]string, len(qfs))
+		for i, qf := range qfs {
+			received[i] = qf.GoString()
+		}
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Key", func(t *testing.T) {
+		memo := map[string]int{}
+		qf1 := quickfilter.NewFilled(70)
+		qf2 := quickfilter.NewFromFunc(70, func(i int) bool { return true })
+		qf3 := quickfilter.NewFilled(71)
+		expectedLen := 2
+
+		memo[qf1.Key()]++
+		memo[qf2.Key()]++
+		memo[qf3.Key()]++
+		receivedLen := len(memo)
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package quickfilter
+
+import "sync/atomic"
+
+// ComponentMasks tracks which components each entity of an
+// entity-component-system has, as one QuickFilter of entity indices per
+// component. Systems select the entities they operate on with queries such
+// as
+//
+//	query := cm.With("position", "velocity").Without("frozen")
+//
+// which are evaluated with a single fused pass over the component masks.
+type ComponentMasks struct {
+	fs    FilterSet
+	empty QuickFilter
+}
+
+// NewComponentMasks returns a new ComponentMasks for the given number of
+// entities, without any components.
+func NewComponentMasks(entities int) ComponentMasks {
+	return ComponentMasks{fs: NewFilterSet(entities), empty: New(entities)}
+}
+
+// Cap returns the number of entities.
+func (cm ComponentMasks) Cap() int {
+	return cm.fs.Cap()
+}
+
+// Mask returns the QuickFilter of the entities that have the component. The
+// returned QuickFilter is owned by the ComponentMasks and must not be
+// modified. A component that no entity has yet gets a shared empty
+// QuickFilter.
+func (cm ComponentMasks) Mask(component string) QuickFilter {
+	qf, ok := cm.fs.Get(component)
+	if !ok {
+		return cm.empty
+	}
+	return qf
+}
+
+// Has returns a boolean indicating whether the entity has the component.
+func (cm ComponentMasks) Has(entity int, component string) bool {
+	qf, ok := cm.fs.Get(component)
+	return ok && qf.Has(entity)
+}
+
+// Attach the component to the entity.
+//
+// The original ComponentMasks is no longer usable and must be replaced with
+// the returned one.
+func (cm ComponentMasks) Attach(entity int, component string) ComponentMasks {
+	qf, ok := cm.fs.Get(component)
+	if !ok {
+		qf = New(cm.fs.sourceLen)
+	}
+	if !qf.Has(entity) {
+		cm.fs = cm.fs.Set(component, qf.Add(entity))
+	}
+	return cm
+}
+
+// Detach the component from the entity.
+//
+// The original ComponentMasks is no longer usable and must be replaced with
+// the returned one.
+func (cm ComponentMasks) Detach(entity int, component string) ComponentMasks {
+	if qf, ok := cm.fs.Get(component); ok {
+		cm.fs = cm.fs.Set(component, qf.Delete(entity))
+	}
+	return cm
+}
+
+// DetachAll detaches all components from the entity, such as when it is
+// destroyed.
+//
+// The original ComponentMasks is no longer usable and must be replaced with
+// the returned one.
+func (cm ComponentMasks) DetachAll(entity int) ComponentMasks {
+	for component, qf := range cm.fs.filters {
+		cm.fs.filters[component] = qf.Delete(entity)
+	}
+	return cm
+}
+
+// With returns a ComponentQuery for the entities that have all of the
+// components.
+func (cm ComponentMasks) With(components ...string) ComponentQuery {
+	return ComponentQuery{cm: cm}.With(components...)
+}
+
+// Without returns a ComponentQuery for the entities that have none of the
+// components.
+func (cm ComponentMasks) Without(components ...string) ComponentQuery {
+	return ComponentQuery{cm: cm}.Without(components...)
+}
+
+// ComponentQuery selects the entities of a ComponentMasks by the components
+// they have and don't have. The query is evaluated against the current state
+// of the ComponentMasks, so it can be built once and evaluated every frame.
+type ComponentQuery struct {
+	cm      ComponentMasks
+	with    []string
+	without []string
+	planned *atomic.Value
+}
+
+type plannedComponentQuery struct {
+	expr       Expr
+	components int
+}
+
+// With returns a ComponentQuery that additionally requires the entities to
+// have all of the components.
+func (q ComponentQuery) With(components ...string) ComponentQuery {
+	q.with = append(q.with[:len(q.with):len(q.with)], components...)
+	q.planned = new(atomic.Value)
+	return q
+}
+
+// Without returns a ComponentQuery that additionally requires the entities
+// to have none of the components.
+func (q ComponentQuery) Without(components ...string) ComponentQuery {
+	q.without = append(q.without[:len(q.without):len(q.without)], components...)
+	q.planned = new(atomic.Value)
+	return q
+}
+
+// Expr returns the query as an Expr over the component masks, planned for
+// evaluation. The Expr is built on the first call and reused until a
+// component that no entity had is attached, since the masks it refers to are
+// updated in place by Attach and Detach.
+func (q ComponentQuery) Expr() Expr {
+	// Components are never removed from the set, so its size only changes
+	// when a new mask is created.
+	components := q.cm.fs.Len()
+	if q.planned != nil {
+		if p, ok := q.planned.Load().(plannedComponentQuery); ok && p.components == components {
+			return p.expr
+		}
+	}
+	expr := q.expr()
+	if q.planned != nil {
+		q.planned.Store(plannedComponentQuery{expr: expr, components: components})
+	}
+	return expr
+}
+
+func (q ComponentQuery) expr() Expr {
+	operands := make([]Expr, 0, len(q.with)+len(q.without))
+	for _, component := range q.with {
+		qf, ok := q.cm.fs.Get(component)
+		if !ok {
+			return Leaf(q.cm.empty)
+		}
+		operands = append(operands, Leaf(qf))
+	}
+	for _, component := range q.without {
+		if qf, ok := q.cm.fs.Get(component); ok {
+			operands = append(operands, Not(Leaf(qf)))
+		}
+	}
+	return And(operands...).Plan()
+}
+
+// Eval fills dst with the entities matching the query. dst is resized to the
+// Cap() of the ComponentMasks.
+func (q ComponentQuery) Eval(dst QuickFilter) QuickFilter {
+	return q.Expr().Eval(dst.Resize(q.cm.Cap()))
+}
@@ -0,0 +1,153 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestComponentMasks(t *testing.T) {
+	newComponentMasks := func() quickfilter.ComponentMasks {
+		cm := quickfilter.NewComponentMasks(100)
+		for i := 0; i < 100; i++ {
+			cm = cm.Attach(i, "position")
+			if i%2 == 0 {
+				cm = cm.Attach(i, "velocity")
+			}
+			if i%3 == 0 {
+				cm = cm.Attach(i, "frozen")
+			}
+		}
+		return cm
+	}
+
+	t.Run("Attach and Has", func(t *testing.T) {
+		cm := newComponentMasks()
+
+		cm = cm.Attach(1, "velocity").Attach(1, "velocity")
+
+		if !cm.Has(1, "velocity") || cm.Mask("velocity").Len() != 51 {
+			t.Errorf("expected 51 entities with velocity, got %d", cm.Mask("velocity").Len())
+		}
+	})
+
+	t.Run("Detach", func(t *testing.T) {
+		cm := newComponentMasks()
+
+		cm = cm.Detach(2, "velocity").Detach(2, "unknown")
+
+		if cm.Has(2, "velocity") || !cm.Has(2, "position") {
+			t.Error("expected only velocity to be detached")
+		}
+	})
+
+	t.Run("DetachAll", func(t *testing.T) {
+		cm := newComponentMasks()
+
+		cm = cm.DetachAll(6)
+
+		if cm.Has(6, "position") || cm.Has(6, "velocity") || cm.Has(6, "frozen") {
+			t.Error("expected all components to be detached")
+		}
+	})
+
+	t.Run("With and Without", func(t *testing.T) {
+		cm := newComponentMasks()
+		expected := []int{2, 4, 8, 10, 14, 16}
+
+		received := collect(cm.With("position", "velocity").Without("frozen").Eval(quickfilter.New(0)))[:6]
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("query sees later changes", func(t *testing.T) {
+		cm := newComponentMasks()
+		query := cm.With("velocity").Without("frozen")
+		expectedLen := query.Eval(quickfilter.New(0)).Len() - 1
+
+		cm = cm.Attach(2, "frozen")
+		receivedLen := query.Eval(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("query sees later attached component", func(t *testing.T) {
+		cm := newComponentMasks()
+		query := cm.With("position").Without("sprite")
+		expectedLen := query.Eval(quickfilter.New(0)).Len() - 1
+
+		cm = cm.Attach(2, "sprite")
+		receivedLen := query.Eval(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("repeated evaluation should not allocate", func(t *testing.T) {
+		cm := newComponentMasks()
+		query := cm.With("position", "velocity").Without("frozen", "sprite")
+		dst := query.Eval(quickfilter.New(0))
+		expectedAllocs := 0.0
+
+		receivedAllocs := testing.AllocsPerRun(10, func() {
+			dst = query.Eval(dst)
+		})
+
+		if expectedAllocs != receivedAllocs {
+			t.Errorf("expected %v, got %v", expectedAllocs, receivedAllocs)
+		}
+	})
+
+	t.Run("With unknown component", func(t *testing.T) {
+		cm := newComponentMasks()
+		expectedLen := 0
+
+		receivedLen := cm.With("position", "sprite").Eval(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("concurrent queries with unknown component", func(t *testing.T) {
+		cm := newComponentMasks()
+		var wg sync.WaitGroup
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				cm.With("position").Without(fmt.Sprint("unknown", i)).Eval(quickfilter.New(0))
+			}(i)
+		}
+		wg.Wait()
+
+		if cm.Has(0, "unknown0") {
+			t.Error("expected no entities with unknown0")
+		}
+	})
+}
+
+func ExampleComponentMasks() {
+	cm := quickfilter.NewComponentMasks(4)
+	cm = cm.Attach(0, "position").Attach(0, "velocity")
+	cm = cm.Attach(1, "position")
+	cm = cm.Attach(2, "position").Attach(2, "velocity").Attach(2, "frozen")
+	cm = cm.Attach(3, "position").Attach(3, "velocity")
+	moving := cm.With("position", "velocity").Without("frozen")
+	qf := moving.Eval(quickfilter.New(cm.Cap()))
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		fmt.Println(it.Value())
+	}
+	// Output:
+	// 0
+	// 3
+}
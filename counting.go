@@ -0,0 +1,131 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// CountingFilter stores a small saturating counter per index instead of a
+// single bit, which allows expressing semantics such as "matched by at least
+// 2 of 5 rules" that a QuickFilter can't.
+type CountingFilter struct {
+	sourceLen   int
+	counterBits uint
+	max         uint
+	words       []uint
+}
+
+// NewCountingFilter returns a new CountingFilter with a counter of
+// counterBits bits for each of the sourceLen offsets. counterBits must be 2, 4
+// or 8, allowing counts up to 3, 15 and 255 respectively, or this will panic.
+func NewCountingFilter(sourceLen, counterBits int) CountingFilter {
+	if counterBits != 2 && counterBits != 4 && counterBits != 8 {
+		panic("counterBits must be 2, 4 or 8")
+	}
+	countersPerWord := bits.UintSize / counterBits
+	return CountingFilter{
+		sourceLen:   sourceLen,
+		counterBits: uint(counterBits),
+		max:         1<<uint(counterBits) - 1,
+		words:       make([]uint, (sourceLen+countersPerWord-1)/countersPerWord),
+	}
+}
+
+// Cap returns the number of counters.
+func (cf CountingFilter) Cap() int {
+	return cf.sourceLen
+}
+
+// Max returns the largest count a counter can hold.
+func (cf CountingFilter) Max() int {
+	return int(cf.max)
+}
+
+// Count returns the count of the index.
+func (cf CountingFilter) Count(index int) int {
+	wordIndex, shift := cf.offsets(index)
+	return int(cf.words[wordIndex] >> shift & cf.max)
+}
+
+// Increment the count of the index. The count saturates at Max().
+//
+// The original CountingFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the CountingFilter from escaping
+// to the heap.
+func (cf CountingFilter) Increment(index int) CountingFilter {
+	wordIndex, shift := cf.offsets(index)
+	if cf.words[wordIndex]>>shift&cf.max != cf.max {
+		cf.words[wordIndex] += 1 << shift
+	}
+	return cf
+}
+
+// Decrement the count of the index. The count saturates at zero.
+//
+// The original CountingFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the CountingFilter from escaping
+// to the heap.
+func (cf CountingFilter) Decrement(index int) CountingFilter {
+	wordIndex, shift := cf.offsets(index)
+	if cf.words[wordIndex]>>shift&cf.max != 0 {
+		cf.words[wordIndex] -= 1 << shift
+	}
+	return cf
+}
+
+// IncrementAll increments the count of every index set in qf.
+//
+// The passed QuickFilter must have a Cap() equal to the Cap() of the
+// CountingFilter or this will panic.
+//
+// The original CountingFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the CountingFilter from escaping
+// to the heap.
+func (cf CountingFilter) IncrementAll(qf QuickFilter) CountingFilter {
+	if qf.sourceLen != cf.sourceLen {
+		panic("QuickFilter must be the same size as the CountingFilter")
+	}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		cf = cf.Increment(it.Value())
+	}
+	return cf
+}
+
+// Clear all the counters.
+//
+// The original CountingFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the CountingFilter from escaping
+// to the heap.
+func (cf CountingFilter) Clear() CountingFilter {
+	for i := range cf.words {
+		cf.words[i] = 0
+	}
+	return cf
+}
+
+// AtLeast fills dst with the indices whose count is at least k. dst is
+// resized to the Cap() of the CountingFilter.
+func (cf CountingFilter) AtLeast(dst QuickFilter, k int) QuickFilter {
+	dst = dst.Resize(cf.sourceLen).Clear()
+	if k <= 0 {
+		return dst.Fill()
+	}
+	countersPerWord := bits.UintSize / int(cf.counterBits)
+	for wordIndex, word := range cf.words {
+		if word == 0 {
+			continue
+		}
+		index := wordIndex * countersPerWord
+		for ; word != 0; word >>= cf.counterBits {
+			if int(word&cf.max) >= k {
+				dst = dst.Add(index)
+			}
+			index++
+		}
+	}
+	return dst
+}
+
+func (cf CountingFilter) offsets(index int) (wordIndex int, shift uint) {
+	countersPerWord := bits.UintSize / int(cf.counterBits)
+	return index / countersPerWord, uint(index%countersPerWord) * cf.counterBits
+}
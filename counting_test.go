@@ -0,0 +1,60 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestCountingFilter(t *testing.T) {
+	t.Run("Increment and Decrement", func(t *testing.T) {
+		cf := quickfilter.NewCountingFilter(100, 4)
+		expectedCount := 2
+
+		cf = cf.Increment(40).Increment(40).Increment(40).Decrement(40).Increment(41)
+		receivedCount := cf.Count(40)
+
+		if expectedCount != receivedCount {
+			t.Errorf("expected %d, got %d", expectedCount, receivedCount)
+		}
+		if cf.Count(41) != 1 || cf.Count(39) != 0 {
+			t.Errorf("expected neighbouring counts 1 and 0, got %d and %d", cf.Count(41), cf.Count(39))
+		}
+	})
+
+	t.Run("saturation", func(t *testing.T) {
+		cf := quickfilter.NewCountingFilter(10, 2)
+
+		for i := 0; i < 5; i++ {
+			cf = cf.Increment(1)
+		}
+		cf = cf.Decrement(2)
+
+		if cf.Count(1) != cf.Max() {
+			t.Errorf("expected %d, got %d", cf.Max(), cf.Count(1))
+		}
+		if cf.Count(0) != 0 || cf.Count(2) != 0 {
+			t.Errorf("expected neighbouring counts 0 and 0, got %d and %d", cf.Count(0), cf.Count(2))
+		}
+	})
+
+	t.Run("AtLeast", func(t *testing.T) {
+		rules := []quickfilter.QuickFilter{
+			quickfilter.New(100).Add(1).Add(2).Add(99),
+			quickfilter.New(100).Add(2).Add(3).Add(99),
+			quickfilter.New(100).Add(3).Add(99),
+		}
+		cf := quickfilter.NewCountingFilter(100, 8)
+		expected := []int{2, 3, 99}
+
+		for _, rule := range rules {
+			cf = cf.IncrementAll(rule)
+		}
+		received := collect(cf.AtLeast(quickfilter.New(0), 2))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
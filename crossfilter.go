@@ -0,0 +1,183 @@
+package quickfilter
+
+import (
+	"math"
+)
+
+// Crossfilter implements the interactive dashboard pattern over a source
+// slice: it has a number of dimensions, each of which can be filtered
+// independently, and maintains the combined selection of all the dimension
+// filters along with per-dimension group counts.
+//
+// As in the classic crossfilter, the group counts of a dimension reflect the
+// filters of all the other dimensions but not its own, so that a dimension
+// shows what selecting each of its groups would yield.
+type Crossfilter struct {
+	sourceLen  int
+	dimensions []crossfilterDimension
+	names      map[string]int
+	selection  QuickFilter
+	scratch    QuickFilter
+}
+
+type crossfilterDimension struct {
+	values []float64
+	groups map[interface{}]QuickFilter
+	filter QuickFilter
+}
+
+// NewCrossfilter returns a new Crossfilter without any dimensions over a
+// source slice of length sourceLen.
+func NewCrossfilter(sourceLen int) Crossfilter {
+	return Crossfilter{
+		sourceLen: sourceLen,
+		names:     make(map[string]int),
+		selection: NewFilled(sourceLen),
+		scratch:   New(sourceLen),
+	}
+}
+
+// AddCategorical registers a categorical dimension using value to extract
+// the value of the element at index i of the source slice. The groups of the
+// dimension are its distinct values, which must be comparable.
+//
+// The original Crossfilter is no longer usable and must be replaced with the
+// returned one.
+func (cf Crossfilter) AddCategorical(name string, value func(i int) interface{}) Crossfilter {
+	return cf.addDimension(name, nil, value)
+}
+
+// AddNumeric registers a numeric dimension using value to extract the value
+// of the element at index i of the source slice. The groups of the dimension
+// are bins of binWidth, keyed by the float64 lower bound of the bin.
+//
+// The original Crossfilter is no longer usable and must be replaced with the
+// returned one.
+func (cf Crossfilter) AddNumeric(name string, value func(i int) float64, binWidth float64) Crossfilter {
+	values := make([]float64, cf.sourceLen)
+	for i := range values {
+		values[i] = value(i)
+	}
+	return cf.addDimension(name, values, func(i int) interface{} {
+		return math.Floor(values[i]/binWidth) * binWidth
+	})
+}
+
+// FilterRange filters a numeric dimension to the elements whose value is in
+// the range [lo, hi).
+//
+// Passing the name of a dimension that is not registered or not numeric will
+// panic.
+//
+// The original Crossfilter is no longer usable and must be replaced with the
+// returned one.
+func (cf Crossfilter) FilterRange(name string, lo, hi float64) Crossfilter {
+	d := &cf.dimensions[cf.mustDimension(name)]
+	if d.values == nil {
+		panic("dimension is not numeric: " + name)
+	}
+	d.filter = d.filter.Clear()
+	for i, v := range d.values {
+		if v >= lo && v < hi {
+			d.filter = d.filter.Add(i)
+		}
+	}
+	return cf.update()
+}
+
+// FilterValues filters a dimension to the elements in any of the given
+// groups.
+//
+// Passing the name of a dimension that is not registered will panic.
+//
+// The original Crossfilter is no longer usable and must be replaced with the
+// returned one.
+func (cf Crossfilter) FilterValues(name string, groups ...interface{}) Crossfilter {
+	d := &cf.dimensions[cf.mustDimension(name)]
+	d.filter = d.filter.Clear()
+	for _, group := range groups {
+		if qf, ok := d.groups[group]; ok {
+			d.filter = d.filter.UnionOf(d.filter, qf)
+		}
+	}
+	return cf.update()
+}
+
+// FilterAll removes the filter of a dimension.
+//
+// Passing the name of a dimension that is not registered will panic.
+//
+// The original Crossfilter is no longer usable and must be replaced with the
+// returned one.
+func (cf Crossfilter) FilterAll(name string) Crossfilter {
+	d := &cf.dimensions[cf.mustDimension(name)]
+	d.filter = d.filter.Fill()
+	return cf.update()
+}
+
+// Selection returns the elements selected by the filters of all the
+// dimensions. The returned QuickFilter is owned by the Crossfilter and must
+// not be modified; it is only valid until the next change to the filters.
+func (cf Crossfilter) Selection() QuickFilter {
+	return cf.selection
+}
+
+// GroupCounts returns the number of elements in each group of a dimension
+// that are selected by the filters of all the other dimensions.
+//
+// Passing the name of a dimension that is not registered will panic.
+func (cf Crossfilter) GroupCounts(name string) map[interface{}]int {
+	index := cf.mustDimension(name)
+	operands := make([]Expr, 0, len(cf.dimensions))
+	for i, d := range cf.dimensions {
+		if i != index {
+			operands = append(operands, Leaf(d.filter))
+		}
+	}
+	base := And(operands...).Eval(cf.scratch)
+	d := cf.dimensions[index]
+	counts := make(map[interface{}]int, len(d.groups))
+	for group, qf := range d.groups {
+		counts[group] = base.IntersectionCount(qf)
+	}
+	return counts
+}
+
+func (cf Crossfilter) addDimension(name string, values []float64, group func(i int) interface{}) Crossfilter {
+	if _, ok := cf.names[name]; ok {
+		panic("dimension already registered: " + name)
+	}
+	groups := make(map[interface{}]QuickFilter)
+	for i := 0; i < cf.sourceLen; i++ {
+		g := group(i)
+		qf, ok := groups[g]
+		if !ok {
+			qf = New(cf.sourceLen)
+		}
+		groups[g] = qf.Add(i)
+	}
+	cf.names[name] = len(cf.dimensions)
+	cf.dimensions = append(cf.dimensions, crossfilterDimension{
+		values: values,
+		groups: groups,
+		filter: NewFilled(cf.sourceLen),
+	})
+	return cf
+}
+
+func (cf Crossfilter) update() Crossfilter {
+	operands := make([]Expr, len(cf.dimensions))
+	for i, d := range cf.dimensions {
+		operands[i] = Leaf(d.filter)
+	}
+	cf.selection = And(operands...).Eval(cf.selection)
+	return cf
+}
+
+func (cf Crossfilter) mustDimension(name string) int {
+	index, ok := cf.names[name]
+	if !ok {
+		panic("unknown dimension in Crossfilter: " + name)
+	}
+	return index
+}
@@ -0,0 +1,73 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestCrossfilter(t *testing.T) {
+	newCrossfilter := func() quickfilter.Crossfilter {
+		cf := quickfilter.NewCrossfilter(len(mockUsers))
+		cf = cf.AddCategorical("region", func(i int) interface{} { return mockUsers[i].region })
+		cf = cf.AddNumeric("tier", func(i int) float64 { return float64(mockUsers[i].tier) }, 2)
+		return cf
+	}
+
+	t.Run("Selection without filters", func(t *testing.T) {
+		cf := newCrossfilter()
+		expectedLen := len(mockUsers)
+
+		receivedLen := cf.Selection().Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("FilterValues and FilterRange", func(t *testing.T) {
+		cf := newCrossfilter()
+		expected := []int{1, 3}
+
+		cf = cf.FilterValues("region", "eu")
+		cf = cf.FilterRange("tier", 2, 4)
+		received := collect(cf.Selection())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("FilterAll", func(t *testing.T) {
+		cf := newCrossfilter()
+		expected := []int{1, 2, 3}
+
+		cf = cf.FilterValues("region", "eu")
+		cf = cf.FilterRange("tier", 2, 4)
+		cf = cf.FilterAll("region")
+		received := collect(cf.Selection())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("GroupCounts should ignore own filter", func(t *testing.T) {
+		cf := newCrossfilter()
+		expectedRegions := map[interface{}]int{"eu": 2, "us": 1}
+		expectedTiers := map[interface{}]int{0.0: 1, 2.0: 2}
+
+		cf = cf.FilterValues("region", "eu")
+		cf = cf.FilterRange("tier", 2, 4)
+		receivedRegions := cf.GroupCounts("region")
+		receivedTiers := cf.GroupCounts("tier")
+
+		if !reflect.DeepEqual(expectedRegions, receivedRegions) {
+			t.Errorf("expected %v, got %v", expectedRegions, receivedRegions)
+		}
+		if !reflect.DeepEqual(expectedTiers, receivedTiers) {
+			t.Errorf("expected %v, got %v", expectedTiers, receivedTiers)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package quickfilter
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// FilterCSV copies the rows read from src whose zero-based row index is set
+// in qf to dst, one row at a time, and flushes dst. Any header row should be
+// read from src, and written to dst, before calling FilterCSV, so that the
+// row indices line up with the filter.
+//
+// Reading stops after the last set row index. Setting ReuseRecord on src
+// keeps the memory used flat regardless of the size of the input.
+func FilterCSV(dst *csv.Writer, src *csv.Reader, qf QuickFilter) error {
+	for row, it := 0, qf.Iterate(); !it.Done(); row++ {
+		record, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if row == it.Value() {
+			if err := dst.Write(record); err != nil {
+				return err
+			}
+			it = it.Next()
+		}
+	}
+	dst.Flush()
+	return dst.Error()
+}
+
+// FilterCSVWhere copies the rows read from src that match pred to dst, and
+// returns a QuickFilter of the matching row indices, with a Cap() of the
+// number of rows read. The returned QuickFilter can be reused with FilterCSV
+// to filter other inputs with the same row order without evaluating pred
+// again.
+//
+// The record passed to pred must not be retained if ReuseRecord is set on
+// src.
+func FilterCSVWhere(dst *csv.Writer, src *csv.Reader, pred func(row int, record []string) bool) (QuickFilter, error) {
+	s := NewStream(0)
+	for {
+		record, err := src.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return QuickFilter{}, err
+		}
+		if !pred(s.Cap(), record) {
+			s = s.ObserveDropped()
+			continue
+		}
+		if err := dst.Write(record); err != nil {
+			return QuickFilter{}, err
+		}
+		s = s.ObserveKept()
+	}
+	dst.Flush()
+	if err := dst.Error(); err != nil {
+		return QuickFilter{}, err
+	}
+	return s.Freeze(), nil
+}
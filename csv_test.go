@@ -0,0 +1,54 @@
+package quickfilter_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestFilterCSV(t *testing.T) {
+	src := "alice,30\nbob,17\ncarol,45\ndave,12\n"
+	qf := quickfilter.New(10).Add(0).Add(2).Add(7)
+	var buf bytes.Buffer
+	expected := "alice,30\ncarol,45\n"
+
+	err := quickfilter.FilterCSV(csv.NewWriter(&buf), csv.NewReader(strings.NewReader(src)), qf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := buf.String()
+
+	if expected != received {
+		t.Errorf("expected %q, got %q", expected, received)
+	}
+}
+
+func TestFilterCSVWhere(t *testing.T) {
+	src := "alice,30\nbob,17\ncarol,45\ndave,12\n"
+	r := csv.NewReader(strings.NewReader(src))
+	r.ReuseRecord = true
+	var buf bytes.Buffer
+	expected := "bob,17\ndave,12\n"
+	expectedIndices := []int{1, 3}
+	expectedCap := 4
+
+	qf, err := quickfilter.FilterCSVWhere(csv.NewWriter(&buf), r, func(row int, record []string) bool {
+		return len(record[1]) == 2 && record[1] < "18"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := buf.String()
+	receivedIndices := collect(qf)
+
+	if expected != received {
+		t.Errorf("expected %q, got %q", expected, received)
+	}
+	if !reflect.DeepEqual(expectedIndices, receivedIndices) || expectedCap != qf.Cap() {
+		t.Errorf("expected %v, got %v", expectedIndices, receivedIndices)
+	}
+}
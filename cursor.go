@@ -0,0 +1,116 @@
+package quickfilter
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded.
+var ErrInvalidCursor = errors.New("quickfilter: invalid cursor")
+
+// ErrStaleCursor is returned when a pagination cursor was issued for a
+// different state of the QuickFilter.
+var ErrStaleCursor = errors.New("quickfilter: cursor was issued for a different filter state")
+
+// Page returns up to limit of the set indices following the position of
+// cursor, appended to dst, along with the cursor for the next page. An empty
+// cursor starts from the beginning, and an empty next cursor means there are
+// no more pages.
+//
+// The cursor is an opaque token that records the last returned index and a
+// hash of the contents of the QuickFilter. If the QuickFilter has changed
+// since the cursor was issued, ErrStaleCursor is returned, so the caller can
+// decide whether to restart or to continue from CursorPosition with
+// PageAfter, rather than silently getting duplicated or skipped results.
+func (qf QuickFilter) Page(dst []int, cursor string, limit int) ([]int, string, error) {
+	start := 0
+	if cursor != "" {
+		hash, next, err := decodeCursor(cursor)
+		if err != nil || next > qf.sourceLen {
+			return dst, "", ErrInvalidCursor
+		}
+		if hash != qf.hash() {
+			return dst, "", ErrStaleCursor
+		}
+		start = next
+	}
+	dst, next := qf.PageAfter(dst, start, limit)
+	if next < 0 {
+		return dst, "", nil
+	}
+	data := make([]byte, 8, 8+binary.MaxVarintLen64)
+	binary.LittleEndian.PutUint64(data, qf.hash())
+	data = appendUvarint(data, uint64(next))
+	return dst, base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// CursorPosition returns the start of the page that cursor points to, which
+// can be passed to PageAfter to continue regardless of whether the
+// QuickFilter has changed since the cursor was issued. An empty cursor points
+// to the beginning.
+func CursorPosition(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	_, next, err := decodeCursor(cursor)
+	return next, err
+}
+
+// decodeCursor returns the hash and position recorded in cursor.
+func decodeCursor(cursor string) (uint64, int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(data) < 9 {
+		return 0, 0, ErrInvalidCursor
+	}
+	next, n := binary.Uvarint(data[8:])
+	if n <= 0 || n != len(data)-8 || next > uint64(maxInt) {
+		return 0, 0, ErrInvalidCursor
+	}
+	return binary.LittleEndian.Uint64(data), int(next), nil
+}
+
+// PageAfter returns up to limit of the set indices starting from start,
+// appended to dst, along with the start of the next page, or -1 if there are
+// no more pages. A negative start is treated as 0.
+func (qf QuickFilter) PageAfter(dst []int, start, limit int) ([]int, int) {
+	if start < 0 {
+		start = 0
+	}
+	it := qf.iterateFrom(start)
+	for ; !it.Done() && limit > 0; it = it.Next() {
+		dst = append(dst, it.Value())
+		limit--
+	}
+	if it.Done() {
+		return dst, -1
+	}
+	return dst, it.Value()
+}
+
+// iterateFrom returns an Iterator at the first set offset at or after index.
+func (qf QuickFilter) iterateFrom(index int) Iterator {
+	return Iterator{
+		index:     index - 1,
+		sourceLen: qf.sourceLen,
+		bits:      qf.bits,
+	}.Next()
+}
+
+// hash returns a hash of the source length and the set values.
+func (qf QuickFilter) hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(qf.sourceLen))
+	_, _ = h.Write(buf[:])
+	last := len(qf.bits) - 1
+	for i, word := range qf.bits {
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(word))
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,119 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestPage(t *testing.T) {
+	t.Run("should return all pages", func(t *testing.T) {
+		qf := quickfilter.New(200)
+		for i := 0; i < qf.Cap(); i += 7 {
+			qf = qf.Add(i)
+		}
+		expected := collect(qf)
+
+		received := []int{}
+		pages := 0
+		cursor := ""
+		for {
+			var err error
+			received, cursor, err = qf.Page(received, cursor, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pages++
+			if cursor == "" {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if pages != 3 {
+			t.Errorf("expected %d, got %d", 3, pages)
+		}
+	})
+
+	t.Run("should detect changes", func(t *testing.T) {
+		qf := quickfilter.NewFilled(100)
+
+		_, cursor, _ := qf.Page(nil, "", 10)
+		qf = qf.Delete(50)
+		_, _, err := qf.Page(nil, cursor, 10)
+
+		if err != quickfilter.ErrStaleCursor {
+			t.Errorf("expected %v, got %v", quickfilter.ErrStaleCursor, err)
+		}
+	})
+
+	t.Run("should continue from a stale cursor with PageAfter", func(t *testing.T) {
+		qf := quickfilter.NewFilled(100)
+		expected := []int{10, 11}
+
+		_, cursor, _ := qf.Page(nil, "", 10)
+		qf = qf.Delete(50)
+		_, _, err := qf.Page(nil, cursor, 2)
+		if err != quickfilter.ErrStaleCursor {
+			t.Fatalf("expected %v, got %v", quickfilter.ErrStaleCursor, err)
+		}
+		start, err := quickfilter.CursorPosition(cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received, _ := qf.PageAfter(nil, start, 2)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("CursorPosition of invalid cursor", func(t *testing.T) {
+		_, err := quickfilter.CursorPosition("not a cursor")
+
+		if err != quickfilter.ErrInvalidCursor {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidCursor, err)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		qf := quickfilter.NewFilled(100)
+
+		_, _, err := qf.Page(nil, "not a cursor", 10)
+
+		if err != quickfilter.ErrInvalidCursor {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidCursor, err)
+		}
+	})
+
+	t.Run("PageAfter", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(3).Add(64).Add(65).Add(99)
+		expected := []int{64, 65}
+
+		received, next := qf.PageAfter(nil, 4, 2)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if next != 99 {
+			t.Errorf("expected %d, got %d", 99, next)
+		}
+	})
+
+	t.Run("PageAfter negative start", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(0).Add(64)
+		expected := []int{0}
+
+		received, next := qf.PageAfter(nil, -1, 1)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if next != 64 {
+			t.Errorf("expected %d, got %d", 64, next)
+		}
+	})
+}
@@ -0,0 +1,6 @@
+//go:build quickfilter_debug
+// +build quickfilter_debug
+
+package quickfilter
+
+const debugChecks = true
@@ -0,0 +1,144 @@
+// Package debughttp provides an http.Handler for inspecting the QuickFilters
+// of a live service, in the spirit of expvar.
+//
+// Filters are published under a name along with a function that returns
+// their current state. The handler serves a JSON object with the statistics
+// of every published filter, and, given an image query parameter naming a
+// filter, a PNG density map of its bits where darker pixels have more bits
+// set:
+//
+//	GET /debug/quickfilter
+//	GET /debug/quickfilter?image=active&width=512
+package debughttp
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+// Handler serves the statistics of the published QuickFilters.
+type Handler struct {
+	mu      sync.RWMutex
+	filters map[string]func() quickfilter.QuickFilter
+}
+
+// NewHandler returns a new Handler without any published QuickFilters.
+func NewHandler() *Handler {
+	return &Handler{filters: make(map[string]func() quickfilter.QuickFilter)}
+}
+
+// Publish a QuickFilter under the given name, replacing any existing one. The
+// function f is called for every request to get the current state of the
+// QuickFilter, and must be safe to call concurrently with the service
+// updating the filter.
+func (h *Handler) Publish(name string, f func() quickfilter.QuickFilter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.filters[name] = f
+}
+
+// Remove the QuickFilter published under the given name.
+func (h *Handler) Remove(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.filters, name)
+}
+
+// Stats contains the statistics served for a QuickFilter.
+type Stats struct {
+	Cap     int     `json:"cap"`
+	Len     int     `json:"len"`
+	Density float64 `json:"density"`
+	Bytes   int     `json:"bytes"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if name := r.URL.Query().Get("image"); name != "" {
+		h.serveImage(w, r, name)
+		return
+	}
+	h.mu.RLock()
+	names := make([]string, 0, len(h.filters))
+	for name := range h.filters {
+		names = append(names, name)
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+	stats := make(map[string]Stats, len(names))
+	for _, name := range names {
+		if qf, ok := h.get(name); ok {
+			stats[name] = statsOf(qf)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+func (h *Handler) serveImage(w http.ResponseWriter, r *http.Request, name string) {
+	qf, ok := h.get(name)
+	if !ok {
+		http.Error(w, "unknown filter", http.StatusNotFound)
+		return
+	}
+	width := 256
+	if s := r.URL.Query().Get("width"); s != "" {
+		var err error
+		if width, err = strconv.Atoi(s); err != nil || width < 1 || width > 4096 {
+			http.Error(w, "invalid width", http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_ = png.Encode(w, densityMap(qf, width))
+}
+
+func (h *Handler) get(name string) (quickfilter.QuickFilter, bool) {
+	h.mu.RLock()
+	f, ok := h.filters[name]
+	h.mu.RUnlock()
+	if !ok {
+		return quickfilter.QuickFilter{}, false
+	}
+	return f(), true
+}
+
+func statsOf(qf quickfilter.QuickFilter) Stats {
+	return Stats{
+		Cap:     qf.Cap(),
+		Len:     qf.Len(),
+		Density: qf.Density(),
+		Bytes:   qf.SizeBytes(),
+	}
+}
+
+// densityMap returns an image of the given width where each pixel covers an
+// equal run of bits, shaded by the fraction of them that are set.
+func densityMap(qf quickfilter.QuickFilter, width int) *image.Gray {
+	bitsPerPixel := (qf.Cap() + width*width - 1) / (width * width)
+	if bitsPerPixel < 1 {
+		bitsPerPixel = 1
+	}
+	pixels := (qf.Cap() + bitsPerPixel - 1) / bitsPerPixel
+	height := (pixels + width - 1) / width
+	if height < 1 {
+		height = 1
+	}
+	counts := make([]int, width*height)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		counts[it.Value()/bitsPerPixel]++
+	}
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i, count := range counts {
+		img.SetGray(i%width, i/width, color.Gray{Y: uint8(255 - count*255/bitsPerPixel)})
+	}
+	return img
+}
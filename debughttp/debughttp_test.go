@@ -0,0 +1,69 @@
+package debughttp_test
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+	"github.com/jussi-kalliokoski/quickfilter/debughttp"
+)
+
+func TestHandler(t *testing.T) {
+	qf := quickfilter.New(1000)
+	for i := 0; i < 250; i++ {
+		qf = qf.Add(i)
+	}
+	h := debughttp.NewHandler()
+	h.Publish("first quarter", func() quickfilter.QuickFilter { return qf })
+
+	t.Run("stats", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		expected := map[string]debughttp.Stats{
+			"first quarter": {Cap: 1000, Len: 250, Density: 0.25, Bytes: qf.SizeBytes()},
+		}
+
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		var received map[string]debughttp.Stats
+		if err := json.NewDecoder(rec.Body).Decode(&received); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/?image=first+quarter&width=10", nil))
+		img, err := png.Decode(rec.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if img.Bounds().Dx() != 10 || img.Bounds().Dy() != 10 {
+			t.Errorf("expected a 10x10 image, got %v", img.Bounds())
+		}
+		if r, _, _, _ := img.At(0, 0).RGBA(); r != 0 {
+			t.Errorf("expected a black pixel, got %v", img.At(0, 0))
+		}
+		if r, _, _, _ := img.At(9, 9).RGBA(); r != 0xffff {
+			t.Errorf("expected a white pixel, got %v", img.At(9, 9))
+		}
+	})
+
+	t.Run("unknown image", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/?image=other", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
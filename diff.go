@@ -0,0 +1,174 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// FilterDiff describes the change between two versions of a QuickFilter.
+type FilterDiff struct {
+	// Added contains the values set in the new version but not in the old.
+	Added QuickFilter
+	// Removed contains the values set in the old version but not in the new.
+	Removed QuickFilter
+}
+
+// Diff returns the FilterDiff between the old and new versions of a
+// QuickFilter, computed in a single pass.
+//
+// The passed QuickFilters must be the same size or this will panic.
+func Diff(old, new QuickFilter) FilterDiff {
+	if len(old.bits) != len(new.bits) {
+		panic("passed QuickFilters must be the same size")
+	}
+	d := FilterDiff{
+		Added:   New(new.sourceLen),
+		Removed: New(new.sourceLen),
+	}
+	last := len(new.bits) - 1
+	for i := range new.bits {
+		oldWord, newWord := old.bits[i], new.bits[i]
+		if i == last {
+			mask := lastWordMask(new.sourceLen)
+			oldWord &= mask
+			newWord &= mask
+		}
+		d.Added.bits[i] = newWord &^ oldWord
+		d.Removed.bits[i] = oldWord &^ newWord
+		d.Added.len += bits.OnesCount(d.Added.bits[i])
+		d.Removed.len += bits.OnesCount(d.Removed.bits[i])
+	}
+	return d
+}
+
+// Empty returns a boolean indicating whether the FilterDiff has no changes.
+func (d FilterDiff) Empty() bool {
+	return d.Added.Len() == 0 && d.Removed.Len() == 0
+}
+
+// ApplyDiff applies the changes described by d to the QuickFilter: the
+// values in d.Removed are cleared and the values in d.Added are set.
+//
+// The receiver and the QuickFilters of the FilterDiff must all be the same
+// size or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) ApplyDiff(d FilterDiff) QuickFilter {
+	if len(qf.bits) != len(d.Added.bits) || len(qf.bits) != len(d.Removed.bits) {
+		panic("receiver and FilterDiff QuickFilters must be the same size")
+	}
+	for i := range qf.bits {
+		qf.bits[i] = qf.bits[i]&^d.Removed.bits[i] | d.Added.bits[i]
+	}
+	return qf.Recount()
+}
+
+// MergeDiffs returns a single FilterDiff with the same effect as applying a
+// followed by b, where b describes changes made after a was applied.
+//
+// Changes that cancel out are dropped from the result: a value added by a
+// and removed by b appears in neither Added nor Removed, and neither does a
+// value removed by a and added back by b.
+//
+// The QuickFilters of the passed FilterDiffs must all be the same size or
+// this will panic.
+func MergeDiffs(a, b FilterDiff) FilterDiff {
+	if len(a.Added.bits) != len(b.Added.bits) || len(a.Removed.bits) != len(b.Removed.bits) || len(a.Added.bits) != len(a.Removed.bits) {
+		panic("FilterDiff QuickFilters must be the same size")
+	}
+	d := FilterDiff{
+		Added:   New(a.Added.sourceLen),
+		Removed: New(a.Added.sourceLen),
+	}
+	for i := range d.Added.bits {
+		d.Added.bits[i] = a.Added.bits[i]&^b.Removed.bits[i] | b.Added.bits[i]&^a.Removed.bits[i]
+		d.Removed.bits[i] = a.Removed.bits[i]&^b.Added.bits[i] | b.Removed.bits[i]&^a.Added.bits[i]
+	}
+	d.Added = d.Added.Recount()
+	d.Removed = d.Removed.Recount()
+	return d
+}
+
+const (
+	// churnBlocks is the number of blocks that CompareSnapshots divides the
+	// indices into for finding the ranges with the most change.
+	churnBlocks = 64
+	// churnHotRanges is the maximum number of ranges in ChurnReport.HotRanges.
+	churnHotRanges = 5
+)
+
+// ChurnReport summarizes the change between two snapshots of a QuickFilter.
+type ChurnReport struct {
+	// Added is the number of values set in the new snapshot but not in the
+	// old.
+	Added int
+	// Removed is the number of values set in the old snapshot but not in the
+	// new.
+	Removed int
+	// Rate is the number of changed values divided by the number of values
+	// set in either snapshot, or zero if neither has values set. It is zero
+	// for identical snapshots and one for disjoint ones.
+	Rate float64
+	// HotRanges contains up to five of the ranges with the most changed
+	// values, in descending order of changes. The indices are divided into
+	// up to 64 word-aligned ranges of equal size for this.
+	HotRanges []ChurnRange
+}
+
+// ChurnRange is a range of indices and the number of changed values in it.
+type ChurnRange struct {
+	Interval
+	Changed int
+}
+
+// CompareSnapshots returns the ChurnReport of the change from the old to the
+// new snapshot of a QuickFilter, computed in a single pass, for monitoring
+// how a selection evolves between runs.
+//
+// The passed QuickFilters must be the same size or this will panic.
+func CompareSnapshots(old, new QuickFilter) ChurnReport {
+	if old.sourceLen != new.sourceLen {
+		panic("passed QuickFilters must be the same size")
+	}
+	var r ChurnReport
+	wordsPerBlock := (len(new.bits) + churnBlocks - 1) / churnBlocks
+	blocks := make([]ChurnRange, 0, churnBlocks)
+	union := 0
+	last := len(new.bits) - 1
+	for i := range new.bits {
+		oldWord, newWord := old.bits[i], new.bits[i]
+		if i == last {
+			mask := lastWordMask(new.sourceLen)
+			oldWord &= mask
+			newWord &= mask
+		}
+		if i%wordsPerBlock == 0 {
+			lo := i * bits.UintSize
+			hi := lo + wordsPerBlock*bits.UintSize
+			if hi > new.sourceLen {
+				hi = new.sourceLen
+			}
+			blocks = append(blocks, ChurnRange{Interval: Interval{Lo: lo, Hi: hi}})
+		}
+		added, removed := bits.OnesCount(newWord&^oldWord), bits.OnesCount(oldWord&^newWord)
+		r.Added += added
+		r.Removed += removed
+		union += bits.OnesCount(oldWord | newWord)
+		blocks[len(blocks)-1].Changed += added + removed
+	}
+	if union > 0 {
+		r.Rate = float64(r.Added+r.Removed) / float64(union)
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Changed > blocks[j].Changed
+	})
+	for _, block := range blocks {
+		if block.Changed == 0 || len(r.HotRanges) == churnHotRanges {
+			break
+		}
+		r.HotRanges = append(r.HotRanges, block)
+	}
+	return r
+}
@@ -0,0 +1,119 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("Added and Removed", func(t *testing.T) {
+		old := quickfilter.New(100).Add(1).Add(2).Add(70)
+		new := quickfilter.New(100).Add(2).Add(70).Add(99)
+		expectedAdded := []int{99}
+		expectedRemoved := []int{1}
+
+		d := quickfilter.Diff(old, new)
+		receivedAdded := collect(d.Added)
+		receivedRemoved := collect(d.Removed)
+
+		if !reflect.DeepEqual(expectedAdded, receivedAdded) {
+			t.Errorf("expected %v, got %v", expectedAdded, receivedAdded)
+		}
+		if !reflect.DeepEqual(expectedRemoved, receivedRemoved) {
+			t.Errorf("expected %v, got %v", expectedRemoved, receivedRemoved)
+		}
+		if d.Added.Len() != 1 || d.Removed.Len() != 1 {
+			t.Errorf("expected counts 1 and 1, got %d and %d", d.Added.Len(), d.Removed.Len())
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		old := quickfilter.NewFilled(10)
+		new := quickfilter.New(10)
+		for i := 0; i < new.Cap(); i++ {
+			new = new.Add(i)
+		}
+
+		d := quickfilter.Diff(old, new)
+
+		if !d.Empty() {
+			t.Error("expected Empty to return true")
+		}
+	})
+}
+
+func TestApplyDiff(t *testing.T) {
+	old := quickfilter.New(100).Add(1).Add(2).Add(70)
+	new := quickfilter.New(100).Add(2).Add(70).Add(99)
+	expected := collect(new)
+
+	qf := old.Copy().ApplyDiff(quickfilter.Diff(old, new))
+	received := collect(qf)
+
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("expected %v, got %v", expected, received)
+	}
+	if new.Len() != qf.Len() {
+		t.Errorf("expected %d, got %d", new.Len(), qf.Len())
+	}
+}
+
+func TestMergeDiffs(t *testing.T) {
+	v1 := quickfilter.New(100).Add(1).Add(2).Add(3)
+	v2 := quickfilter.New(100).Add(2).Add(3).Add(4).Add(5)
+	v3 := quickfilter.New(100).Add(1).Add(2).Add(5).Add(6)
+	expectedAdded := []int{5, 6}
+	expectedRemoved := []int{3}
+
+	d := quickfilter.MergeDiffs(quickfilter.Diff(v1, v2), quickfilter.Diff(v2, v3))
+	receivedAdded := collect(d.Added)
+	receivedRemoved := collect(d.Removed)
+
+	if !reflect.DeepEqual(expectedAdded, receivedAdded) {
+		t.Errorf("expected %v, got %v", expectedAdded, receivedAdded)
+	}
+	if !reflect.DeepEqual(expectedRemoved, receivedRemoved) {
+		t.Errorf("expected %v, got %v", expectedRemoved, receivedRemoved)
+	}
+	if !reflect.DeepEqual(collect(v3), collect(v1.Copy().ApplyDiff(d))) {
+		t.Errorf("expected %v, got %v", collect(v3), collect(v1.Copy().ApplyDiff(d)))
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	t.Run("report", func(t *testing.T) {
+		old := quickfilter.NewRange(6400, 0, 1000)
+		new := quickfilter.NewRange(6400, 500, 1000).AddMany([]int{6000, 6001, 6002, 6003, 6004, 6005, 6006, 6007, 6008, 6009})
+		expected := quickfilter.ChurnReport{
+			Added:   10,
+			Removed: 500,
+			Rate:    510.0 / 1010.0,
+			HotRanges: []quickfilter.ChurnRange{
+				{Interval: quickfilter.Interval{Lo: 0, Hi: 128}, Changed: 128},
+				{Interval: quickfilter.Interval{Lo: 128, Hi: 256}, Changed: 128},
+				{Interval: quickfilter.Interval{Lo: 256, Hi: 384}, Changed: 128},
+				{Interval: quickfilter.Interval{Lo: 384, Hi: 512}, Changed: 116},
+				{Interval: quickfilter.Interval{Lo: 5888, Hi: 6016}, Changed: 10},
+			},
+		}
+
+		received := quickfilter.CompareSnapshots(old, new)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(3)
+		expected := quickfilter.ChurnReport{}
+
+		received := quickfilter.CompareSnapshots(qf, qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
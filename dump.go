@@ -0,0 +1,91 @@
+package quickfilter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GoString implements fmt.GoStringer, so that formatting a QuickFilter with
+// %#v, such as in a failed test assertion, shows the NewFromIndices call
+// that reproduces it, for example:
+//
+//	quickfilter.NewFromIndices(20, []int{0, 2, 4})
+func (qf QuickFilter) GoString() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "quickfilter.NewFromIndices(%d, []int{", qf.sourceLen)
+	sep := ""
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		b.WriteString(sep)
+		b.WriteString(strconv.Itoa(it.Value()))
+		sep = ", "
+	}
+	b.WriteString("})")
+	return b.String()
+}
+
+// DumpOptions configures DebugDump.
+type DumpOptions struct {
+	// BitsPerChar is the number of offsets summarized by each character.
+	// Defaults to 64.
+	BitsPerChar int
+	// CharsPerLine is the number of characters per line. Defaults to 64.
+	CharsPerLine int
+}
+
+var dumpChars = []rune{' ', '░', '▒', '▓', '█'}
+
+// DebugDump writes a density map of the QuickFilter to w for a human to see
+// at a glance where the set values cluster. Each character summarizes a
+// block of offsets: a space means none are set and █ means all are set, with
+// ░, ▒ and ▓ for increasing densities in between. Each line starts with the
+// offset of its first block, for example:
+//
+//	qf.DebugDump(os.Stdout, quickfilter.DumpOptions{BitsPerChar: 8, CharsPerLine: 8})
+//	// Output:
+//	//        0 |██▓     |
+//	//       64 |    ░░░ |
+func (qf QuickFilter) DebugDump(w io.Writer, opts DumpOptions) error {
+	if opts.BitsPerChar <= 0 {
+		opts.BitsPerChar = 64
+	}
+	if opts.CharsPerLine <= 0 {
+		opts.CharsPerLine = 64
+	}
+	bitsPerLine := opts.BitsPerChar * opts.CharsPerLine
+	var line strings.Builder
+	for lineStart := 0; lineStart < qf.sourceLen; lineStart += bitsPerLine {
+		line.Reset()
+		for c := 0; c < opts.CharsPerLine; c++ {
+			lo := lineStart + c*opts.BitsPerChar
+			hi := lo + opts.BitsPerChar
+			if hi > qf.sourceLen {
+				hi = qf.sourceLen
+			}
+			if lo >= hi {
+				line.WriteRune(' ')
+				continue
+			}
+			line.WriteRune(dumpChars[qf.densityLevel(lo, hi)])
+		}
+		if _, err := fmt.Fprintf(w, "%8d |%s|\n", lineStart, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// densityLevel returns the index in dumpChars for the density of the set
+// values between lo (inclusive) and hi (exclusive).
+func (qf QuickFilter) densityLevel(lo, hi int) int {
+	count := qf.countRange(lo, hi)
+	switch {
+	case count == 0:
+		return 0
+	case count == hi-lo:
+		return len(dumpChars) - 1
+	default:
+		return 1 + count*(len(dumpChars)-2)/(hi-lo)
+	}
+}
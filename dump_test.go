@@ -0,0 +1,72 @@
+package quickfilter_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestGoString(t *testing.T) {
+	t.Run("GoString", func(t *testing.T) {
+		qf := quickfilter.NewFromIndices(20, []int{4, 0, 2})
+		expected := "quickfilter.NewFromIndices(20, []int{0, 2, 4})"
+
+		received := fmt.Sprintf("%#v", qf)
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("GoString of empty", func(t *testing.T) {
+		qf := quickfilter.New(5)
+		expected := "quickfilter.NewFromIndices(5, []int{})"
+
+		received := qf.GoString()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+}
+
+func TestDebugDump(t *testing.T) {
+	t.Run("density levels", func(t *testing.T) {
+		qf := quickfilter.New(76)
+		for i := 0; i < 16; i++ {
+			qf = qf.Add(i)
+		}
+		qf = qf.Add(16).Add(24).Add(25).Add(26).Add(27).Add(28).Add(29).Add(30)
+		qf = qf.Add(72)
+		expected := "       0 |██░▓    |\n      64 | ░      |\n"
+		var buf bytes.Buffer
+
+		err := qf.DebugDump(&buf, quickfilter.DumpOptions{BitsPerChar: 8, CharsPerLine: 8})
+		received := buf.String()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		qf := quickfilter.NewFilled(64 * 64 * 2)
+		expectedLines := 2
+		var buf bytes.Buffer
+
+		err := qf.DebugDump(&buf, quickfilter.DumpOptions{})
+		receivedLines := bytes.Count(buf.Bytes(), []byte("\n"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expectedLines != receivedLines {
+			t.Errorf("expected %d, got %d", expectedLines, receivedLines)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package quickfilter
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// AppendEliasFano appends the Elias-Fano encoding of the set indices to dst
+// and returns the extended slice. The encoding takes about
+// 2 + log2(Cap()/Len()) bits per set index, which is close to the minimum
+// possible for sparse QuickFilters, at the cost of slower encoding and
+// decoding than MarshalBinary.
+//
+// The encoding is the source length and the number of set indices as
+// unsigned varints, followed by the low bits of each index packed in
+// little-endian bit order, followed by the high bits of the indices in
+// unary, also packed in little-endian bit order.
+func (qf QuickFilter) AppendEliasFano(dst []byte) []byte {
+	count := qf.countRange(0, qf.sourceLen)
+	dst = appendUvarint(dst, uint64(qf.sourceLen))
+	dst = appendUvarint(dst, uint64(count))
+	lowBits := log2MeanGap(qf.sourceLen, count)
+	lowMask := uint64(1)<<lowBits - 1
+	w := bitWriter{buf: dst}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		w.write(uint64(it.Value())&lowMask, lowBits)
+	}
+	w.flush()
+	w = bitWriter{buf: w.buf}
+	prevHigh := uint64(0)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		high := uint64(it.Value()) >> lowBits
+		w.writeUnary(high - prevHigh)
+		prevHigh = high
+	}
+	w.flush()
+	return w.buf
+}
+
+// UnmarshalEliasFano decodes data produced by AppendEliasFano into the
+// QuickFilter, setting the bits as the indices are decoded without
+// materializing them. The data is validated before the QuickFilter is
+// modified, so on error the QuickFilter is left unchanged.
+//
+// The existing backing buffer of the QuickFilter is reused if it is large
+// enough.
+func (qf *QuickFilter) UnmarshalEliasFano(data []byte) error {
+	sourceLen, count, err := decodeEliasFano(data, nil)
+	if err != nil {
+		return err
+	}
+	result := qf.Resize(sourceLen).Clear()
+	decodeEliasFano(data, func(index int) {
+		wordIndex, mask := offsets(index)
+		result.bits[wordIndex] |= mask
+	})
+	result.len = count
+	*qf = result
+	return nil
+}
+
+// decodeEliasFano decodes data produced by AppendEliasFano, calling set, if
+// not nil, with each index, and returns the source length and the number of
+// indices.
+func decodeEliasFano(data []byte, set func(index int)) (int, int, error) {
+	sourceLen, n := binary.Uvarint(data)
+	if n <= 0 || !plausibleSourceLen(sourceLen, len(data)) {
+		return 0, 0, ErrInvalidEncoding
+	}
+	data = data[n:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > sourceLen || count > uint64(len(data))*8 {
+		return 0, 0, ErrInvalidEncoding
+	}
+	data = data[n:]
+	lowBits := log2MeanGap(int(sourceLen), int(count))
+	lowLen := (count*uint64(lowBits) + 7) / 8
+	if uint64(len(data)) < lowLen {
+		return 0, 0, ErrInvalidEncoding
+	}
+	low, high := bitReader{data: data[:lowLen]}, bitReader{data: data[lowLen:]}
+	value, prev := uint64(0), -1
+	for i := uint64(0); i < count; i++ {
+		gap, ok := high.readUnary()
+		lowValue, lowOK := low.read(lowBits)
+		if !ok || !lowOK {
+			return 0, 0, ErrInvalidEncoding
+		}
+		value += gap << lowBits
+		index := value | lowValue
+		if index >= sourceLen || int(index) <= prev {
+			return 0, 0, ErrInvalidEncoding
+		}
+		prev = int(index)
+		if set != nil {
+			set(prev)
+		}
+	}
+	return int(sourceLen), int(count), nil
+}
+
+// log2MeanGap returns the base 2 logarithm of the mean gap between count
+// indices spread over sourceLen, rounded down, which is the number of low
+// bits stored per index in Elias-Fano coding and the parameter of Rice
+// coding.
+func log2MeanGap(sourceLen, count int) uint {
+	if count == 0 || sourceLen <= count {
+		return 0
+	}
+	return uint(bits.Len(uint(sourceLen/count))) - 1
+}
+
+// bitWriter appends bits to a byte slice in little-endian bit order.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) write(v uint64, n uint) {
+	for n > 0 {
+		take := 64 - w.nbits
+		if take > n {
+			take = n
+		}
+		w.acc |= (v & (1<<take - 1)) << w.nbits
+		w.nbits += take
+		v >>= take
+		n -= take
+		for w.nbits >= 8 {
+			w.buf = append(w.buf, byte(w.acc))
+			w.acc >>= 8
+			w.nbits -= 8
+		}
+	}
+}
+
+// writeUnary writes n zero bits followed by a one bit.
+func (w *bitWriter) writeUnary(n uint64) {
+	for ; n >= 32; n -= 32 {
+		w.write(0, 32)
+	}
+	w.write(1<<n, uint(n)+1)
+}
+
+func (w *bitWriter) flush() {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+}
+
+// bitReader reads bits from a byte slice in little-endian bit order.
+type bitReader struct {
+	data []byte
+	pos  uint64
+}
+
+// read reads n bits, or returns false if the data ends before them.
+func (r *bitReader) read(n uint) (uint64, bool) {
+	if r.pos+uint64(n) > uint64(len(r.data))*8 {
+		return 0, false
+	}
+	var v uint64
+	for i := uint(0); i < n; {
+		offset := uint(r.pos % 8)
+		take := 8 - offset
+		if take > n-i {
+			take = n - i
+		}
+		v |= uint64(r.data[r.pos/8]>>offset&(1<<take-1)) << i
+		r.pos += uint64(take)
+		i += take
+	}
+	return v, true
+}
+
+// readUnary reads zero bits up to and including a one bit and returns the
+// number of zero bits, or false if the data ends before a one bit.
+func (r *bitReader) readUnary() (uint64, bool) {
+	start := r.pos
+	for byteIndex := r.pos / 8; byteIndex < uint64(len(r.data)); byteIndex++ {
+		b := r.data[byteIndex] >> (r.pos % 8)
+		if b != 0 {
+			r.pos += uint64(bits.TrailingZeros8(b)) + 1
+			return r.pos - 1 - start, true
+		}
+		r.pos += 8 - r.pos%8
+	}
+	return 0, false
+}
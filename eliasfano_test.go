@@ -0,0 +1,97 @@
+package quickfilter_test
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestEliasFano(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name string
+		qf   quickfilter.QuickFilter
+	}{
+		{"empty", quickfilter.New(0)},
+		{"no set indices", quickfilter.New(1000)},
+		{"sparse", quickfilter.NewRandom(100000, 0.001, rng)},
+		{"dense", quickfilter.NewRandom(1000, 0.9, rng)},
+		{"full", quickfilter.NewFilled(130)},
+		{"edges", quickfilter.New(1 << 20).Add(0).Add(1<<20 - 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := collect(tt.qf)
+
+			data := tt.qf.AppendEliasFano(nil)
+			qf := quickfilter.NewFilled(3)
+			if err := qf.UnmarshalEliasFano(data); err != nil {
+				t.Fatal(err)
+			}
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || tt.qf.Cap() != qf.Cap() || qf.Validate() != nil {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+	}
+
+	t.Run("should be compact for sparse filters", func(t *testing.T) {
+		qf := quickfilter.NewRandom(1000000, 0.001, rng)
+		binary, _ := qf.MarshalBinary()
+
+		data := qf.AppendEliasFano(nil)
+
+		if len(data)*50 > len(binary) {
+			t.Errorf("expected at most %d bytes, got %d", len(binary)/50, len(data))
+		}
+	})
+
+	t.Run("should ignore bits beyond Cap", func(t *testing.T) {
+		qf := quickfilter.New(3).Add(1)
+		qf.Resize(10).Add(7)
+		expected := []int{1}
+
+		data := qf.AppendEliasFano(nil)
+		var decoded quickfilter.QuickFilter
+		if err := decoded.UnmarshalEliasFano(data); err != nil {
+			t.Fatal(err)
+		}
+		received := collect(decoded)
+
+		if !reflect.DeepEqual(expected, received) || decoded.Cap() != 3 {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := quickfilter.New(100).Add(3).Add(50).AppendEliasFano(nil)
+		qf := quickfilter.New(10).Add(1).Add(2)
+		expected := []int{1, 2}
+
+		err := qf.UnmarshalEliasFano(data[:len(data)-1])
+		received := collect(qf)
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("implausible source length", func(t *testing.T) {
+		data := make([]byte, binary.MaxVarintLen64+1)
+		data = data[:binary.PutUvarint(data, 1<<40)+1]
+		var qf quickfilter.QuickFilter
+
+		err := qf.UnmarshalEliasFano(data)
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
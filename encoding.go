@@ -0,0 +1,236 @@
+package quickfilter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidEncoding is returned when decoding data that is not a valid
+// encoding of a QuickFilter.
+var ErrInvalidEncoding = errors.New("quickfilter: invalid encoding")
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The encoding is the source length as an unsigned varint followed by the
+// bits packed in little-endian byte order, making it independent of the
+// platform word size.
+func (qf QuickFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, binary.MaxVarintLen64+encodedBitsLen(qf.sourceLen))
+	data = appendUvarint(data, uint64(qf.sourceLen))
+	return appendBits(data, qf), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// The existing backing buffer of the QuickFilter is reused if it is large
+// enough.
+func (qf *QuickFilter) UnmarshalBinary(data []byte) error {
+	sourceLen, n := binary.Uvarint(data)
+	if n <= 0 || sourceLen > uint64(maxInt) {
+		return ErrInvalidEncoding
+	}
+	data = data[n:]
+	if len(data) != encodedBitsLen(int(sourceLen)) {
+		return ErrInvalidEncoding
+	}
+	*qf = readBits(qf.Resize(int(sourceLen)), data)
+	return nil
+}
+
+// FromBytes returns a new QuickFilter decoded from data produced by
+// MarshalBinary.
+func FromBytes(data []byte) (QuickFilter, error) {
+	var qf QuickFilter
+	if err := qf.UnmarshalBinary(data); err != nil {
+		return QuickFilter{}, err
+	}
+	return qf, nil
+}
+
+// MustFromBytes is like FromBytes but panics if the data can't be decoded.
+// It simplifies initialization of QuickFilters in tests and global
+// variables.
+func MustFromBytes(data []byte) QuickFilter {
+	qf, err := FromBytes(data)
+	if err != nil {
+		panic(err)
+	}
+	return qf
+}
+
+// Format is a serialization format of a QuickFilter, for selecting the
+// trade-off between size and speed with AppendFormat.
+type Format byte
+
+const (
+	// FormatWords is the encoding of MarshalBinary, which is the fastest to
+	// encode and decode and the most compact for dense QuickFilters.
+	FormatWords Format = iota + 1
+	// FormatRice is the encoding of AppendRice, which is a middle ground in
+	// size and speed.
+	FormatRice
+	// FormatEliasFano is the encoding of AppendEliasFano, which is the most
+	// compact for sparse QuickFilters.
+	FormatEliasFano
+)
+
+// AppendFormat appends the encoding of the QuickFilter in the given format to
+// dst, prefixed with the format as a byte, and returns the extended slice.
+// The result can be decoded with UnmarshalFormat without knowing the format.
+//
+// format must be one of the defined formats or this will panic.
+func (qf QuickFilter) AppendFormat(dst []byte, format Format) []byte {
+	dst = append(dst, byte(format))
+	switch format {
+	case FormatWords:
+		dst = appendUvarint(dst, uint64(qf.sourceLen))
+		return appendBits(dst, qf)
+	case FormatRice:
+		return qf.AppendRice(dst)
+	case FormatEliasFano:
+		return qf.AppendEliasFano(dst)
+	default:
+		panic("unknown format")
+	}
+}
+
+// UnmarshalFormat decodes data produced by AppendFormat in any format into
+// the QuickFilter.
+//
+// The existing backing buffer of the QuickFilter is reused if it is large
+// enough.
+func (qf *QuickFilter) UnmarshalFormat(data []byte) error {
+	if len(data) == 0 {
+		return ErrInvalidEncoding
+	}
+	switch Format(data[0]) {
+	case FormatWords:
+		return qf.UnmarshalBinary(data[1:])
+	case FormatRice:
+		return qf.UnmarshalRice(data[1:])
+	case FormatEliasFano:
+		return qf.UnmarshalEliasFano(data[1:])
+	default:
+		return ErrInvalidEncoding
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The encoding is an object with the source length as "cap" and the set
+// indices in ascending order as "indices", for example
+// {"cap":10,"indices":[1,4,9]}.
+func (qf QuickFilter) MarshalJSON() ([]byte, error) {
+	v := jsonQuickFilter{Cap: qf.sourceLen, Indices: make([]int, 0, qf.len)}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		v.Indices = append(v.Indices, it.Value())
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The existing backing buffer of the QuickFilter is reused if it is large
+// enough.
+func (qf *QuickFilter) UnmarshalJSON(data []byte) error {
+	var v jsonQuickFilter
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Cap < 0 {
+		return ErrInvalidEncoding
+	}
+	for _, index := range v.Indices {
+		if index < 0 || index >= v.Cap {
+			return ErrInvalidEncoding
+		}
+	}
+	result := qf.Resize(v.Cap).Clear()
+	for _, index := range v.Indices {
+		if !result.Has(index) {
+			result = result.Add(index)
+		}
+	}
+	*qf = result
+	return nil
+}
+
+// FromJSON returns a new QuickFilter decoded from data produced by
+// MarshalJSON.
+func FromJSON(data []byte) (QuickFilter, error) {
+	var qf QuickFilter
+	if err := qf.UnmarshalJSON(data); err != nil {
+		return QuickFilter{}, err
+	}
+	return qf, nil
+}
+
+// MustFromJSON is like FromJSON but panics if the data can't be decoded. It
+// simplifies initialization of QuickFilters in tests and global variables.
+func MustFromJSON(data []byte) QuickFilter {
+	qf, err := FromJSON(data)
+	if err != nil {
+		panic(err)
+	}
+	return qf
+}
+
+type jsonQuickFilter struct {
+	Cap     int   `json:"cap"`
+	Indices []int `json:"indices"`
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// The compact encodings can describe a huge source length in a few bytes, so
+// their decoders only accept a source length of more than maxFreeSourceLen
+// if data has at least one byte for each sourceLenPerByte offsets. This
+// keeps untrusted input from making the decoder allocate an arbitrarily
+// large backing buffer.
+const (
+	maxFreeSourceLen = 1 << 28
+	sourceLenPerByte = 1 << 16
+)
+
+// plausibleSourceLen reports whether sourceLen is within maxInt and within
+// the allocation bound of the compact decoders for data of dataLen bytes.
+func plausibleSourceLen(sourceLen uint64, dataLen int) bool {
+	if sourceLen > uint64(maxInt) {
+		return false
+	}
+	return sourceLen <= maxFreeSourceLen || sourceLen/sourceLenPerByte <= uint64(dataLen)
+}
+
+func encodedBitsLen(sourceLen int) int {
+	return (sourceLen + 7) / 8
+}
+
+func appendUvarint(data []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(data, buf[:n]...)
+}
+
+func appendBits(data []byte, qf QuickFilter) []byte {
+	const bytesPerWord = bits.UintSize / 8
+	n := encodedBitsLen(qf.sourceLen)
+	for i := 0; i < n; i++ {
+		data = append(data, byte(qf.bits[i/bytesPerWord]>>(uint(i%bytesPerWord)*8)))
+	}
+	if n > 0 && qf.sourceLen%8 != 0 {
+		data[len(data)-1] &= byte(1)<<uint(qf.sourceLen%8) - 1
+	}
+	return data
+}
+
+func readBits(qf QuickFilter, data []byte) QuickFilter {
+	const bytesPerWord = bits.UintSize / 8
+	qf = qf.Clear()
+	for i, b := range data {
+		qf.bits[i/bytesPerWord] |= uint(b) << (uint(i%bytesPerWord) * 8)
+	}
+	qf.bits[len(qf.bits)-1] &= lastWordMask(qf.sourceLen)
+	return qf.Recount()
+}
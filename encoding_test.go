@@ -0,0 +1,189 @@
+package quickfilter_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestBinaryEncoding(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		qf := quickfilter.New(131).Add(0).Add(64).Add(130)
+		expected := collect(qf)
+
+		data, err := qf.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var qf2 quickfilter.QuickFilter
+		if err := qf2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		received := collect(qf2)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if qf.Cap() != qf2.Cap() {
+			t.Errorf("expected %d, got %d", qf.Cap(), qf2.Cap())
+		}
+		if qf.Len() != qf2.Len() {
+			t.Errorf("expected %d, got %d", qf.Len(), qf2.Len())
+		}
+	})
+
+	t.Run("filled", func(t *testing.T) {
+		qf := quickfilter.NewFilled(13)
+
+		data, err := qf.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var qf2 quickfilter.QuickFilter
+		if err := qf2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if qf.Len() != qf2.Len() {
+			t.Errorf("expected %d, got %d", qf.Len(), qf2.Len())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var qf quickfilter.QuickFilter
+
+		err := qf.UnmarshalBinary([]byte{16, 0})
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
+
+func TestFromBytes(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		data, err := quickfilter.New(131).Add(0).Add(130).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []int{0, 130}
+
+		qf, err := quickfilter.FromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Cap() != 131 {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := quickfilter.FromBytes([]byte{16, 0})
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+
+	t.Run("MustFromBytes invalid should panic", func(t *testing.T) {
+		defer func() {
+			if err := recover(); err != quickfilter.ErrInvalidEncoding {
+				t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+			}
+		}()
+
+		quickfilter.MustFromBytes([]byte{16, 0})
+	})
+}
+
+func TestFormat(t *testing.T) {
+	qf := quickfilter.NewRandom(1000, 0.05, rand.New(rand.NewSource(1)))
+	expected := collect(qf)
+	formats := []quickfilter.Format{quickfilter.FormatWords, quickfilter.FormatRice, quickfilter.FormatEliasFano}
+	for _, format := range formats {
+		t.Run(fmt.Sprint(format), func(t *testing.T) {
+			data := qf.AppendFormat(nil, format)
+			var qf2 quickfilter.QuickFilter
+			if err := qf2.UnmarshalFormat(data); err != nil {
+				t.Fatal(err)
+			}
+			received := collect(qf2)
+
+			if !reflect.DeepEqual(expected, received) || qf.Cap() != qf2.Cap() {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		var qf2 quickfilter.QuickFilter
+
+		err := qf2.UnmarshalFormat([]byte{0, 1, 0})
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
+
+func TestJSONEncoding(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		qf := quickfilter.New(131).Add(0).Add(64).Add(130)
+		expectedJSON := `{"cap":131,"indices":[0,64,130]}`
+
+		data, err := json.Marshal(qf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var qf2 quickfilter.QuickFilter
+		if err := json.Unmarshal(data, &qf2); err != nil {
+			t.Fatal(err)
+		}
+
+		if expectedJSON != string(data) {
+			t.Errorf("expected %s, got %s", expectedJSON, data)
+		}
+		if !reflect.DeepEqual(collect(qf), collect(qf2)) {
+			t.Errorf("expected %v, got %v", collect(qf), collect(qf2))
+		}
+		if qf.Len() != qf2.Len() {
+			t.Errorf("expected %d, got %d", qf.Len(), qf2.Len())
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		var qf quickfilter.QuickFilter
+
+		err := json.Unmarshal([]byte(`{"cap":10,"indices":[10]}`), &qf)
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		expected := []int{1, 4, 9}
+
+		received := collect(quickfilter.MustFromJSON([]byte(`{"cap":10,"indices":[9,1,4]}`)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := quickfilter.FromJSON([]byte(`{"cap":10,"indices":[10]}`))
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// ExpiringFilter is a filter where every added index carries an expiry
+// bucket, such as a unix timestamp truncated to the minute, and Expire
+// periodically clears the lapsed indices in bulk. This suits uses like
+// "recently alerted" suppression sets without rebuilding them on a timer.
+//
+// The indices of each bucket are stored in a QuickFilter of their own, and
+// the union of the live buckets is maintained so that the ExpiringFilter can
+// be queried like a regular QuickFilter.
+type ExpiringFilter struct {
+	buckets map[int64]QuickFilter
+	free    []QuickFilter
+	union   QuickFilter
+}
+
+// NewExpiringFilter returns a new empty ExpiringFilter with enough space
+// reserved to store sourceLen offsets.
+func NewExpiringFilter(sourceLen int) ExpiringFilter {
+	return ExpiringFilter{
+		buckets: make(map[int64]QuickFilter),
+		union:   New(sourceLen),
+	}
+}
+
+// Add an index that expires once Expire is called with a time of expiry or
+// later. An index added more than once expires at the latest of its
+// expiries.
+//
+// The original ExpiringFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the ExpiringFilter from escaping
+// to the heap.
+func (ef ExpiringFilter) Add(index int, expiry int64) ExpiringFilter {
+	bucket, ok := ef.buckets[expiry]
+	if !ok {
+		if n := len(ef.free); n > 0 {
+			bucket, ef.free = ef.free[n-1], ef.free[:n-1]
+		} else {
+			bucket = New(ef.union.sourceLen)
+		}
+	}
+	if !bucket.Has(index) {
+		bucket = bucket.Add(index)
+	}
+	ef.buckets[expiry] = bucket
+	if !ef.union.Has(index) {
+		ef.union = ef.union.Add(index)
+	}
+	return ef
+}
+
+// Expire clears the indices whose expiry is at or before now, unless they
+// have also been added with a later expiry. Only the words where the lapsed
+// buckets had values set are recomputed.
+//
+// The original ExpiringFilter is no longer usable and must be replaced with
+// the returned one. This approach prevents the ExpiringFilter from escaping
+// to the heap.
+func (ef ExpiringFilter) Expire(now int64) ExpiringFilter {
+	var lapsed []QuickFilter
+	for expiry, bucket := range ef.buckets {
+		if expiry <= now {
+			lapsed = append(lapsed, bucket)
+			delete(ef.buckets, expiry)
+		}
+	}
+	if len(lapsed) == 0 {
+		return ef
+	}
+	for i := range ef.union.bits {
+		expired := uint(0)
+		for _, bucket := range lapsed {
+			expired |= bucket.bits[i]
+		}
+		if expired == 0 {
+			continue
+		}
+		word := ef.union.bits[i] &^ expired
+		for _, bucket := range ef.buckets {
+			word |= bucket.bits[i] & expired
+		}
+		ef.union.len += bits.OnesCount(word) - bits.OnesCount(ef.union.bits[i])
+		ef.union.bits[i] = word
+	}
+	for _, bucket := range lapsed {
+		ef.free = append(ef.free, bucket.Clear())
+	}
+	return ef
+}
+
+// Has returns a boolean indicating whether the index is set and has not
+// expired.
+func (ef ExpiringFilter) Has(index int) bool {
+	return ef.union.Has(index)
+}
+
+// Len returns the number of offsets set that have not expired.
+func (ef ExpiringFilter) Len() int {
+	return ef.union.Len()
+}
+
+// Cap returns the maximum number of values that can be stored.
+func (ef ExpiringFilter) Cap() int {
+	return ef.union.Cap()
+}
+
+// Filter returns the indices that have not expired as a QuickFilter. The
+// returned QuickFilter is owned by the ExpiringFilter and must not be
+// modified; it is only valid until the next Add or Expire.
+func (ef ExpiringFilter) Filter() QuickFilter {
+	return ef.union
+}
@@ -0,0 +1,56 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestExpiringFilter(t *testing.T) {
+	t.Run("Expire", func(t *testing.T) {
+		ef := quickfilter.NewExpiringFilter(100)
+		expected := []int{3, 70}
+
+		ef = ef.Add(1, 10).Add(2, 10).Add(3, 20).Add(70, 30).Add(2, 5)
+		ef = ef.Expire(10)
+		received := collect(ef.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if len(expected) != ef.Len() {
+			t.Errorf("expected %d, got %d", len(expected), ef.Len())
+		}
+	})
+
+	t.Run("should keep the latest expiry", func(t *testing.T) {
+		ef := quickfilter.NewExpiringFilter(100)
+
+		ef = ef.Add(1, 10).Add(1, 20)
+		ef = ef.Expire(15)
+
+		if !ef.Has(1) {
+			t.Error("expected Has to return true")
+		}
+		ef = ef.Expire(20)
+		if ef.Has(1) {
+			t.Error("expected Has to return false")
+		}
+	})
+
+	t.Run("should reuse expired buckets", func(t *testing.T) {
+		ef := quickfilter.NewExpiringFilter(100)
+		expected := []int{5}
+
+		ef = ef.Add(1, 10).Expire(10).Add(5, 20)
+		received := collect(ef.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if ef.Expire(20).Len() != 0 {
+			t.Errorf("expected %d, got %d", 0, ef.Len())
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// Expr is a boolean expression tree over QuickFilters. An Expr is evaluated
+// in a single pass over the words of its operands, without allocating
+// intermediate QuickFilters for the sub-expressions.
+type Expr struct {
+	op       exprOp
+	leaf     QuickFilter
+	operands []Expr
+}
+
+type exprOp int
+
+const (
+	exprLeaf exprOp = iota
+	exprAnd
+	exprOr
+	exprNot
+)
+
+// Leaf returns an Expr that evaluates to the values set in qf.
+func Leaf(qf QuickFilter) Expr {
+	return Expr{op: exprLeaf, leaf: qf}
+}
+
+// And returns an Expr that evaluates to the values set in all of the
+// operands. And with no operands evaluates to all values.
+func And(operands ...Expr) Expr {
+	return Expr{op: exprAnd, operands: operands}
+}
+
+// Or returns an Expr that evaluates to the values set in any of the operands.
+// Or with no operands evaluates to no values.
+func Or(operands ...Expr) Expr {
+	return Expr{op: exprOr, operands: operands}
+}
+
+// Not returns an Expr that evaluates to the values not set in the operand.
+func Not(operand Expr) Expr {
+	return Expr{op: exprNot, operands: []Expr{operand}}
+}
+
+// And returns an Expr that evaluates to the values set in e and all of the
+// operands.
+func (e Expr) And(operands ...Expr) Expr {
+	return And(append([]Expr{e}, operands...)...)
+}
+
+// Or returns an Expr that evaluates to the values set in e or any of the
+// operands.
+func (e Expr) Or(operands ...Expr) Expr {
+	return Or(append([]Expr{e}, operands...)...)
+}
+
+// Eval fills dst with the result of the expression. dst is resized to the
+// Cap() of the QuickFilters in the expression; if there are none, the Cap()
+// of dst is kept.
+//
+// The QuickFilters in the expression must all be the same size or this will
+// panic.
+func (e Expr) Eval(dst QuickFilter) QuickFilter {
+	if instrumentation != nil {
+		defer observeSetOp("Expr.Eval", time.Now())
+	}
+	sourceLen := e.sourceLen(-1)
+	if sourceLen < 0 {
+		sourceLen = dst.sourceLen
+	}
+	dst = dst.Resize(sourceLen)
+	for i := range dst.bits {
+		dst.bits[i] = e.word(i)
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(sourceLen)
+	return dst.Recount()
+}
+
+// Plan returns an equivalent Expr with the operands reordered by their
+// estimated number of set values, so that Eval can skip words as early as
+// possible: the operands of And are ordered from the fewest set values to the
+// most, and the operands of Or from the most to the fewest. The estimates are
+// those of EstimateLen, so the overlap of correlated operands is taken into
+// account, and are computed from a single sample of the words shared by the
+// whole expression.
+//
+// Planning allocates, so an Expr that is evaluated repeatedly should be
+// planned once and the result reused.
+func (e Expr) Plan() Expr {
+	sourceLen := e.sourceLen(-1)
+	if sourceLen <= 0 {
+		return e
+	}
+	return e.plan(sourceLen, sampleWords(sourceLen))
+}
+
+type plannedExpr struct {
+	expr     Expr
+	estimate float64
+}
+
+func (e Expr) plan(sourceLen int, sample []int) Expr {
+	switch e.op {
+	case exprLeaf:
+		return e
+	case exprNot:
+		return Not(e.operands[0].plan(sourceLen, sample))
+	}
+	planned := make([]plannedExpr, len(e.operands))
+	for i, operand := range e.operands {
+		planned[i].expr = operand.plan(sourceLen, sample)
+		planned[i].estimate, _ = planned[i].expr.estimate(sourceLen, sample)
+	}
+	sort.SliceStable(planned, func(i, j int) bool {
+		if e.op == exprAnd {
+			return planned[i].estimate < planned[j].estimate
+		}
+		return planned[i].estimate > planned[j].estimate
+	})
+	operands := make([]Expr, len(planned))
+	for i, p := range planned {
+		operands[i] = p.expr
+	}
+	return Expr{op: e.op, operands: operands}
+}
+
+func (e Expr) sourceLen(sourceLen int) int {
+	if e.op == exprLeaf {
+		if sourceLen >= 0 && sourceLen != e.leaf.sourceLen {
+			panic("QuickFilters in an Expr must be the same size")
+		}
+		return e.leaf.sourceLen
+	}
+	for _, operand := range e.operands {
+		sourceLen = operand.sourceLen(sourceLen)
+	}
+	return sourceLen
+}
+
+func (e Expr) word(i int) uint {
+	switch e.op {
+	case exprLeaf:
+		return e.leaf.bits[i]
+	case exprAnd:
+		word := ^uint(0)
+		for _, operand := range e.operands {
+			if word == 0 {
+				break
+			}
+			word &= operand.word(i)
+		}
+		return word
+	case exprOr:
+		word := uint(0)
+		for _, operand := range e.operands {
+			if word == ^uint(0) {
+				break
+			}
+			word |= operand.word(i)
+		}
+		return word
+	default:
+		return ^e.operands[0].word(i)
+	}
+}
+
+// exprSampleWords is the maximum number of words that EstimateLen reads
+// from each QuickFilter in the expression.
+const exprSampleWords = 64
+
+// EstimateLen estimates the number of values set in the result of the
+// expression without evaluating it, for example for pre-allocating buffers
+// for the result.
+//
+// The estimate combines the Len() of the QuickFilters with their overlap in
+// a sample of up to 64 words spread evenly over them: the result of And is
+// estimated as the Len() of its first operand scaled by the fraction of the
+// set values of that operand that satisfy the whole And in the sample, and
+// the result of Or as the sum of the Len() of its operands scaled by the
+// fraction of that sum that remains after removing the overlap in the
+// sample. Where the sample has no set values to measure, the operands are
+// assumed to be independent. The estimate is exact for a single QuickFilter
+// and for QuickFilters of up to 64 words. An expression without
+// QuickFilters is estimated to have no set values.
+//
+// The QuickFilters in the expression must all be the same size or this will
+// panic.
+func (e Expr) EstimateLen() int {
+	sourceLen := e.sourceLen(-1)
+	if sourceLen <= 0 {
+		return 0
+	}
+	estimate, _ := e.estimate(sourceLen, sampleWords(sourceLen))
+	return int(estimate + 0.5)
+}
+
+// sampleWords returns the indices of up to exprSampleWords words spread
+// evenly over a QuickFilter of sourceLen offsets.
+func sampleWords(sourceLen int) []int {
+	lastIndex, _ := offsets(sourceLen - 1)
+	words := lastIndex + 1
+	stride := 1
+	if words > exprSampleWords {
+		stride = (words + exprSampleWords - 1) / exprSampleWords
+	}
+	sample := make([]int, 0, exprSampleWords)
+	for i := 0; i < words; i += stride {
+		sample = append(sample, i)
+	}
+	return sample
+}
+
+// estimate returns the estimated number of set values in the result of the
+// expression and the number of them in the sampled words.
+func (e Expr) estimate(sourceLen int, sample []int) (float64, int) {
+	switch e.op {
+	case exprLeaf:
+		return float64(e.leaf.len), e.sampleCount(sourceLen, sample)
+	case exprNot:
+		estimate, _ := e.operands[0].estimate(sourceLen, sample)
+		return float64(sourceLen) - estimate, e.sampleCount(sourceLen, sample)
+	}
+	n := float64(sourceLen)
+	sampled := e.sampleCount(sourceLen, sample)
+	if e.op == exprAnd {
+		if len(e.operands) == 0 {
+			return n, sampled
+		}
+		independent := n
+		first, firstSampled := 0.0, 0
+		for i, operand := range e.operands {
+			estimate, operandSampled := operand.estimate(sourceLen, sample)
+			if i == 0 {
+				first, firstSampled = estimate, operandSampled
+			}
+			independent *= estimate / n
+		}
+		if firstSampled == 0 {
+			return independent, sampled
+		}
+		return first * float64(sampled) / float64(firstSampled), sampled
+	}
+	sum, sumSampled, independentMiss := 0.0, 0, 1.0
+	for _, operand := range e.operands {
+		estimate, operandSampled := operand.estimate(sourceLen, sample)
+		sum += estimate
+		sumSampled += operandSampled
+		independentMiss *= 1 - estimate/n
+	}
+	if sumSampled == 0 {
+		return n * (1 - independentMiss), sampled
+	}
+	return sum * float64(sampled) / float64(sumSampled), sampled
+}
+
+// sampleCount returns the number of values set in the result of the
+// expression in the sampled words.
+func (e Expr) sampleCount(sourceLen int, sample []int) int {
+	count := 0
+	last := (sourceLen - 1) / bits.UintSize
+	for _, i := range sample {
+		word := e.word(i)
+		if i == last {
+			word &= lastWordMask(sourceLen)
+		}
+		count += bits.OnesCount(word)
+	}
+	return count
+}
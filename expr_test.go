@@ -0,0 +1,177 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestExpr(t *testing.T) {
+	const sourceLen = 70
+	multiplesOf := func(n int) quickfilter.QuickFilter {
+		qf := quickfilter.New(sourceLen)
+		for i := 0; i < sourceLen; i += n {
+			qf = qf.Add(i)
+		}
+		return qf
+	}
+	two := quickfilter.Leaf(multiplesOf(2))
+	three := quickfilter.Leaf(multiplesOf(3))
+	five := quickfilter.Leaf(multiplesOf(5))
+
+	t.Run("And", func(t *testing.T) {
+		expected := []int{0, 30, 60}
+
+		received := collect(quickfilter.And(two, three, five).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Or", func(t *testing.T) {
+		expectedLen := 0
+		for i := 0; i < sourceLen; i++ {
+			if i%3 == 0 || i%5 == 0 {
+				expectedLen++
+			}
+		}
+
+		receivedLen := quickfilter.Or(three, five).Eval(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("Not", func(t *testing.T) {
+		expectedLen := sourceLen / 2
+
+		qf := quickfilter.Not(two).Eval(quickfilter.New(0))
+		receivedLen := qf.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+		if qf.Has(68) || !qf.Has(69) {
+			t.Error("expected only odd values to be set")
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		expected := []int{6, 12, 18, 24, 36, 42, 48, 54, 66}
+
+		received := collect(quickfilter.And(two, quickfilter.Or(three, quickfilter.Leaf(quickfilter.New(sourceLen))), quickfilter.Not(five)).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("empty And", func(t *testing.T) {
+		expectedLen := 10
+
+		receivedLen := quickfilter.And().Eval(quickfilter.New(expectedLen)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		empty := quickfilter.Leaf(quickfilter.New(sourceLen))
+		exprs := []quickfilter.Expr{
+			quickfilter.And(two, three, five),
+			quickfilter.Or(five, three, two),
+			quickfilter.And(two, quickfilter.Not(three), quickfilter.Or(five, empty)),
+			quickfilter.And(two, empty, five),
+			quickfilter.And(quickfilter.Or(two, three), quickfilter.Not(two), quickfilter.Or(five, two)),
+			quickfilter.And(),
+		}
+		for _, expr := range exprs {
+			expected := collect(expr.Eval(quickfilter.New(sourceLen)))
+
+			received := collect(expr.Plan().Eval(quickfilter.New(sourceLen)))
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.And(two, quickfilter.Leaf(quickfilter.New(sourceLen+1))).Eval(quickfilter.New(0))
+	})
+}
+
+func ExampleExpr() {
+	data := make([]int, 0, 16)
+	for len(data) < cap(data) {
+		data = append(data, len(data))
+	}
+	even := quickfilter.New(len(data))
+	multipleOf3 := quickfilter.New(len(data))
+	for i := range data {
+		if data[i]%2 == 0 {
+			even = even.Add(i)
+		}
+		if data[i]%3 == 0 {
+			multipleOf3 = multipleOf3.Add(i)
+		}
+	}
+	expr := quickfilter.And(quickfilter.Leaf(even), quickfilter.Not(quickfilter.Leaf(multipleOf3)))
+	qf := expr.Eval(quickfilter.New(len(data)))
+	newData := make([]int, 0, qf.Len())
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		newData = append(newData, data[it.Value()])
+	}
+	// Output: [2 4 8 10 14]
+	fmt.Println(newData)
+}
+
+func TestExprEstimateLen(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	small := quickfilter.NewRandom(1000, 0.3, rng)
+	small2 := quickfilter.NewRandom(1000, 0.6, rng)
+	large := quickfilter.NewRandom(1000000, 0.3, rng)
+	large2 := quickfilter.NewRandom(1000000, 0.6, rng)
+	clustered := quickfilter.NewRange(1000000, 0, 300000)
+	clustered2 := quickfilter.NewRange(1000000, 200000, 500000)
+	tests := []struct {
+		name  string
+		expr  quickfilter.Expr
+		exact bool
+	}{
+		{"leaf", quickfilter.Leaf(large), true},
+		{"small And", quickfilter.And(quickfilter.Leaf(small), quickfilter.Leaf(small2)), true},
+		{"small Or Not", quickfilter.Or(quickfilter.Leaf(small), quickfilter.Not(quickfilter.Leaf(small2))), true},
+		{"large And", quickfilter.And(quickfilter.Leaf(large), quickfilter.Leaf(large2)), false},
+		{"large Or", quickfilter.Or(quickfilter.Leaf(large), quickfilter.Leaf(large2)), false},
+		{"correlated And", quickfilter.And(quickfilter.Leaf(clustered), quickfilter.Leaf(clustered2)), false},
+		{"correlated Or", quickfilter.Or(quickfilter.Leaf(clustered), quickfilter.Leaf(clustered2)), false},
+		{"correlated And Not", quickfilter.And(quickfilter.Leaf(clustered), quickfilter.Not(quickfilter.Leaf(clustered2))), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.expr.Eval(quickfilter.New(0)).Len()
+
+			received := tt.expr.EstimateLen()
+
+			if tt.exact && expected != received {
+				t.Errorf("expected %d, got %d", expected, received)
+			}
+			if delta := expected - received; delta*10 > expected || -delta*10 > expected {
+				t.Errorf("expected about %d, got %d", expected, received)
+			}
+		})
+	}
+}
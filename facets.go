@@ -0,0 +1,44 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// FacetCounts returns the number of values in base that each of the facet
+// QuickFilters would select, i.e. the IntersectionCount of base with each
+// facet, keyed by the facet names.
+//
+// Rather than intersecting base with each facet separately, the counts are
+// computed in a single pass over base, skipping the facets entirely for words
+// of base that have no values set.
+//
+// The passed QuickFilters must all be the same size or this will panic.
+func FacetCounts(base QuickFilter, facets map[string]QuickFilter) map[string]int {
+	names := make([]string, 0, len(facets))
+	filters := make([]QuickFilter, 0, len(facets))
+	for name, qf := range facets {
+		if len(qf.bits) != len(base.bits) {
+			panic("base and facet QuickFilters must be the same size")
+		}
+		names = append(names, name)
+		filters = append(filters, qf)
+	}
+	counts := make([]int, len(filters))
+	last := len(base.bits) - 1
+	for i, word := range base.bits {
+		if i == last {
+			word &= lastWordMask(base.sourceLen)
+		}
+		if word == 0 {
+			continue
+		}
+		for j := range filters {
+			counts[j] += bits.OnesCount(word & filters[j].bits[i])
+		}
+	}
+	result := make(map[string]int, len(names))
+	for j, name := range names {
+		result[name] = counts[j]
+	}
+	return result
+}
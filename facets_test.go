@@ -0,0 +1,40 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestFacetCounts(t *testing.T) {
+	t.Run("counts", func(t *testing.T) {
+		bi := newUserIndex()
+		base := bi.Eq("status", "active").Eval(quickfilter.New(0))
+		facets := map[string]quickfilter.QuickFilter{
+			"eu": bi.Get("region", "eu"),
+			"us": bi.Get("region", "us"),
+		}
+		expected := map[string]int{"eu": 2, "us": 1}
+
+		received := quickfilter.FacetCounts(base, facets)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should ignore bits beyond Cap", func(t *testing.T) {
+		base := quickfilter.NewFilled(10)
+		facets := map[string]quickfilter.QuickFilter{
+			"all": quickfilter.NewFilled(10),
+		}
+		expected := map[string]int{"all": 10}
+
+		received := quickfilter.FacetCounts(base, facets)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
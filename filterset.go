@@ -0,0 +1,222 @@
+package quickfilter
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// FilterSet manages a set of named QuickFilters over the same source slice.
+type FilterSet struct {
+	sourceLen int
+	filters   map[string]QuickFilter
+}
+
+// NewFilterSet returns a new empty FilterSet for a source slice of length
+// sourceLen.
+func NewFilterSet(sourceLen int) FilterSet {
+	return FilterSet{
+		sourceLen: sourceLen,
+		filters:   make(map[string]QuickFilter),
+	}
+}
+
+// Cap returns the source length shared by the QuickFilters in the set.
+func (fs FilterSet) Cap() int {
+	return fs.sourceLen
+}
+
+// Len returns the number of QuickFilters in the set.
+func (fs FilterSet) Len() int {
+	return len(fs.filters)
+}
+
+// Names returns the names of the QuickFilters in the set in sorted order.
+func (fs FilterSet) Names() []string {
+	names := make([]string, 0, len(fs.filters))
+	for name := range fs.filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the QuickFilter with the given name and a boolean indicating
+// whether it was found.
+func (fs FilterSet) Get(name string) (QuickFilter, bool) {
+	qf, ok := fs.filters[name]
+	return qf, ok
+}
+
+// Set stores qf under the given name, replacing any existing QuickFilter with
+// the same name.
+//
+// The passed QuickFilter must have the same Cap() as the set or this will
+// panic.
+//
+// The original FilterSet is no longer usable and must be replaced with the
+// returned one.
+func (fs FilterSet) Set(name string, qf QuickFilter) FilterSet {
+	if qf.sourceLen != fs.sourceLen {
+		panic("QuickFilter must be the same size as the FilterSet")
+	}
+	fs.filters[name] = qf
+	return fs
+}
+
+// Delete the QuickFilter with the given name from the set.
+//
+// The original FilterSet is no longer usable and must be replaced with the
+// returned one.
+func (fs FilterSet) Delete(name string) FilterSet {
+	delete(fs.filters, name)
+	return fs
+}
+
+// Resize all the QuickFilters in the set to a new source length, keeping
+// the values below it as with Grow and Truncate.
+//
+// The original FilterSet is no longer usable and must be replaced with the
+// returned one.
+func (fs FilterSet) Resize(sourceLen int) FilterSet {
+	fs.sourceLen = sourceLen
+	for name, qf := range fs.filters {
+		fs.filters[name] = qf.Grow(sourceLen).Truncate(sourceLen)
+	}
+	return fs
+}
+
+// Expr returns an Expr that evaluates to the values set in the named
+// QuickFilter, for composing the QuickFilters of the set into nested
+// expressions with And, Or and Not.
+//
+// Naming a QuickFilter that is not in the set will panic.
+func (fs FilterSet) Expr(name string) Expr {
+	return Leaf(fs.mustGet(name))
+}
+
+// UnionOf fills dst with the values set in any of the named QuickFilters.
+// dst is resized to the Cap() of the set.
+//
+// Naming a QuickFilter that is not in the set will panic.
+func (fs FilterSet) UnionOf(dst QuickFilter, names ...string) QuickFilter {
+	dst = dst.Resize(fs.sourceLen).Clear()
+	for _, name := range names {
+		qf := fs.mustGet(name)
+		for i := range dst.bits {
+			dst.bits[i] |= qf.bits[i]
+		}
+	}
+	return dst.Recount()
+}
+
+// IntersectionOf fills dst with the values set in all of the named
+// QuickFilters. dst is resized to the Cap() of the set. If no names are
+// given, the result is empty.
+//
+// The QuickFilters are intersected in order from the fewest set values to
+// the most, stopping early once the intermediate result is empty, so the
+// order of the names does not affect performance.
+//
+// Naming a QuickFilter that is not in the set will panic.
+func (fs FilterSet) IntersectionOf(dst QuickFilter, names ...string) QuickFilter {
+	dst = dst.Resize(fs.sourceLen)
+	if len(names) == 0 {
+		return dst.Clear()
+	}
+	filters := make([]QuickFilter, len(names))
+	for i, name := range names {
+		filters[i] = fs.mustGet(name)
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].len < filters[j].len
+	})
+	dst = dst.CopyFrom(filters[0])
+	nonEmpty := dst.len > 0
+	for _, qf := range filters[1:] {
+		if !nonEmpty {
+			break
+		}
+		var word uint
+		for j := range dst.bits {
+			dst.bits[j] &= qf.bits[j]
+			word |= dst.bits[j]
+		}
+		nonEmpty = word != 0
+	}
+	return dst.Recount()
+}
+
+// DifferenceOf fills dst with the values set in the QuickFilter named base
+// but in none of the QuickFilters named in excluded. dst is resized to the
+// Cap() of the set.
+//
+// Naming a QuickFilter that is not in the set will panic.
+func (fs FilterSet) DifferenceOf(dst QuickFilter, base string, excluded ...string) QuickFilter {
+	dst = dst.Resize(fs.sourceLen).CopyFrom(fs.mustGet(base))
+	for _, name := range excluded {
+		qf := fs.mustGet(name)
+		for i := range dst.bits {
+			dst.bits[i] &^= qf.bits[i]
+		}
+	}
+	return dst.Recount()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The QuickFilters are encoded in the order of their names, so equal sets
+// produce equal encodings.
+func (fs FilterSet) MarshalBinary() ([]byte, error) {
+	names := fs.Names()
+	data := appendUvarint(nil, uint64(fs.sourceLen))
+	data = appendUvarint(data, uint64(len(names)))
+	for _, name := range names {
+		data = appendUvarint(data, uint64(len(name)))
+		data = append(data, name...)
+		data = appendBits(data, fs.filters[name])
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (fs *FilterSet) UnmarshalBinary(data []byte) error {
+	sourceLen, n := binary.Uvarint(data)
+	if n <= 0 || !plausibleSourceLen(sourceLen, len(data)) {
+		return ErrInvalidEncoding
+	}
+	data = data[n:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return ErrInvalidEncoding
+	}
+	data = data[n:]
+	bitsLen := encodedBitsLen(int(sourceLen))
+	result := NewFilterSet(int(sourceLen))
+	for i := uint64(0); i < count; i++ {
+		nameLen, n := binary.Uvarint(data)
+		if n <= 0 || nameLen > uint64(len(data)-n) {
+			return ErrInvalidEncoding
+		}
+		data = data[n:]
+		name := string(data[:nameLen])
+		data = data[nameLen:]
+		if len(data) < bitsLen {
+			return ErrInvalidEncoding
+		}
+		result.filters[name] = readBits(New(result.sourceLen), data[:bitsLen])
+		data = data[bitsLen:]
+	}
+	if len(data) != 0 {
+		return ErrInvalidEncoding
+	}
+	*fs = result
+	return nil
+}
+
+func (fs FilterSet) mustGet(name string) QuickFilter {
+	qf, ok := fs.filters[name]
+	if !ok {
+		panic("unknown QuickFilter in FilterSet: " + name)
+	}
+	return qf
+}
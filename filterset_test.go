@@ -0,0 +1,160 @@
+package quickfilter_test
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestFilterSet(t *testing.T) {
+	newFilterSet := func() quickfilter.FilterSet {
+		fs := quickfilter.NewFilterSet(10)
+		fs = fs.Set("even", quickfilter.New(10).Add(0).Add(2).Add(4).Add(6).Add(8))
+		fs = fs.Set("low", quickfilter.New(10).Add(0).Add(1).Add(2).Add(3))
+		fs = fs.Set("nine", quickfilter.New(10).Add(9))
+		return fs
+	}
+
+	t.Run("Names", func(t *testing.T) {
+		fs := newFilterSet()
+		expected := []string{"even", "low", "nine"}
+
+		received := fs.Names()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("UnionOf", func(t *testing.T) {
+		fs := newFilterSet()
+		expected := []int{0, 1, 2, 3, 9}
+
+		received := collect(fs.UnionOf(quickfilter.New(0), "low", "nine"))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Expr", func(t *testing.T) {
+		fs := newFilterSet()
+		expected := []int{1, 3}
+
+		received := collect(fs.Expr("low").And(fs.Expr("nine").Or(quickfilter.Not(fs.Expr("even")))).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("IntersectionOf", func(t *testing.T) {
+		fs := newFilterSet()
+		expected := []int{0, 2}
+
+		received := collect(fs.IntersectionOf(quickfilter.New(0), "even", "low"))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("IntersectionOf with empty intermediate result", func(t *testing.T) {
+		fs := newFilterSet()
+		expectedLen := 0
+
+		receivedLen := fs.IntersectionOf(quickfilter.New(0), "even", "low", "nine").Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("DifferenceOf", func(t *testing.T) {
+		fs := newFilterSet()
+		expected := []int{4, 6, 8}
+
+		received := collect(fs.DifferenceOf(quickfilter.New(0), "even", "low", "nine"))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		fs := newFilterSet()
+		expectedCap := 200
+
+		fs = fs.Resize(expectedCap)
+
+		for _, name := range fs.Names() {
+			qf, _ := fs.Get(name)
+			if expectedCap != qf.Cap() {
+				t.Errorf("expected %d, got %d", expectedCap, qf.Cap())
+			}
+		}
+	})
+
+	t.Run("Resize should keep contents", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			sourceLen int
+			expected  []int
+		}{
+			{"grow", 200, []int{1, 5}},
+			{"shrink", 3, []int{1}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fs := quickfilter.NewFilterSet(10).Set("a", quickfilter.New(10).Add(1).Add(5))
+
+				qf, _ := fs.Resize(tt.sourceLen).Get("a")
+				received := collect(qf)
+
+				if !reflect.DeepEqual(tt.expected, received) || qf.Len() != len(tt.expected) {
+					t.Errorf("expected %v, got %v (Len %d)", tt.expected, received, qf.Len())
+				}
+			})
+		}
+	})
+
+	t.Run("binary round trip", func(t *testing.T) {
+		fs := newFilterSet()
+
+		data, err := fs.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fs2 quickfilter.FilterSet
+		if err := fs2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(fs.Names(), fs2.Names()) {
+			t.Errorf("expected %v, got %v", fs.Names(), fs2.Names())
+		}
+		for _, name := range fs.Names() {
+			qf, _ := fs.Get(name)
+			qf2, _ := fs2.Get(name)
+			if !reflect.DeepEqual(collect(qf), collect(qf2)) {
+				t.Errorf("expected %v, got %v", collect(qf), collect(qf2))
+			}
+		}
+	})
+
+	t.Run("UnmarshalBinary should reject implausible source length", func(t *testing.T) {
+		data := make([]byte, binary.MaxVarintLen64+4)
+		data = data[:binary.PutUvarint(data, math.MaxInt64)+4]
+		copy(data[len(data)-4:], []byte{1, 1, 'a', 0})
+		var fs quickfilter.FilterSet
+
+		err := fs.UnmarshalBinary(data)
+
+		if err != quickfilter.ErrInvalidEncoding {
+			t.Errorf("expected %v, got %v", quickfilter.ErrInvalidEncoding, err)
+		}
+	})
+}
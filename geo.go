@@ -0,0 +1,107 @@
+package quickfilter
+
+import (
+	"math"
+	"math/bits"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// BBox is a geographic bounding box in degrees. The latitude and longitude
+// ranges are inclusive. A box that crosses the antimeridian is expressed with
+// MinLon greater than MaxLon, for example MinLon = 170 and MaxLon = -170.
+type BBox struct {
+	MinLat, MinLon float64
+	MaxLat, MaxLon float64
+}
+
+// AddWhereInBBox adds the indices of the points (lats[i], lons[i]) that are
+// inside box to qf. The points are tested without branches, a word at a
+// time, so that the inner loop stays cheap over millions of coordinates.
+//
+// lats and lons must have lengths equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereInBBox(qf QuickFilter, lats, lons []float64, box BBox) QuickFilter {
+	mustColumn(qf, len(lats))
+	mustColumn(qf, len(lons))
+	wraps := box.MinLon > box.MaxLon
+	for w := range qf.bits {
+		base := w * bits.UintSize
+		end := base + bits.UintSize
+		if end > qf.sourceLen {
+			end = qf.sourceLen
+		}
+		lat, lon := lats[base:end], lons[base:end]
+		var word uint
+		if wraps {
+			for j := range lat {
+				in := b2u(lat[j] >= box.MinLat) & b2u(lat[j] <= box.MaxLat) &
+					(b2u(lon[j] >= box.MinLon) | b2u(lon[j] <= box.MaxLon))
+				word |= in << uint(j)
+			}
+		} else {
+			for j := range lat {
+				in := b2u(lat[j] >= box.MinLat) & b2u(lat[j] <= box.MaxLat) &
+					b2u(lon[j] >= box.MinLon) & b2u(lon[j] <= box.MaxLon)
+				word |= in << uint(j)
+			}
+		}
+		qf.len += bits.OnesCount(word &^ qf.bits[w])
+		qf.bits[w] |= word
+	}
+	return qf
+}
+
+// AddWhereWithinRadius adds the indices of the points (lats[i], lons[i])
+// whose great-circle distance from (lat, lon) is at most radius meters to qf.
+// Points outside the bounding box of the circle are rejected before the
+// distance is computed.
+//
+// lats and lons must have lengths equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereWithinRadius(qf QuickFilter, lats, lons []float64, lat, lon, radius float64) QuickFilter {
+	mustColumn(qf, len(lats))
+	mustColumn(qf, len(lons))
+	const rad = math.Pi / 180
+	angle := radius / earthRadius
+	// The circle is compared in haversine space, which avoids an asin per
+	// point: hav(d) <= hav(angle) for angles up to pi.
+	maxHav := haversine(math.Min(angle, math.Pi))
+	dLat := angle / rad
+	minLat, maxLat := lat-dLat, lat+dLat
+	dLon := 180.0
+	if minLat > -90 && maxLat < 90 {
+		dLon = math.Asin(math.Min(math.Sin(angle)/math.Cos(lat*rad), 1)) / rad
+	}
+	cosLat := math.Cos(lat * rad)
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		if lats[i] < minLat || lats[i] > maxLat {
+			return false
+		}
+		if delta := math.Abs(math.Remainder(lons[i]-lon, 360)); delta > dLon {
+			return false
+		}
+		h := haversine((lats[i]-lat)*rad) + cosLat*math.Cos(lats[i]*rad)*haversine((lons[i]-lon)*rad)
+		return h <= maxHav
+	})
+}
+
+func haversine(theta float64) float64 {
+	s := math.Sin(theta / 2)
+	return s * s
+}
+
+func b2u(b bool) uint {
+	var u uint
+	if b {
+		u = 1
+	}
+	return u
+}
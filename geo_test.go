@@ -0,0 +1,85 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestAddWhereInBBox(t *testing.T) {
+	// Helsinki, Tallinn, Stockholm, Fiji, Samoa, Auckland.
+	lats := []float64{60.17, 59.44, 59.33, -17.71, -13.76, -36.85}
+	lons := []float64{24.94, 24.75, 18.07, 178.07, -172.10, 174.76}
+	tests := []struct {
+		name     string
+		box      quickfilter.BBox
+		expected []int
+	}{
+		{"Gulf of Finland", quickfilter.BBox{MinLat: 59, MinLon: 22, MaxLat: 61, MaxLon: 28}, []int{0, 1}},
+		{"across the antimeridian", quickfilter.BBox{MinLat: -20, MinLon: 175, MaxLat: -10, MaxLon: -170}, []int{3, 4}},
+		{"inclusive bounds", quickfilter.BBox{MinLat: 59.33, MinLon: 18.07, MaxLat: 59.33, MaxLon: 18.07}, []int{2}},
+		{"empty", quickfilter.BBox{MinLat: 0, MinLon: 0, MaxLat: 1, MaxLon: 1}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := collect(quickfilter.AddWhereInBBox(quickfilter.New(len(lats)), lats, lons, tt.box))
+
+			if !reflect.DeepEqual(tt.expected, received) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+
+	t.Run("mismatched columns should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.AddWhereInBBox(quickfilter.New(len(lats)), lats, lons[1:], quickfilter.BBox{})
+	})
+}
+
+func TestAddWhereWithinRadius(t *testing.T) {
+	lats := []float64{60.17, 59.44, 59.33, -17.71, -13.76, -36.85}
+	lons := []float64{24.94, 24.75, 18.07, 178.07, -172.10, 174.76}
+	tests := []struct {
+		name     string
+		lat, lon float64
+		radius   float64
+		expected []int
+	}{
+		// Helsinki to Tallinn is about 80 km and to Stockholm about 400 km.
+		{"Helsinki 100km", 60.17, 24.94, 100e3, []int{0, 1}},
+		{"Helsinki 500km", 60.17, 24.94, 500e3, []int{0, 1, 2}},
+		// Fiji to Samoa is about 1150 km across the antimeridian.
+		{"Fiji 1200km", -17.71, 178.07, 1200e3, []int{3, 4}},
+		{"whole globe", 0, 0, 30000e3, []int{0, 1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := collect(quickfilter.AddWhereWithinRadius(quickfilter.New(len(lats)), lats, lons, tt.lat, tt.lon, tt.radius))
+
+			if !reflect.DeepEqual(tt.expected, received) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+}
+
+func BenchmarkAddWhereInBBox(b *testing.B) {
+	const n = 1 << 20
+	lats, lons := make([]float64, n), make([]float64, n)
+	for i := range lats {
+		lats[i] = float64(i%180) - 90
+		lons[i] = float64(i%360) - 180
+	}
+	box := quickfilter.BBox{MinLat: -10, MinLon: -20, MaxLat: 10, MaxLon: 20}
+	qf := quickfilter.New(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		qf = quickfilter.AddWhereInBBox(qf.Clear(), lats, lons, box)
+	}
+}
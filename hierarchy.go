@@ -0,0 +1,91 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// Hierarchy maps the elements of a child slice to the elements of a parent
+// slice, such as line items to orders, and translates QuickFilters between
+// the two levels.
+type Hierarchy struct {
+	parents    []int
+	numParents int
+}
+
+// NewHierarchy returns a new Hierarchy between a parent slice of length
+// numParents and a child slice where the child at index i belongs to the
+// parent at index parents[i]. A negative parent index means the child has no
+// parent. The Hierarchy takes ownership of parents.
+func NewHierarchy(numParents int, parents []int) Hierarchy {
+	for _, parent := range parents {
+		if parent >= numParents {
+			panic("parent index out of range")
+		}
+	}
+	return Hierarchy{parents: parents, numParents: numParents}
+}
+
+// RollUpAny fills dst with the parents that have any of their children set in
+// children. dst is resized to the number of parents.
+//
+// The passed children must have a Cap() equal to the number of children or
+// this will panic.
+func (h Hierarchy) RollUpAny(dst, children QuickFilter) QuickFilter {
+	h.mustMatchChildren(children)
+	dst = dst.Resize(h.numParents).Clear()
+	for it := children.Iterate(); !it.Done(); it = it.Next() {
+		if parent := h.parents[it.Value()]; parent >= 0 && !dst.Has(parent) {
+			dst = dst.Add(parent)
+		}
+	}
+	return dst
+}
+
+// RollUpAll fills dst with the parents that have all of their children set
+// in children. Parents without any children are not included. dst is resized
+// to the number of parents.
+//
+// The passed children must have a Cap() equal to the number of children or
+// this will panic.
+func (h Hierarchy) RollUpAll(dst, children QuickFilter) QuickFilter {
+	dst = h.RollUpAny(dst, children)
+	last := len(children.bits) - 1
+	for i, word := range children.bits {
+		unset := ^word
+		if i == last {
+			unset &= lastWordMask(children.sourceLen)
+		}
+		for unset != 0 {
+			child := i*bits.UintSize + bits.TrailingZeros(unset)
+			unset &= unset - 1
+			if parent := h.parents[child]; parent >= 0 {
+				dst = dst.Delete(parent)
+			}
+		}
+	}
+	return dst
+}
+
+// PushDown fills dst with the children whose parent is set in parents. dst is
+// resized to the number of children.
+//
+// The passed parents must have a Cap() equal to the number of parents or
+// this will panic.
+func (h Hierarchy) PushDown(dst, parents QuickFilter) QuickFilter {
+	if parents.sourceLen != h.numParents {
+		panic("QuickFilter must be the same size as the parent slice")
+	}
+	dst = dst.Resize(len(h.parents)).Clear()
+	for child, parent := range h.parents {
+		if parent >= 0 && parents.Has(parent) {
+			dst = dst.Add(child)
+		}
+	}
+	return dst
+}
+
+func (h Hierarchy) mustMatchChildren(children QuickFilter) {
+	if children.sourceLen != len(h.parents) {
+		panic("QuickFilter must be the same size as the child slice")
+	}
+}
@@ -0,0 +1,53 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestHierarchy(t *testing.T) {
+	// orders 0..3, order 3 has no line items, line item 6 has no order
+	h := quickfilter.NewHierarchy(4, []int{0, 0, 1, 1, 1, 2, -1})
+
+	t.Run("RollUpAny", func(t *testing.T) {
+		expected := []int{0, 1}
+
+		received := collect(h.RollUpAny(quickfilter.New(0), quickfilter.New(7).Add(1).Add(3).Add(6)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("RollUpAll", func(t *testing.T) {
+		expected := []int{1, 2}
+
+		received := collect(h.RollUpAll(quickfilter.New(0), quickfilter.New(7).Add(0).Add(2).Add(3).Add(4).Add(5)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("RollUpAll filled", func(t *testing.T) {
+		expected := []int{0, 1, 2}
+
+		received := collect(h.RollUpAll(quickfilter.New(0), quickfilter.NewFilled(7)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("PushDown", func(t *testing.T) {
+		expected := []int{2, 3, 4, 5}
+
+		received := collect(h.PushDown(quickfilter.New(0), quickfilter.New(4).Add(1).Add(2).Add(3)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// History wraps a QuickFilter and records its changes as a sequence of
+// versions, allowing changes to be undone and redone and any earlier version
+// to be reconstructed. Each version stores only the words that changed, as
+// XOR deltas, so the cost of a version is proportional to the size of the
+// change rather than the size of the QuickFilter.
+type History struct {
+	qf       QuickFilter
+	pending  map[int]uint
+	versions [][]wordDelta
+	version  int
+	tags     map[string]int
+}
+
+type wordDelta struct {
+	index int
+	xor   uint
+}
+
+// NewHistory returns a new History with qf as its initial version. The
+// History takes ownership of qf.
+func NewHistory(qf QuickFilter) History {
+	return History{
+		qf:      qf,
+		pending: make(map[int]uint),
+		tags:    make(map[string]int),
+	}
+}
+
+// Filter returns the current state of the QuickFilter, including uncommitted
+// changes. The returned QuickFilter is owned by the History and must not be
+// modified.
+func (h History) Filter() QuickFilter {
+	return h.qf
+}
+
+// Version returns the number of the current version. The initial version is
+// zero.
+func (h History) Version() int {
+	return h.version
+}
+
+// Add an index to the QuickFilter as an uncommitted change.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Add(index int) History {
+	if h.qf.Has(index) {
+		return h
+	}
+	h.touch(index)
+	h.qf = h.qf.Add(index)
+	return h
+}
+
+// Delete an index from the QuickFilter as an uncommitted change.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Delete(index int) History {
+	if !h.qf.Has(index) {
+		return h
+	}
+	h.touch(index)
+	h.qf = h.qf.Delete(index)
+	return h
+}
+
+// Commit the uncommitted changes as a new version, discarding any versions
+// that could have been redone. Committing without changes is a no-op.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Commit() History {
+	deltas := make([]wordDelta, 0, len(h.pending))
+	for index, word := range h.pending {
+		if xor := word ^ h.qf.bits[index]; xor != 0 {
+			deltas = append(deltas, wordDelta{index: index, xor: xor})
+		}
+		delete(h.pending, index)
+	}
+	if len(deltas) == 0 {
+		return h
+	}
+	h.versions = append(h.versions[:h.version], deltas)
+	h.version++
+	for tag, version := range h.tags {
+		if version >= h.version {
+			delete(h.tags, tag)
+		}
+	}
+	return h
+}
+
+// Checkpoint commits the uncommitted changes and tags the resulting version,
+// so that it can be returned to with Rollback. Reusing a tag moves it.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Checkpoint(tag string) History {
+	h = h.Commit()
+	h.tags[tag] = h.version
+	return h
+}
+
+// CanUndo returns a boolean indicating whether there is a version to undo.
+func (h History) CanUndo() bool {
+	return h.version > 0 || len(h.pending) > 0
+}
+
+// CanRedo returns a boolean indicating whether there is a version to redo.
+func (h History) CanRedo() bool {
+	return h.version < len(h.versions) && len(h.pending) == 0
+}
+
+// Undo returns to the previous version. Uncommitted changes are committed
+// first, so that they can be redone. Undoing the initial version is a no-op.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Undo() History {
+	h = h.Commit()
+	if h.version == 0 {
+		return h
+	}
+	h.version--
+	h.qf = applyDeltas(h.qf, h.versions[h.version])
+	return h
+}
+
+// Redo returns to the next version after an Undo. Uncommitted changes are
+// committed first, which discards the versions that could have been redone.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Redo() History {
+	h = h.Commit()
+	if h.version == len(h.versions) {
+		return h
+	}
+	h.qf = applyDeltas(h.qf, h.versions[h.version])
+	h.version++
+	return h
+}
+
+// Rollback returns to the version tagged with Checkpoint. Uncommitted changes
+// are committed first. Versions after the tagged version can still be redone
+// until the next commit.
+//
+// Passing an unknown tag will panic.
+//
+// The original History is no longer usable and must be replaced with the
+// returned one.
+func (h History) Rollback(tag string) History {
+	h = h.Commit()
+	version, ok := h.tags[tag]
+	if !ok {
+		panic("unknown checkpoint in History: " + tag)
+	}
+	for h.version > version {
+		h = h.Undo()
+	}
+	for h.version < version {
+		h = h.Redo()
+	}
+	return h
+}
+
+// At fills dst with the given committed version of the QuickFilter without
+// changing the current version. dst is resized to the Cap() of the
+// QuickFilter.
+func (h History) At(dst QuickFilter, version int) QuickFilter {
+	if version < 0 || version > len(h.versions) {
+		panic("version out of range")
+	}
+	dst = dst.CopyFrom(h.qf)
+	for index, word := range h.pending {
+		dst.len += bits.OnesCount(word) - bits.OnesCount(dst.bits[index])
+		dst.bits[index] = word
+	}
+	for v := h.version - 1; v >= version; v-- {
+		dst = applyDeltas(dst, h.versions[v])
+	}
+	for v := h.version; v < version; v++ {
+		dst = applyDeltas(dst, h.versions[v])
+	}
+	return dst
+}
+
+func (h History) touch(index int) {
+	wordIndex, _ := offsets(index)
+	if _, ok := h.pending[wordIndex]; !ok {
+		h.pending[wordIndex] = h.qf.bits[wordIndex]
+	}
+}
+
+func applyDeltas(qf QuickFilter, deltas []wordDelta) QuickFilter {
+	for _, delta := range deltas {
+		word := qf.bits[delta.index] ^ delta.xor
+		qf.len += bits.OnesCount(word) - bits.OnesCount(qf.bits[delta.index])
+		qf.bits[delta.index] = word
+	}
+	return qf
+}
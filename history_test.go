@@ -0,0 +1,121 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestHistory(t *testing.T) {
+	newHistory := func() quickfilter.History {
+		h := quickfilter.NewHistory(quickfilter.New(100))
+		h = h.Add(1).Add(2).Commit()
+		h = h.Add(70).Delete(1).Commit()
+		return h
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		h := newHistory()
+		expectedVersion := 2
+
+		receivedVersion := h.Version()
+
+		if expectedVersion != receivedVersion {
+			t.Errorf("expected %d, got %d", expectedVersion, receivedVersion)
+		}
+	})
+
+	t.Run("Undo", func(t *testing.T) {
+		h := newHistory()
+		expected := []int{1, 2}
+
+		h = h.Undo()
+		received := collect(h.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if len(expected) != h.Filter().Len() {
+			t.Errorf("expected %d, got %d", len(expected), h.Filter().Len())
+		}
+	})
+
+	t.Run("Undo past the initial version", func(t *testing.T) {
+		h := newHistory()
+		expectedLen := 0
+
+		h = h.Undo().Undo().Undo()
+		receivedLen := h.Filter().Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+		if h.CanUndo() {
+			t.Error("expected CanUndo to return false")
+		}
+	})
+
+	t.Run("Redo", func(t *testing.T) {
+		h := newHistory()
+		expected := []int{2, 70}
+
+		h = h.Undo().Undo().Redo().Redo()
+		received := collect(h.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if h.CanRedo() {
+			t.Error("expected CanRedo to return false")
+		}
+	})
+
+	t.Run("Commit after Undo should discard redo", func(t *testing.T) {
+		h := newHistory()
+
+		h = h.Undo().Add(50).Commit()
+
+		if h.CanRedo() {
+			t.Error("expected CanRedo to return false")
+		}
+	})
+
+	t.Run("Undo should commit pending changes", func(t *testing.T) {
+		h := newHistory()
+		expected := []int{2, 70, 99}
+
+		h = h.Add(99).Undo().Redo()
+		received := collect(h.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Checkpoint and Rollback", func(t *testing.T) {
+		h := quickfilter.NewHistory(quickfilter.New(100))
+		expected := []int{1}
+
+		h = h.Add(1).Checkpoint("first")
+		h = h.Add(2).Commit()
+		h = h.Add(3).Commit()
+		h = h.Rollback("first")
+		received := collect(h.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("At", func(t *testing.T) {
+		h := newHistory()
+		expected := []int{1, 2}
+
+		received := collect(h.Add(99).At(quickfilter.New(0), 1))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
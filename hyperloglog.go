@@ -0,0 +1,151 @@
+package quickfilter
+
+import (
+	"math"
+	"math/bits"
+)
+
+// HyperLogLog is a sketch for estimating the number of distinct uint64 keys
+// added to it, using 2^precision bytes regardless of the number of keys.
+//
+// Sketches can be merged, so the cardinality of a union of QuickFilters
+// spread across shards or nodes can be estimated by maintaining a sketch
+// alongside each shard and transferring only the sketches, rather than
+// transferring and merging the full bitmaps.
+type HyperLogLog struct {
+	precision uint
+	registers []uint8
+}
+
+// NewHyperLogLog returns a new empty HyperLogLog with 2^precision registers.
+// The standard error of the estimate is about 1.04/sqrt(2^precision), for
+// example 1.6% with a precision of 12. precision must be between 4 and 16 or
+// this will panic.
+func NewHyperLogLog(precision int) HyperLogLog {
+	if precision < 4 || precision > 16 {
+		panic("precision must be between 4 and 16")
+	}
+	return HyperLogLog{precision: uint(precision), registers: make([]uint8, 1<<uint(precision))}
+}
+
+// Precision returns the precision of the HyperLogLog.
+func (h HyperLogLog) Precision() int {
+	return int(h.precision)
+}
+
+// Add a key to the HyperLogLog.
+//
+// The original HyperLogLog is no longer usable and must be replaced with the
+// returned one. This approach prevents the HyperLogLog from escaping to the
+// heap.
+func (h HyperLogLog) Add(key uint64) HyperLogLog {
+	hash := splitMix64(key)
+	index := hash >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+	return h
+}
+
+// AddFilter adds the set indices of qf to the HyperLogLog as keys, offset by
+// base. When a key space is sharded into QuickFilters, base should be the
+// global key of index 0 of the shard, so that keys are counted once across
+// shards.
+//
+// The original HyperLogLog is no longer usable and must be replaced with the
+// returned one. This approach prevents the HyperLogLog from escaping to the
+// heap.
+func (h HyperLogLog) AddFilter(qf QuickFilter, base uint64) HyperLogLog {
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		h = h.Add(base + uint64(it.Value()))
+	}
+	return h
+}
+
+// UnionOf fills the HyperLogLog with the merge of the provided HyperLogLogs,
+// estimating the cardinality of the union of their keys.
+//
+// The receiver and passed HyperLogLogs must all have the same precision or
+// this will panic.
+//
+// The original HyperLogLog is no longer usable and must be replaced with the
+// returned one. This approach prevents the HyperLogLog from escaping to the
+// heap.
+func (h HyperLogLog) UnionOf(h1, h2 HyperLogLog) HyperLogLog {
+	if h.precision != h1.precision || h.precision != h2.precision {
+		panic("receiver and passed HyperLogLogs must have the same precision")
+	}
+	for i := range h.registers {
+		r := h1.registers[i]
+		if h2.registers[i] > r {
+			r = h2.registers[i]
+		}
+		h.registers[i] = r
+	}
+	return h
+}
+
+// Clear the keys in the HyperLogLog.
+//
+// The original HyperLogLog is no longer usable and must be replaced with the
+// returned one. This approach prevents the HyperLogLog from escaping to the
+// heap.
+func (h HyperLogLog) Clear() HyperLogLog {
+	for i := range h.registers {
+		h.registers[i] = 0
+	}
+	return h
+}
+
+// Estimate returns the estimated number of distinct keys added.
+func (h HyperLogLog) Estimate() float64 {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		return m * math.Log(m/float64(zeros))
+	}
+	return estimate
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The encoding is the precision as a single byte followed by the registers.
+func (h HyperLogLog) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 1+len(h.registers))
+	data = append(data, byte(h.precision))
+	return append(data, h.registers...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 || data[0] < 4 || data[0] > 16 || len(data) != 1+1<<data[0] {
+		return ErrInvalidEncoding
+	}
+	for _, r := range data[1:] {
+		if r > 64-data[0]+1 {
+			return ErrInvalidEncoding
+		}
+	}
+	h.precision = uint(data[0])
+	h.registers = append(h.registers[:0], data[1:]...)
+	return nil
+}
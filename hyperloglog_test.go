@@ -0,0 +1,92 @@
+package quickfilter_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	withinError := func(expected, received float64) bool {
+		return math.Abs(received-expected)/expected < 0.05
+	}
+	newShard := func(sourceLen, step int) quickfilter.QuickFilter {
+		qf := quickfilter.New(sourceLen)
+		for i := 0; i < sourceLen; i += step {
+			qf = qf.Add(i)
+		}
+		return qf
+	}
+
+	t.Run("Estimate", func(t *testing.T) {
+		for _, n := range []int{10, 1000, 100000} {
+			h := quickfilter.NewHyperLogLog(12)
+			expected := float64(n)
+
+			for i := 0; i < n; i++ {
+				h = h.Add(uint64(i))
+				h = h.Add(uint64(i))
+			}
+			received := h.Estimate()
+
+			if !withinError(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+
+	t.Run("UnionOf shards", func(t *testing.T) {
+		shard1 := newShard(50000, 1)
+		shard2 := newShard(50000, 2)
+		h1 := quickfilter.NewHyperLogLog(12).AddFilter(shard1, 0)
+		h2 := quickfilter.NewHyperLogLog(12).AddFilter(shard2, 25000)
+		expected := 50000.0 + 12500.0
+
+		received := quickfilter.NewHyperLogLog(12).UnionOf(h1, h2).Estimate()
+
+		if !withinError(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("binary round trip", func(t *testing.T) {
+		h := quickfilter.NewHyperLogLog(8).AddFilter(newShard(1000, 3), 0)
+		expected := h.Estimate()
+
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded quickfilter.HyperLogLog
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		received := decoded.Estimate()
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("UnmarshalBinary with invalid data", func(t *testing.T) {
+		var h quickfilter.HyperLogLog
+		expected := quickfilter.ErrInvalidEncoding
+
+		received := h.UnmarshalBinary([]byte{8, 1, 2})
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("precision mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.NewHyperLogLog(8).UnionOf(quickfilter.NewHyperLogLog(8), quickfilter.NewHyperLogLog(9))
+	})
+}
@@ -0,0 +1,98 @@
+package quickfilter
+
+import "math"
+
+// AddManyInt32 is like AddMany for indices of type int32, for callers whose
+// IDs are int32 and who would otherwise need to convert them first.
+//
+// The indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AddManyInt32(indices []int32) QuickFilter {
+	for _, index := range indices {
+		qf.mustIndex64(int64(index))
+	}
+	for _, index := range indices {
+		qf = qf.addUnchecked(int(index))
+	}
+	return qf
+}
+
+// AddManyUint32 is like AddMany for indices of type uint32.
+//
+// The indices must be less than Cap() or this will panic with an
+// *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AddManyUint32(indices []uint32) QuickFilter {
+	for _, index := range indices {
+		qf.mustIndex64(int64(index))
+	}
+	for _, index := range indices {
+		qf = qf.addUnchecked(int(index))
+	}
+	return qf
+}
+
+// AddManyInt64 is like AddMany for indices of type int64. The range check is
+// done before converting the indices to int, so indices that overflow int
+// on 32-bit platforms are reported rather than wrapped around.
+//
+// The indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AddManyInt64(indices []int64) QuickFilter {
+	for _, index := range indices {
+		qf.mustIndex64(index)
+	}
+	for _, index := range indices {
+		qf = qf.addUnchecked(int(index))
+	}
+	return qf
+}
+
+// AppendSelectionUint32 appends the set indices to dst as uint32 values, in
+// ascending order, like AppendSelection32.
+//
+// The Cap() of the QuickFilter must not exceed the range of uint32 or this
+// will panic.
+func (qf QuickFilter) AppendSelectionUint32(dst []uint32) []uint32 {
+	if int64(qf.sourceLen)-1 > math.MaxUint32 {
+		panic("Cap() must not exceed the range of uint32")
+	}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		dst = append(dst, uint32(it.Value()))
+	}
+	return dst
+}
+
+// mustIndex64 panics with an *IndexError if the index is out of range. An
+// index that doesn't fit in an int is reported as -1.
+func (qf QuickFilter) mustIndex64(index int64) {
+	if index < 0 || index >= int64(qf.sourceLen) {
+		reported := int(index)
+		if int64(reported) != index {
+			reported = -1
+		}
+		panic(&IndexError{Index: reported, Cap: qf.sourceLen})
+	}
+}
+
+// addUnchecked adds an index that is known to be in range, without
+// incrementing Len() if it is already set.
+func (qf QuickFilter) addUnchecked(index int) QuickFilter {
+	index, mask := offsets(index)
+	if qf.bits[index]&mask == 0 {
+		qf.bits[index] |= mask
+		qf.len++
+	}
+	return qf
+}
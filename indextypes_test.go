@@ -0,0 +1,92 @@
+package quickfilter_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestIndexTypes(t *testing.T) {
+	t.Run("AddManyInt32", func(t *testing.T) {
+		expected := []int{1, 64, 99}
+
+		qf := quickfilter.New(100).AddManyInt32([]int32{99, 1, 64, 1})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AddManyUint32", func(t *testing.T) {
+		expected := []int{1, 64, 99}
+
+		qf := quickfilter.New(100).AddManyUint32([]uint32{99, 1, 64})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AddManyInt64", func(t *testing.T) {
+		expected := []int{1, 64, 99}
+
+		qf := quickfilter.New(100).AddManyInt64([]int64{99, 1, 64})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("out of range should panic", func(t *testing.T) {
+		qf := quickfilter.New(100)
+		for _, fn := range []func(){
+			func() { qf.AddManyInt32([]int32{1, -1}) },
+			func() { qf.AddManyUint32([]uint32{1 << 31}) },
+			func() { qf.AddManyInt64([]int64{1 << 40}) },
+		} {
+			func() {
+				defer func() {
+					if _, ok := recover().(*quickfilter.IndexError); !ok {
+						t.Error("expected a panic with an IndexError")
+					}
+				}()
+
+				fn()
+			}()
+		}
+		if qf.Len() != 0 || len(collect(qf)) != 0 {
+			t.Error("expected the QuickFilter to be unchanged")
+		}
+	})
+
+	t.Run("AppendSelectionUint32", func(t *testing.T) {
+		qf := quickfilter.NewFromIndices(100, []int{3, 70})
+		expected := []uint32{3, 70}
+
+		received := qf.AppendSelectionUint32(nil)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AppendSelectionUint32 with Cap beyond uint32 should panic", func(t *testing.T) {
+		sourceLen := int64(math.MaxUint32) + 2
+		if int64(int(sourceLen)) != sourceLen {
+			t.Skip("int is 32 bits")
+		}
+		qf := quickfilter.New(int(sourceLen))
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		qf.AppendSelectionUint32(nil)
+	})
+}
@@ -0,0 +1,72 @@
+package quickfilter
+
+import (
+	"time"
+)
+
+// Instrumentation receives callbacks about the work done by QuickFilters, so
+// that it can be bridged to a metrics system such as Prometheus or
+// OpenTelemetry. Implementations must be safe for concurrent use and should
+// return quickly, as the callbacks are made synchronously.
+type Instrumentation interface {
+	// Alloc is called when a new backing buffer of the given number of
+	// words is allocated.
+	Alloc(words int)
+	// Resize is called when the Cap() of a QuickFilter is changed.
+	Resize(oldCap, newCap int)
+	// SetOp is called when a set operation, identified by op, completes,
+	// with the time it took.
+	SetOp(op string, d time.Duration)
+}
+
+// PoolInstrumentation can be implemented by an Instrumentation to also
+// receive callbacks about the buffers of a TenantPool, for tracking the hit
+// rate of the pool.
+type PoolInstrumentation interface {
+	// PoolGet is called when a buffer is requested from a pool, with hit
+	// reporting whether an idle buffer was reused.
+	PoolGet(tenant string, hit bool)
+	// PoolEvict is called when a buffer is dropped from a pool or rejected
+	// by it to stay within the budgets.
+	PoolEvict(tenant string)
+}
+
+var instrumentation Instrumentation
+
+// SetInstrumentation installs i to receive callbacks for all QuickFilters,
+// or removes the instrumentation if i is nil. Without instrumentation the
+// callbacks cost a single nil check.
+//
+// SetInstrumentation is not safe for concurrent use with QuickFilters, and
+// should be called during program initialization.
+func SetInstrumentation(i Instrumentation) {
+	instrumentation = i
+}
+
+func observeAlloc(words int) {
+	if instrumentation != nil {
+		instrumentation.Alloc(words)
+	}
+}
+
+func observeResize(oldCap, newCap int) {
+	if instrumentation != nil && oldCap != newCap {
+		instrumentation.Resize(oldCap, newCap)
+	}
+}
+
+func observeSetOp(op string, start time.Time) {
+	instrumentation.SetOp(op, time.Since(start))
+}
+
+func observePoolGet(tenant string, hit bool) {
+	if pi, ok := instrumentation.(PoolInstrumentation); ok {
+		pi.PoolGet(tenant, hit)
+	}
+}
+
+func observePoolEvict(tenant string) {
+	if pi, ok := instrumentation.(PoolInstrumentation); ok {
+		pi.PoolEvict(tenant)
+	}
+}
@@ -0,0 +1,123 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+type mockInstrumentation struct {
+	allocs  []int
+	resizes [][2]int
+	ops     []string
+}
+
+func (m *mockInstrumentation) Alloc(words int) {
+	m.allocs = append(m.allocs, words)
+}
+
+func (m *mockInstrumentation) Resize(oldCap, newCap int) {
+	m.resizes = append(m.resizes, [2]int{oldCap, newCap})
+}
+
+func (m *mockInstrumentation) SetOp(op string, d time.Duration) {
+	m.ops = append(m.ops, op)
+}
+
+type mockPoolInstrumentation struct {
+	mockInstrumentation
+	gets      []bool
+	evictions int
+}
+
+func (m *mockPoolInstrumentation) PoolGet(tenant string, hit bool) {
+	m.gets = append(m.gets, hit)
+}
+
+func (m *mockPoolInstrumentation) PoolEvict(tenant string) {
+	m.evictions++
+}
+
+func TestInstrumentation(t *testing.T) {
+	t.Run("Alloc", func(t *testing.T) {
+		m := &mockInstrumentation{}
+		quickfilter.SetInstrumentation(m)
+		defer quickfilter.SetInstrumentation(nil)
+		expected := []int{2, 4}
+
+		quickfilter.New(100).Resize(50).Resize(200)
+		received := m.allocs
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		m := &mockInstrumentation{}
+		quickfilter.SetInstrumentation(m)
+		defer quickfilter.SetInstrumentation(nil)
+		expected := [][2]int{{100, 50}, {50, 200}}
+
+		quickfilter.New(100).Resize(50).Resize(50).Resize(200)
+		received := m.resizes
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("SetOp", func(t *testing.T) {
+		qf1, qf2 := quickfilter.New(100), quickfilter.New(100)
+		m := &mockInstrumentation{}
+		quickfilter.SetInstrumentation(m)
+		defer quickfilter.SetInstrumentation(nil)
+		expected := []string{"UnionOf", "IntersectionOf", "Expr.Eval"}
+
+		qf1 = qf1.UnionOf(qf1, qf2)
+		qf1 = qf1.IntersectionOf(qf1, qf2)
+		quickfilter.Leaf(qf1).Eval(qf2)
+		received := m.ops
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		m := &mockInstrumentation{}
+		quickfilter.SetInstrumentation(m)
+		quickfilter.SetInstrumentation(nil)
+		expectedLen := 0
+
+		quickfilter.New(100)
+		receivedLen := len(m.allocs)
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("PoolInstrumentation", func(t *testing.T) {
+		m := &mockPoolInstrumentation{}
+		quickfilter.SetInstrumentation(m)
+		defer quickfilter.SetInstrumentation(nil)
+		p := quickfilter.NewTenantPool(1<<20, 1<<20)
+		expected := []bool{false, true, false}
+		expectedEvictions := 1
+
+		p.Put("a", p.Get("a", 100))
+		p.Get("a", 100)
+		p.Put("a", p.Get("a", 1<<24))
+		received := m.gets
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if expectedEvictions != m.evictions {
+			t.Errorf("expected %d, got %d", expectedEvictions, m.evictions)
+		}
+	})
+}
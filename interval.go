@@ -0,0 +1,195 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Interval is a half-open range of indices [Lo, Hi).
+type Interval struct {
+	Lo int
+	Hi int
+}
+
+// IntervalSet is a set of indices stored as sorted, non-overlapping ranges,
+// such as business rules like "rows 10k-20k and 55k-70k". It converts to and
+// from QuickFilter a word at a time rather than a bit at a time.
+type IntervalSet struct {
+	intervals []Interval
+}
+
+// NewIntervalSet returns a new IntervalSet of the given intervals, which may
+// be unsorted, overlapping or empty.
+func NewIntervalSet(intervals ...Interval) IntervalSet {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		if interval.Lo < interval.Hi {
+			sorted = append(sorted, interval)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Lo < sorted[j].Lo
+	})
+	return IntervalSet{intervals: appendIntervals(nil, sorted...)}
+}
+
+// IntervalSetOf returns the IntervalSet of the runs of set values in qf.
+func IntervalSetOf(qf QuickFilter) IntervalSet {
+	var intervals []Interval
+	qf.eachRun(func(lo, hi int) {
+		intervals = append(intervals, Interval{Lo: lo, Hi: hi})
+	})
+	return IntervalSet{intervals: intervals}
+}
+
+// eachRun calls fn with the bounds of each run of set values in ascending
+// order, finding the runs a word at a time.
+func (qf QuickFilter) eachRun(fn func(lo, hi int)) {
+	inRun, start := false, 0
+	last := len(qf.bits) - 1
+	for i, word := range qf.bits {
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		if (!inRun && word == 0) || (inRun && word == ^uint(0)) {
+			continue
+		}
+		base := i * bits.UintSize
+		for bit := 0; bit < bits.UintSize; {
+			if !inRun {
+				w := word >> uint(bit)
+				if w == 0 {
+					break
+				}
+				bit += bits.TrailingZeros(w)
+				inRun, start = true, base+bit
+			} else {
+				w := ^word >> uint(bit)
+				if w == 0 {
+					break
+				}
+				bit += bits.TrailingZeros(w)
+				inRun = false
+				fn(start, base+bit)
+			}
+		}
+	}
+	if inRun {
+		fn(start, qf.sourceLen)
+	}
+}
+
+// Intervals returns the sorted, non-overlapping and non-adjacent intervals
+// of the set. The returned slice must not be modified.
+func (s IntervalSet) Intervals() []Interval {
+	return s.intervals
+}
+
+// Len returns the number of indices in the set.
+func (s IntervalSet) Len() int {
+	n := 0
+	for _, interval := range s.intervals {
+		n += interval.Hi - interval.Lo
+	}
+	return n
+}
+
+// Has returns a boolean indicating whether the index is in the set.
+func (s IntervalSet) Has(index int) bool {
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return s.intervals[i].Hi > index
+	})
+	return i < len(s.intervals) && s.intervals[i].Lo <= index
+}
+
+// Union returns the indices in one or both of the sets.
+func (s IntervalSet) Union(other IntervalSet) IntervalSet {
+	result := make([]Interval, 0, len(s.intervals)+len(other.intervals))
+	a, b := s.intervals, other.intervals
+	for len(a) > 0 || len(b) > 0 {
+		if len(b) == 0 || (len(a) > 0 && a[0].Lo <= b[0].Lo) {
+			result = appendIntervals(result, a[0])
+			a = a[1:]
+		} else {
+			result = appendIntervals(result, b[0])
+			b = b[1:]
+		}
+	}
+	return IntervalSet{intervals: result}
+}
+
+// Intersection returns the indices in both of the sets.
+func (s IntervalSet) Intersection(other IntervalSet) IntervalSet {
+	var result []Interval
+	a, b := s.intervals, other.intervals
+	for len(a) > 0 && len(b) > 0 {
+		lo, hi := a[0].Lo, a[0].Hi
+		if b[0].Lo > lo {
+			lo = b[0].Lo
+		}
+		if b[0].Hi < hi {
+			hi = b[0].Hi
+		}
+		if lo < hi {
+			result = append(result, Interval{Lo: lo, Hi: hi})
+		}
+		if a[0].Hi < b[0].Hi {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return IntervalSet{intervals: result}
+}
+
+// Difference returns the indices in the receiver but not in other.
+func (s IntervalSet) Difference(other IntervalSet) IntervalSet {
+	var result []Interval
+	b := other.intervals
+	for _, interval := range s.intervals {
+		lo := interval.Lo
+		for len(b) > 0 && b[0].Hi <= lo {
+			b = b[1:]
+		}
+		for i := 0; i < len(b) && b[i].Lo < interval.Hi; i++ {
+			if b[i].Lo > lo {
+				result = append(result, Interval{Lo: lo, Hi: b[i].Lo})
+			}
+			lo = b[i].Hi
+		}
+		if lo < interval.Hi {
+			result = append(result, Interval{Lo: lo, Hi: interval.Hi})
+		}
+	}
+	return IntervalSet{intervals: result}
+}
+
+// ToQuickFilter fills dst with the indices in the set, keeping the Cap() of
+// dst.
+//
+// The intervals must all be within the Cap() of dst or this will panic.
+func (s IntervalSet) ToQuickFilter(dst QuickFilter) QuickFilter {
+	dst = dst.Clear()
+	for _, interval := range s.intervals {
+		if interval.Lo < 0 || interval.Hi > dst.sourceLen {
+			panic("interval out of range")
+		}
+		dst = dst.addRange(interval.Lo, interval.Hi)
+	}
+	return dst
+}
+
+// appendIntervals appends sorted intervals to sorted, merging overlapping and
+// adjacent ones.
+func appendIntervals(sorted []Interval, intervals ...Interval) []Interval {
+	for _, interval := range intervals {
+		if n := len(sorted); n > 0 && interval.Lo <= sorted[n-1].Hi {
+			if interval.Hi > sorted[n-1].Hi {
+				sorted[n-1].Hi = interval.Hi
+			}
+			continue
+		}
+		sorted = append(sorted, interval)
+	}
+	return sorted
+}
@@ -0,0 +1,92 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestIntervalSet(t *testing.T) {
+	iv := func(lo, hi int) quickfilter.Interval {
+		return quickfilter.Interval{Lo: lo, Hi: hi}
+	}
+
+	t.Run("NewIntervalSet should normalize", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(0, 10), iv(20, 40)}
+
+		received := quickfilter.NewIntervalSet(iv(30, 40), iv(5, 10), iv(0, 6), iv(20, 30), iv(50, 50)).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Has and Len", func(t *testing.T) {
+		s := quickfilter.NewIntervalSet(iv(0, 10), iv(20, 40))
+		expectedLen := 30
+
+		receivedLen := s.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+		if !s.Has(0) || !s.Has(39) || s.Has(10) || s.Has(19) || s.Has(40) {
+			t.Error("unexpected Has result")
+		}
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(0, 15), iv(20, 40)}
+
+		received := quickfilter.NewIntervalSet(iv(0, 10), iv(20, 30)).Union(quickfilter.NewIntervalSet(iv(10, 15), iv(25, 40))).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Intersection", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(5, 10), iv(20, 30), iv(35, 38)}
+
+		received := quickfilter.NewIntervalSet(iv(0, 10), iv(20, 30), iv(35, 40)).Intersection(quickfilter.NewIntervalSet(iv(5, 38))).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Difference", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(0, 5), iv(8, 10), iv(30, 40)}
+
+		received := quickfilter.NewIntervalSet(iv(0, 10), iv(20, 40)).Difference(quickfilter.NewIntervalSet(iv(5, 8), iv(15, 30))).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("QuickFilter round trip", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(0, 1), iv(3, 64), iv(65, 130), iv(190, 200)}
+
+		qf := quickfilter.NewIntervalSet(expected...).ToQuickFilter(quickfilter.New(200))
+		received := quickfilter.IntervalSetOf(qf).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if qf.Len() != 1+61+65+10 {
+			t.Errorf("expected %d, got %d", 1+61+65+10, qf.Len())
+		}
+	})
+
+	t.Run("IntervalSetOf filled", func(t *testing.T) {
+		expected := []quickfilter.Interval{iv(0, 70)}
+
+		received := quickfilter.IntervalSetOf(quickfilter.NewFilled(70)).Intervals()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package quickfilter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FilterJSONL copies the records of the JSON Lines stream src whose
+// zero-based record index is set in qf to dst. The records aren't decoded,
+// so this is the same as FilterLines.
+func FilterJSONL(dst io.Writer, src io.Reader, qf QuickFilter) error {
+	return FilterLines(dst, src, qf)
+}
+
+// FilterJSONLWhere copies the records of the JSON Lines stream src that
+// match pred to dst, each followed by a newline, and returns a QuickFilter of
+// the matching record indices, with a Cap() of the number of records read.
+// The returned QuickFilter can be reused with FilterJSONL to filter other
+// streams with the same record order without evaluating pred again.
+//
+// The raw record passed to pred, which can be decoded with json.Unmarshal,
+// is only valid for the duration of the call. An error is returned if a
+// record isn't valid JSON.
+func FilterJSONLWhere(dst io.Writer, src io.Reader, pred func(record int, raw json.RawMessage) bool) (QuickFilter, error) {
+	r := bufio.NewReader(src)
+	bw := bufio.NewWriter(dst)
+	s := NewStream(0)
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return QuickFilter{}, err
+		}
+		if len(line) > 0 {
+			raw := bytes.TrimRight(line, "\r\n")
+			if !json.Valid(raw) {
+				return QuickFilter{}, fmt.Errorf("quickfilter: invalid JSON in record %d", s.Cap())
+			}
+			if pred(s.Cap(), raw) {
+				bw.Write(raw)
+				if err := bw.WriteByte('\n'); err != nil {
+					return QuickFilter{}, err
+				}
+				s = s.ObserveKept()
+			} else {
+				s = s.ObserveDropped()
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		line = line[:0]
+	}
+	if err := bw.Flush(); err != nil {
+		return QuickFilter{}, err
+	}
+	return s.Freeze(), nil
+}
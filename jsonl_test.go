@@ -0,0 +1,72 @@
+package quickfilter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestFilterJSONL(t *testing.T) {
+	src := "{\"name\":\"alice\"}\n{\"name\":\"bob\"}\n{\"name\":\"carol\"}\n"
+	qf := quickfilter.New(3).Add(0).Add(2)
+	var buf bytes.Buffer
+	expected := "{\"name\":\"alice\"}\n{\"name\":\"carol\"}\n"
+
+	err := quickfilter.FilterJSONL(&buf, strings.NewReader(src), qf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := buf.String()
+
+	if expected != received {
+		t.Errorf("expected %q, got %q", expected, received)
+	}
+}
+
+func TestFilterJSONLWhere(t *testing.T) {
+	t.Run("should copy and record the matching records", func(t *testing.T) {
+		src := "{\"age\":30}\r\n{\"age\":17}\n{\"age\":45}\n{\"age\":12}"
+		var buf bytes.Buffer
+		expected := "{\"age\":17}\n{\"age\":12}\n"
+		expectedIndices := []int{1, 3}
+		expectedCap := 4
+
+		qf, err := quickfilter.FilterJSONLWhere(&buf, strings.NewReader(src), func(record int, raw json.RawMessage) bool {
+			var v struct{ Age int }
+			if err := json.Unmarshal(raw, &v); err != nil {
+				t.Fatal(err)
+			}
+			return v.Age < 18
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := buf.String()
+		receivedIndices := collect(qf)
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+		if !reflect.DeepEqual(expectedIndices, receivedIndices) || expectedCap != qf.Cap() {
+			t.Errorf("expected %v, got %v", expectedIndices, receivedIndices)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		src := "{\"age\":30}\n\n{\"age\":45}\n"
+		var buf bytes.Buffer
+		expected := "quickfilter: invalid JSON in record 1"
+
+		_, err := quickfilter.FilterJSONLWhere(&buf, strings.NewReader(src), func(int, json.RawMessage) bool {
+			return true
+		})
+
+		if err == nil || expected != err.Error() {
+			t.Errorf("expected %q, got %v", expected, err)
+		}
+	})
+}
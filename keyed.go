@@ -0,0 +1,181 @@
+package quickfilter
+
+// Dictionary maps string keys to dense indices, in the order the keys were
+// added, so that sets of keys can be stored as QuickFilters. A Dictionary is
+// shared between the KeyedFilters using it.
+type Dictionary struct {
+	indices map[string]int
+	keys    []string
+}
+
+// NewDictionary returns a new Dictionary containing the given keys.
+func NewDictionary(keys ...string) *Dictionary {
+	d := &Dictionary{indices: make(map[string]int, len(keys))}
+	for _, key := range keys {
+		d.Add(key)
+	}
+	return d
+}
+
+// Len returns the number of keys in the Dictionary.
+func (d *Dictionary) Len() int {
+	return len(d.keys)
+}
+
+// Add a key to the Dictionary if it isn't already in it, and return its
+// index.
+func (d *Dictionary) Add(key string) int {
+	if index, ok := d.indices[key]; ok {
+		return index
+	}
+	index := len(d.keys)
+	d.indices[key] = index
+	d.keys = append(d.keys, key)
+	return index
+}
+
+// Index returns the index of the key and a boolean indicating whether the
+// key is in the Dictionary.
+func (d *Dictionary) Index(key string) (int, bool) {
+	index, ok := d.indices[key]
+	return index, ok
+}
+
+// Key returns the key at the index.
+func (d *Dictionary) Key(index int) string {
+	return d.keys[index]
+}
+
+// KeyedFilter is a QuickFilter of string keys, paired with the Dictionary
+// that maps the keys to indices. The QuickFilter grows as keys are added to
+// the Dictionary.
+type KeyedFilter struct {
+	dict *Dictionary
+	qf   QuickFilter
+}
+
+// NewKeyedFilter returns a new empty KeyedFilter using the given Dictionary.
+func NewKeyedFilter(dict *Dictionary) KeyedFilter {
+	return KeyedFilter{dict: dict, qf: New(dict.Len())}
+}
+
+// Dictionary returns the Dictionary of the KeyedFilter.
+func (kf KeyedFilter) Dictionary() *Dictionary {
+	return kf.dict
+}
+
+// Filter returns the QuickFilter of the KeyedFilter, with a Cap() equal to
+// the Len() of the Dictionary. The returned QuickFilter shares its storage
+// with the KeyedFilter.
+func (kf KeyedFilter) Filter() QuickFilter {
+	return kf.sync().qf
+}
+
+// Len returns the number of keys stored.
+func (kf KeyedFilter) Len() int {
+	return kf.qf.Len()
+}
+
+// AddKey adds a key to the KeyedFilter, adding it to the Dictionary if it
+// isn't already in it.
+//
+// The original KeyedFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the KeyedFilter from escaping to the
+// heap.
+func (kf KeyedFilter) AddKey(key string) KeyedFilter {
+	index := kf.dict.Add(key)
+	kf = kf.sync()
+	if !kf.qf.Has(index) {
+		kf.qf = kf.qf.Add(index)
+	}
+	return kf
+}
+
+// DeleteKey deletes a key from the KeyedFilter.
+//
+// The original KeyedFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the KeyedFilter from escaping to the
+// heap.
+func (kf KeyedFilter) DeleteKey(key string) KeyedFilter {
+	if index, ok := kf.dict.Index(key); ok && index < kf.qf.sourceLen {
+		kf.qf = kf.qf.Delete(index)
+	}
+	return kf
+}
+
+// HasKey returns a boolean indicating whether the KeyedFilter has the key.
+func (kf KeyedFilter) HasKey(key string) bool {
+	index, ok := kf.dict.Index(key)
+	return ok && index < kf.qf.sourceLen && kf.qf.Has(index)
+}
+
+// UnionOf fills the KeyedFilter with the keys in one or both of the provided
+// KeyedFilters.
+//
+// The receiver and passed KeyedFilters must all use the same Dictionary or
+// this will panic.
+//
+// The original KeyedFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the KeyedFilter from escaping to the
+// heap.
+func (kf KeyedFilter) UnionOf(kf1, kf2 KeyedFilter) KeyedFilter {
+	kf.mustShareDictionary(kf1, kf2)
+	kf = kf.sync()
+	kf.qf = kf.qf.UnionOf(kf1.sync().qf, kf2.sync().qf)
+	return kf
+}
+
+// IntersectionOf fills the KeyedFilter with the keys in both of the provided
+// KeyedFilters.
+//
+// The receiver and passed KeyedFilters must all use the same Dictionary or
+// this will panic.
+//
+// The original KeyedFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the KeyedFilter from escaping to the
+// heap.
+func (kf KeyedFilter) IntersectionOf(kf1, kf2 KeyedFilter) KeyedFilter {
+	kf.mustShareDictionary(kf1, kf2)
+	kf = kf.sync()
+	kf.qf = kf.qf.IntersectionOf(kf1.sync().qf, kf2.sync().qf)
+	return kf
+}
+
+// IterateKeys iterates over the stored keys in Dictionary order.
+func (kf KeyedFilter) IterateKeys() KeyIterator {
+	return KeyIterator{it: kf.qf.Iterate(), dict: kf.dict}
+}
+
+func (kf KeyedFilter) sync() KeyedFilter {
+	kf.qf = kf.qf.Grow(kf.dict.Len())
+	return kf
+}
+
+func (kf KeyedFilter) mustShareDictionary(kf1, kf2 KeyedFilter) {
+	if kf.dict != kf1.dict || kf.dict != kf2.dict {
+		panic("receiver and passed KeyedFilters must use the same Dictionary")
+	}
+}
+
+// KeyIterator over the keys of a KeyedFilter.
+type KeyIterator struct {
+	it   Iterator
+	dict *Dictionary
+}
+
+// Done returns a boolean indicating whether the KeyIterator has been
+// exhausted.
+func (it KeyIterator) Done() bool {
+	return it.it.Done()
+}
+
+// Next returns the KeyIterator at the next key.
+func (it KeyIterator) Next() KeyIterator {
+	it.it = it.it.Next()
+	return it
+}
+
+// Key returns the current key.
+func (it KeyIterator) Key() string {
+	return it.dict.Key(it.it.Value())
+}
@@ -0,0 +1,85 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestKeyedFilter(t *testing.T) {
+	collectKeys := func(kf quickfilter.KeyedFilter) []string {
+		keys := []string{}
+		for it := kf.IterateKeys(); !it.Done(); it = it.Next() {
+			keys = append(keys, it.Key())
+		}
+		return keys
+	}
+
+	t.Run("AddKey, DeleteKey and HasKey", func(t *testing.T) {
+		dict := quickfilter.NewDictionary("a", "b")
+		kf := quickfilter.NewKeyedFilter(dict)
+		expected := []string{"b", "c"}
+
+		kf = kf.AddKey("a").AddKey("b").AddKey("c").AddKey("c").DeleteKey("a").DeleteKey("d")
+		received := collectKeys(kf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if kf.Len() != 2 {
+			t.Errorf("expected %d, got %d", 2, kf.Len())
+		}
+		if !kf.HasKey("b") || kf.HasKey("a") || kf.HasKey("d") {
+			t.Error("unexpected HasKey result")
+		}
+	})
+
+	t.Run("should grow with the Dictionary", func(t *testing.T) {
+		dict := quickfilter.NewDictionary()
+		kf1 := quickfilter.NewKeyedFilter(dict).AddKey("first")
+		expected := []string{"first"}
+
+		for i := 0; i < 200; i++ {
+			dict.Add(string(rune('A' + i)))
+		}
+		received := collectKeys(kf1.AddKey("first"))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if kf1.Filter().Cap() != dict.Len() {
+			t.Errorf("expected %d, got %d", dict.Len(), kf1.Filter().Cap())
+		}
+	})
+
+	t.Run("UnionOf and IntersectionOf", func(t *testing.T) {
+		dict := quickfilter.NewDictionary()
+		kf1 := quickfilter.NewKeyedFilter(dict).AddKey("a").AddKey("b")
+		kf2 := quickfilter.NewKeyedFilter(dict).AddKey("b").AddKey("c")
+		expectedUnion := []string{"a", "b", "c"}
+		expectedIntersection := []string{"b"}
+
+		receivedUnion := collectKeys(quickfilter.NewKeyedFilter(dict).UnionOf(kf1, kf2))
+		receivedIntersection := collectKeys(quickfilter.NewKeyedFilter(dict).IntersectionOf(kf1, kf2))
+
+		if !reflect.DeepEqual(expectedUnion, receivedUnion) {
+			t.Errorf("expected %v, got %v", expectedUnion, receivedUnion)
+		}
+		if !reflect.DeepEqual(expectedIntersection, receivedIntersection) {
+			t.Errorf("expected %v, got %v", expectedIntersection, receivedIntersection)
+		}
+	})
+
+	t.Run("different Dictionaries should panic", func(t *testing.T) {
+		kf1 := quickfilter.NewKeyedFilter(quickfilter.NewDictionary())
+		kf2 := quickfilter.NewKeyedFilter(quickfilter.NewDictionary())
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		kf1.UnionOf(kf1, kf2)
+	})
+}
@@ -0,0 +1,43 @@
+package quickfilter
+
+import (
+	"bufio"
+	"io"
+)
+
+// FilterLines copies the lines of src whose zero-based line number is set in
+// qf to dst, including their line endings, such as for extracting matching
+// lines from a large log file using a QuickFilter computed from its metadata.
+//
+// The lines are streamed through a buffer, so lines of any length are
+// supported without holding them in memory, and reading stops after the
+// last set line number.
+func FilterLines(dst io.Writer, src io.Reader, qf QuickFilter) error {
+	it := qf.Iterate()
+	if it.Done() {
+		return nil
+	}
+	r := bufio.NewReader(src)
+	bw := bufio.NewWriter(dst)
+	for line := 0; !it.Done(); {
+		chunk, err := r.ReadSlice('\n')
+		if line == it.Value() && len(chunk) > 0 {
+			if _, werr := bw.Write(chunk); werr != nil {
+				return werr
+			}
+		}
+		switch err {
+		case nil:
+			if line == it.Value() {
+				it = it.Next()
+			}
+			line++
+		case bufio.ErrBufferFull:
+		case io.EOF:
+			return bw.Flush()
+		default:
+			return err
+		}
+	}
+	return bw.Flush()
+}
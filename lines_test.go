@@ -0,0 +1,62 @@
+package quickfilter_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestFilterLines(t *testing.T) {
+	t.Run("should copy the selected lines", func(t *testing.T) {
+		src := "zero\none\ntwo\nthree\nfour"
+		qf := quickfilter.New(10).Add(1).Add(3).Add(4).Add(9)
+		var buf bytes.Buffer
+		expected := "one\nthree\nfour"
+
+		err := quickfilter.FilterLines(&buf, strings.NewReader(src), qf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := buf.String()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("should support long lines", func(t *testing.T) {
+		long := strings.Repeat("x", 3*bufio.MaxScanTokenSize) + "\n"
+		src := "short\n" + long + "short\n"
+		qf := quickfilter.New(3).Add(1)
+		var buf bytes.Buffer
+
+		err := quickfilter.FilterLines(&buf, strings.NewReader(src), qf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := buf.String()
+
+		if long != received {
+			t.Errorf("expected %d bytes, got %d", len(long), len(received))
+		}
+	})
+
+	t.Run("should stop after the last set line", func(t *testing.T) {
+		src := "zero\none\n" + strings.Repeat("more\n", 10000)
+		qf := quickfilter.New(3).Add(0)
+		r := strings.NewReader(src)
+		var buf bytes.Buffer
+
+		err := quickfilter.FilterLines(&buf, r, qf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Len() == 0 {
+			t.Error("expected the rest of the source to be left unread")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package quickfilter
+
+// MutableFilter wraps a QuickFilter with pointer-receiver methods that
+// modify it in place, so there is no returned value to forget to reassign:
+//
+//	mf := quickfilter.NewMutableFilter(len(items))
+//	mf.Add(3)
+//
+// A MutableFilter is usually allocated on the heap. Code where that matters
+// should keep using QuickFilter directly.
+type MutableFilter struct {
+	qf QuickFilter
+}
+
+// NewMutableFilter returns a new empty MutableFilter with enough space
+// reserved to store sourceLen offsets.
+func NewMutableFilter(sourceLen int) *MutableFilter {
+	return &MutableFilter{qf: New(sourceLen)}
+}
+
+// MutableFilterOf returns a MutableFilter wrapping qf. The original
+// QuickFilter is no longer usable, as the MutableFilter takes over its
+// backing buffer.
+func MutableFilterOf(qf QuickFilter) *MutableFilter {
+	return &MutableFilter{qf: qf}
+}
+
+// QuickFilter returns the current state as a QuickFilter. The returned
+// QuickFilter shares its backing buffer with the MutableFilter, so it must
+// not be modified and is only valid until the MutableFilter is next
+// modified; use Copy to keep a snapshot.
+func (mf *MutableFilter) QuickFilter() QuickFilter {
+	return mf.qf
+}
+
+// Len returns the number of offsets stored.
+func (mf *MutableFilter) Len() int {
+	return mf.qf.Len()
+}
+
+// Cap returns the maximum number of values that can be stored.
+func (mf *MutableFilter) Cap() int {
+	return mf.qf.Cap()
+}
+
+// Has returns a boolean indicating whether the bit at given index is set.
+func (mf *MutableFilter) Has(index int) bool {
+	return mf.qf.Has(index)
+}
+
+// Iterate over the stored offsets.
+func (mf *MutableFilter) Iterate() Iterator {
+	return mf.qf.Iterate()
+}
+
+// Add an index to the offset list. Adding an index that is already set has
+// no effect.
+func (mf *MutableFilter) Add(index int) {
+	mf.qf = mf.qf.Add(index)
+}
+
+// Delete an index from the offset list.
+func (mf *MutableFilter) Delete(index int) {
+	mf.qf = mf.qf.Delete(index)
+}
+
+// Clear the entries.
+func (mf *MutableFilter) Clear() {
+	mf.qf = mf.qf.Clear()
+}
+
+// Fill to contain all the entries in the source slice.
+func (mf *MutableFilter) Fill() {
+	mf.qf = mf.qf.Fill()
+}
+
+// Resize to a new source length. Will allocate a new backing buffer if the
+// source length won't fit in the old one.
+func (mf *MutableFilter) Resize(sourceLen int) {
+	mf.qf = mf.qf.Resize(sourceLen)
+}
+
+// CopyFrom copies the set values from an existing QuickFilter, resizing if
+// the Cap() differs.
+func (mf *MutableFilter) CopyFrom(qf QuickFilter) {
+	mf.qf = mf.qf.CopyFrom(qf)
+}
+
+// UnionOf fills the MutableFilter with the set values in one or both of the
+// provided QuickFilters.
+//
+// The receiver and passed QuickFilters must all be the same size or this will
+// panic.
+func (mf *MutableFilter) UnionOf(qf1, qf2 QuickFilter) {
+	mf.qf = mf.qf.UnionOf(qf1, qf2)
+}
+
+// IntersectionOf fills the MutableFilter with the set values in both of the
+// provided QuickFilters.
+//
+// The receiver and passed QuickFilters must all be the same size or this will
+// panic.
+func (mf *MutableFilter) IntersectionOf(qf1, qf2 QuickFilter) {
+	mf.qf = mf.qf.IntersectionOf(qf1, qf2)
+}
+
+// SwapWith exchanges the contents of the MutableFilter and other in
+// constant time, without copying the bits. This allows double-buffering,
+// where the next state is built in one MutableFilter while the current state
+// is read from the other, and the two are swapped when the build is done.
+func (mf *MutableFilter) SwapWith(other *MutableFilter) {
+	mf.qf, other.qf = other.qf, mf.qf
+}
+
+// IntSet is a conventional interface for sets of non-negative integers,
+// which MutableFilter implements so that it can be used with code written
+// against such abstractions.
+type IntSet interface {
+	// Contains returns a boolean indicating whether the set contains v.
+	Contains(v int) bool
+	// Insert v into the set.
+	Insert(v int)
+	// Remove v from the set.
+	Remove(v int)
+	// Each calls fn for each value in the set in ascending order until fn
+	// returns false.
+	Each(fn func(v int) bool)
+	// Len returns the number of values in the set.
+	Len() int
+}
+
+var _ IntSet = (*MutableFilter)(nil)
+
+// Contains returns a boolean indicating whether the bit at given index is
+// set. Unlike Has, an index that is out of range is reported as not set.
+func (mf *MutableFilter) Contains(index int) bool {
+	return uint(index) < uint(mf.qf.sourceLen) && mf.qf.Has(index)
+}
+
+// Insert adds an index like Add, but grows the MutableFilter if the index is
+// beyond Cap().
+func (mf *MutableFilter) Insert(index int) {
+	mf.qf = mf.qf.AddGrow(index)
+}
+
+// Remove deletes an index like Delete, but an index that is out of range is
+// ignored.
+func (mf *MutableFilter) Remove(index int) {
+	if uint(index) < uint(mf.qf.sourceLen) {
+		mf.qf = mf.qf.Delete(index)
+	}
+}
+
+// Each calls fn for each stored offset in ascending order until fn returns
+// false.
+func (mf *MutableFilter) Each(fn func(index int) bool) {
+	for it := mf.qf.Iterate(); !it.Done(); it = it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
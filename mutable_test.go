@@ -0,0 +1,129 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestMutableFilter(t *testing.T) {
+	t.Run("Add and Delete", func(t *testing.T) {
+		mf := quickfilter.NewMutableFilter(100)
+		expected := []int{3, 70}
+
+		mf.Add(3)
+		mf.Add(3)
+		mf.Add(50)
+		mf.Add(70)
+		mf.Delete(50)
+		received := collect(mf.QuickFilter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if mf.Len() != len(expected) {
+			t.Errorf("expected %d, got %d", len(expected), mf.Len())
+		}
+	})
+
+	t.Run("Fill and Clear", func(t *testing.T) {
+		mf := quickfilter.NewMutableFilter(100)
+		expectedLen := 0
+
+		mf.Fill()
+		mf.Clear()
+		receivedLen := mf.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("UnionOf and IntersectionOf", func(t *testing.T) {
+		qf1 := quickfilter.New(10).Add(1).Add(2)
+		qf2 := quickfilter.New(10).Add(2).Add(3)
+		mf := quickfilter.MutableFilterOf(quickfilter.New(10))
+		expectedUnion := []int{1, 2, 3}
+		expectedIntersection := []int{2}
+
+		mf.UnionOf(qf1, qf2)
+		receivedUnion := collect(mf.QuickFilter())
+		mf.IntersectionOf(qf1, qf2)
+		receivedIntersection := collect(mf.QuickFilter())
+
+		if !reflect.DeepEqual(expectedUnion, receivedUnion) {
+			t.Errorf("expected %v, got %v", expectedUnion, receivedUnion)
+		}
+		if !reflect.DeepEqual(expectedIntersection, receivedIntersection) {
+			t.Errorf("expected %v, got %v", expectedIntersection, receivedIntersection)
+		}
+	})
+
+	t.Run("Resize and CopyFrom", func(t *testing.T) {
+		mf := quickfilter.NewMutableFilter(10)
+		expected := []int{0, 150}
+
+		mf.Resize(200)
+		mf.CopyFrom(quickfilter.New(200).Add(0).Add(150))
+		received := collect(mf.QuickFilter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("SwapWith", func(t *testing.T) {
+		front := quickfilter.MutableFilterOf(quickfilter.NewFromIndices(10, []int{1}))
+		back := quickfilter.MutableFilterOf(quickfilter.NewFromIndices(20, []int{2, 15}))
+		expectedFront, expectedBack := []int{2, 15}, []int{1}
+
+		front.SwapWith(back)
+		receivedFront, receivedBack := collect(front.QuickFilter()), collect(back.QuickFilter())
+
+		if !reflect.DeepEqual(expectedFront, receivedFront) || front.Cap() != 20 {
+			t.Errorf("expected %v, got %v", expectedFront, receivedFront)
+		}
+		if !reflect.DeepEqual(expectedBack, receivedBack) || back.Cap() != 10 {
+			t.Errorf("expected %v, got %v", expectedBack, receivedBack)
+		}
+	})
+
+	t.Run("IntSet", func(t *testing.T) {
+		var set quickfilter.IntSet = quickfilter.NewMutableFilter(10)
+		expected := []int{3, 500}
+
+		set.Insert(3)
+		set.Insert(500)
+		set.Insert(7)
+		set.Remove(7)
+		set.Remove(10000)
+		received := []int{}
+		set.Each(func(v int) bool {
+			received = append(received, v)
+			return true
+		})
+
+		if !reflect.DeepEqual(expected, received) || set.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if !set.Contains(500) || set.Contains(7) || set.Contains(-1) || set.Contains(10000) {
+			t.Error("unexpected result from Contains")
+		}
+	})
+
+	t.Run("Each stops early", func(t *testing.T) {
+		mf := quickfilter.MutableFilterOf(quickfilter.NewFilled(100))
+		expected := []int{0, 1, 2}
+
+		received := []int{}
+		mf.Each(func(v int) bool {
+			received = append(received, v)
+			return len(received) < 3
+		})
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
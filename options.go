@@ -0,0 +1,65 @@
+package quickfilter
+
+// Option customizes the construction of a QuickFilter in New.
+type Option func(*options)
+
+type options struct {
+	filled       bool
+	buf          []uint
+	maxSourceLen int
+}
+
+// WithFilled makes New return a QuickFilter with all the offsets stored, like
+// NewFilled.
+func WithFilled() Option {
+	return func(o *options) {
+		o.filled = true
+	}
+}
+
+// WithBuffer makes New use buf as the backing buffer of the QuickFilter if
+// it has enough capacity, rather than allocating a new one. This allows
+// reusing the buffer of a QuickFilter that is no longer needed, such as one
+// obtained from a pool. The contents of buf are overwritten, and buf must not
+// be used for anything else afterwards.
+func WithBuffer(buf []uint) Option {
+	return func(o *options) {
+		o.buf = buf
+	}
+}
+
+// WithCapacity makes New reserve a backing buffer large enough for a source
+// length of maxSourceLen, so that later calls to Resize or Grow up to that
+// source length don't allocate.
+func WithCapacity(maxSourceLen int) Option {
+	return func(o *options) {
+		o.maxSourceLen = maxSourceLen
+	}
+}
+
+// newWithOptions is kept separate from New so that the options only escape
+// to the heap when they are used.
+func newWithOptions(sourceLen int, opts []Option) QuickFilter {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	lastIndex, _ := offsets(sourceLen - 1)
+	bitsLen := lastIndex + 1
+	qf := QuickFilter{sourceLen: sourceLen}
+	if cap(o.buf) >= bitsLen {
+		qf.bits = o.buf[:bitsLen]
+		qf = qf.Clear()
+	} else {
+		bitsCap := bitsLen
+		if maxIndex, _ := offsets(o.maxSourceLen - 1); maxIndex+1 > bitsCap {
+			bitsCap = maxIndex + 1
+		}
+		observeAlloc(bitsCap)
+		qf.bits = make([]uint, bitsLen, bitsCap)
+	}
+	if o.filled {
+		qf = qf.Fill()
+	}
+	return qf
+}
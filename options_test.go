@@ -0,0 +1,67 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestOptions(t *testing.T) {
+	t.Run("WithFilled", func(t *testing.T) {
+		expectedLen := 100
+
+		receivedLen := quickfilter.New(100, quickfilter.WithFilled()).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("WithBuffer", func(t *testing.T) {
+		buf := make([]uint, 4)
+		buf[0] = 1
+		expected := []int{3}
+
+		qf := quickfilter.New(100, quickfilter.WithBuffer(buf)).Add(3)
+		received := collect(qf)
+		buf[0] = 0
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if qf.Has(3) {
+			t.Error("expected the QuickFilter to use the passed buffer")
+		}
+	})
+
+	t.Run("WithBuffer too small", func(t *testing.T) {
+		buf := make([]uint, 1)
+		expectedLen := 999
+
+		qf := quickfilter.New(1000, quickfilter.WithBuffer(buf), quickfilter.WithFilled()).Delete(999)
+		receivedLen := qf.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+		if buf[0] != 0 {
+			t.Error("expected the passed buffer not to be used")
+		}
+	})
+
+	t.Run("WithCapacity", func(t *testing.T) {
+		m := &mockInstrumentation{}
+		qf := quickfilter.New(10, quickfilter.WithCapacity(1000))
+		quickfilter.SetInstrumentation(m)
+		defer quickfilter.SetInstrumentation(nil)
+		expectedAllocs := 0
+
+		qf = qf.Resize(1000).Resize(500).Grow(1000)
+		receivedAllocs := len(m.allocs)
+
+		if expectedAllocs != receivedAllocs || qf.Cap() != 1000 {
+			t.Errorf("expected %d, got %d", expectedAllocs, receivedAllocs)
+		}
+	})
+}
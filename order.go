@@ -0,0 +1,146 @@
+package quickfilter
+
+import (
+	"sort"
+)
+
+// SortedBy appends the set indices to dst ordered by less and returns the
+// extended slice, for processing the selection ordered by an external key
+// column without materializing the selected rows, for example:
+//
+//	byPrice := qf.SortedBy(nil, func(i, j int) bool {
+//		return prices[i] < prices[j]
+//	})
+//
+// less is called with two set indices and reports whether the row at the
+// first one sorts before the row at the second one. The sort is stable, so
+// indices with equal keys stay in ascending order. dst is grown at most once
+// to fit exactly Len() more indices.
+func (qf QuickFilter) SortedBy(dst []int, less func(i, j int) bool) []int {
+	start := len(dst)
+	dst = growInts(dst, qf.len)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		dst = append(dst, it.Value())
+	}
+	sorted := dst[start:]
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return less(sorted[a], sorted[b])
+	})
+	return dst
+}
+
+// growInts returns dst with room for exactly n more elements, reallocating
+// it only if needed.
+func growInts(dst []int, n int) []int {
+	if len(dst)+n <= cap(dst) {
+		return dst
+	}
+	grown := make([]int, len(dst), len(dst)+n)
+	copy(grown, dst)
+	return grown
+}
+
+// PriorityIterator iterates over set indices in descending order of
+// priority. It is returned by IterateByPriority.
+type PriorityIterator struct {
+	order []int
+	pos   int
+}
+
+// IterateByPriority returns an iterator over up to limit of the set indices
+// with the highest priorities, in descending order of prio[index], with
+// equal priorities in ascending order of index, for processing the most
+// urgent of the selected items first.
+//
+// The indices are selected in a single pass using a heap bounded by limit,
+// so the cost is O(Len() log limit) rather than that of sorting the whole
+// selection.
+//
+// prio must have a length equal to Cap() or this will panic.
+func (qf QuickFilter) IterateByPriority(prio []int, limit int) PriorityIterator {
+	if len(prio) != qf.sourceLen {
+		panic("prio must be the same size as the QuickFilter")
+	}
+	if limit > qf.len {
+		limit = qf.len
+	}
+	if limit <= 0 {
+		return PriorityIterator{}
+	}
+	// h is a min-heap of the most urgent indices seen so far, with the least
+	// urgent of them at the root.
+	h := priorityHeap{prio: prio, indices: make([]int, 0, limit)}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		index := it.Value()
+		if len(h.indices) < limit {
+			h.push(index)
+		} else if h.less(h.indices[0], index) {
+			h.indices[0] = index
+			h.down(0)
+		}
+	}
+	sort.Slice(h.indices, func(a, b int) bool {
+		return h.less(h.indices[b], h.indices[a])
+	})
+	return PriorityIterator{order: h.indices}
+}
+
+// Done returns a boolean indicating whether the PriorityIterator has been
+// exhausted.
+func (it PriorityIterator) Done() bool {
+	return it.pos >= len(it.order)
+}
+
+// Next returns the PriorityIterator at the next index.
+func (it PriorityIterator) Next() PriorityIterator {
+	it.pos++
+	return it
+}
+
+// Value returns the current index.
+func (it PriorityIterator) Value() int {
+	return it.order[it.pos]
+}
+
+// priorityHeap is a min-heap of indices by urgency.
+type priorityHeap struct {
+	prio    []int
+	indices []int
+}
+
+// less reports whether index i is less urgent than index j.
+func (h *priorityHeap) less(i, j int) bool {
+	if h.prio[i] != h.prio[j] {
+		return h.prio[i] < h.prio[j]
+	}
+	return i > j
+}
+
+func (h *priorityHeap) push(index int) {
+	h.indices = append(h.indices, index)
+	for i := len(h.indices) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.less(h.indices[i], h.indices[parent]) {
+			break
+		}
+		h.indices[i], h.indices[parent] = h.indices[parent], h.indices[i]
+		i = parent
+	}
+}
+
+func (h *priorityHeap) down(i int) {
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(h.indices) && h.less(h.indices[left], h.indices[smallest]) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(h.indices) && h.less(h.indices[right], h.indices[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.indices[i], h.indices[smallest] = h.indices[smallest], h.indices[i]
+		i = smallest
+	}
+}
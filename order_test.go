@@ -0,0 +1,65 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestSortedBy(t *testing.T) {
+	prices := []float64{9.5, 3, 7, 3, 1, 12, 7}
+	qf := quickfilter.New(7).Add(0).Add(1).Add(2).Add(3).Add(6)
+
+	t.Run("should order by the key", func(t *testing.T) {
+		expected := []int{1, 3, 2, 6, 0}
+
+		received := qf.SortedBy(nil, func(i, j int) bool {
+			return prices[i] < prices[j]
+		})
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should allocate exactly", func(t *testing.T) {
+		expected := []int{-1, 0, 2, 6, 1, 3}
+
+		received := qf.SortedBy([]int{-1}, func(i, j int) bool {
+			return prices[i] > prices[j]
+		})
+
+		if !reflect.DeepEqual(expected, received) || cap(received) != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
+
+func TestIterateByPriority(t *testing.T) {
+	prio := []int{5, 1, 9, 5, 7, 0, 9, 2}
+	qf := quickfilter.New(8).Add(0).Add(1).Add(3).Add(4).Add(5).Add(6).Add(7)
+	tests := []struct {
+		name     string
+		limit    int
+		expected []int
+	}{
+		{"limited", 3, []int{6, 4, 0}},
+		{"ties in index order", 4, []int{6, 4, 0, 3}},
+		{"all", 100, []int{6, 4, 0, 3, 7, 1, 5}},
+		{"none", 0, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := []int{}
+
+			for it := qf.IterateByPriority(prio, tt.limit); !it.Done(); it = it.Next() {
+				received = append(received, it.Value())
+			}
+
+			if !reflect.DeepEqual(tt.expected, received) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package quickfilter
+
+// UnionOfPadded fills the QuickFilter with the set values in one or both of
+// the provided QuickFilters, like UnionOf, but the QuickFilters may be of
+// different sizes: the smaller one is treated as if it was padded with unset
+// values, and the receiver is resized to the larger Cap(). This is useful for
+// combining filters computed over a slice that has grown in between.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) UnionOfPadded(qf1, qf2 QuickFilter) QuickFilter {
+	qf = qf.Resize(maxSourceLen(qf1, qf2))
+	for i := range qf.bits {
+		qf.bits[i] = qf1.paddedWord(i) | qf2.paddedWord(i)
+	}
+	return qf.Recount()
+}
+
+// IntersectionOfPadded fills the QuickFilter with the set values in both of
+// the provided QuickFilters, like IntersectionOf, but the QuickFilters may be
+// of different sizes: the smaller one is treated as if it was padded with
+// unset values, and the receiver is resized to the larger Cap().
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) IntersectionOfPadded(qf1, qf2 QuickFilter) QuickFilter {
+	qf = qf.Resize(maxSourceLen(qf1, qf2))
+	for i := range qf.bits {
+		qf.bits[i] = qf1.paddedWord(i) & qf2.paddedWord(i)
+	}
+	return qf.Recount()
+}
+
+// paddedWord returns the word at index i with the bits beyond the source
+// length cleared, or zero if i is beyond the last word.
+func (qf QuickFilter) paddedWord(i int) uint {
+	last := len(qf.bits) - 1
+	switch {
+	case i < last:
+		return qf.bits[i]
+	case i == last:
+		return qf.bits[i] & lastWordMask(qf.sourceLen)
+	default:
+		return 0
+	}
+}
+
+func maxSourceLen(qf1, qf2 QuickFilter) int {
+	if qf1.sourceLen > qf2.sourceLen {
+		return qf1.sourceLen
+	}
+	return qf2.sourceLen
+}
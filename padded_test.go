@@ -0,0 +1,49 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestPadded(t *testing.T) {
+	newSmall := func() quickfilter.QuickFilter {
+		return quickfilter.NewFilled(10)
+	}
+	newLarge := func() quickfilter.QuickFilter {
+		return quickfilter.New(200).Add(5).Add(20).Add(150)
+	}
+
+	t.Run("UnionOfPadded", func(t *testing.T) {
+		expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 20, 150}
+
+		received := quickfilter.New(0).UnionOfPadded(newSmall(), newLarge())
+
+		if !reflect.DeepEqual(expected, collect(received)) || received.Cap() != 200 {
+			t.Errorf("expected %v, got %v", expected, collect(received))
+		}
+	})
+
+	t.Run("IntersectionOfPadded", func(t *testing.T) {
+		expected := []int{5}
+
+		received := quickfilter.New(0).IntersectionOfPadded(newLarge(), newSmall())
+
+		if !reflect.DeepEqual(expected, collect(received)) || received.Cap() != 200 {
+			t.Errorf("expected %v, got %v", expected, collect(received))
+		}
+	})
+
+	t.Run("receiver as operand", func(t *testing.T) {
+		qf := newSmall()
+		expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 20, 150}
+
+		qf = qf.UnionOfPadded(qf, newLarge())
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
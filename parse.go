@@ -0,0 +1,219 @@
+package quickfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes a problem parsing an expression.
+type ParseError struct {
+	// Expr is the expression that was being parsed.
+	Expr string
+	// Offset is the byte offset in Expr where the problem was found.
+	Offset int
+	// Msg describes the problem.
+	Msg string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("quickfilter: parsing %q: %s at offset %d", err.Expr, err.Msg, err.Offset)
+}
+
+// ParseExpr parses a boolean expression over named QuickFilters into an
+// Expr. The names are resolved to QuickFilters using resolve, for example
+// FilterSet.Get.
+//
+// The expression syntax consists of names, `&` for And, `|` for Or, `!` for
+// Not and parentheses for grouping, for example:
+//
+//	active & !archived & (gold | silver)
+//
+// Not binds tighter than And, which binds tighter than Or. Names may contain
+// letters, digits, `_`, `-`, `.` and `:`.
+func ParseExpr(s string, resolve func(name string) (QuickFilter, bool)) (Expr, error) {
+	p := exprParser{s: s, resolve: resolve}
+	expr, err := p.parseOr()
+	if err != nil {
+		return Expr{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return Expr{}, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return expr, nil
+}
+
+// MustParseExpr is like ParseExpr but panics if the expression can't be
+// parsed. It simplifies initialization of expressions in tests and global
+// variables.
+func MustParseExpr(s string, resolve func(name string) (QuickFilter, bool)) Expr {
+	expr, err := ParseExpr(s, resolve)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// ParseRanges parses a comma-separated list of indices and inclusive index
+// ranges into a new QuickFilter of sourceLen offsets, for example:
+//
+//	1-3, 7, 10-12
+//
+// The ranges may be in any order and may overlap. An empty string results in
+// an empty QuickFilter.
+func ParseRanges(sourceLen int, s string) (QuickFilter, error) {
+	qf := New(sourceLen)
+	if strings.TrimSpace(s) == "" {
+		return qf, nil
+	}
+	offset := 0
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, msg := parseRange(strings.TrimSpace(part), sourceLen)
+		if msg != "" {
+			offset += len(part) - len(strings.TrimLeft(part, " \t\n\r"))
+			return QuickFilter{}, &ParseError{Expr: s, Offset: offset, Msg: msg}
+		}
+		qf = qf.addRange(lo, hi+1)
+		offset += len(part) + 1
+	}
+	return qf, nil
+}
+
+// MustParseRanges is like ParseRanges but panics if the ranges can't be
+// parsed. It simplifies initialization of QuickFilters in tests and global
+// variables.
+func MustParseRanges(sourceLen int, s string) QuickFilter {
+	qf, err := ParseRanges(sourceLen, s)
+	if err != nil {
+		panic(err)
+	}
+	return qf
+}
+
+func parseRange(part string, sourceLen int) (lo, hi int, msg string) {
+	loText, hiText := part, part
+	if i := strings.IndexByte(part, '-'); i > 0 {
+		loText, hiText = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+	}
+	lo, err := strconv.Atoi(loText)
+	if err != nil {
+		return 0, 0, fmt.Sprintf("invalid index %q", loText)
+	}
+	hi, err = strconv.Atoi(hiText)
+	if err != nil {
+		return 0, 0, fmt.Sprintf("invalid index %q", hiText)
+	}
+	if lo < 0 || hi >= sourceLen {
+		return 0, 0, fmt.Sprintf("range %q out of range for QuickFilter with Cap() %d", part, sourceLen)
+	}
+	if lo > hi {
+		return 0, 0, fmt.Sprintf("range %q is reversed", part)
+	}
+	return lo, hi, ""
+}
+
+type exprParser struct {
+	s       string
+	pos     int
+	resolve func(name string) (QuickFilter, bool)
+}
+
+func (p *exprParser) parseOr() (Expr, error) {
+	operand, err := p.parseAnd()
+	if err != nil || !p.peek('|') {
+		return operand, err
+	}
+	operands := []Expr{operand}
+	for p.consume('|') {
+		if operand, err = p.parseAnd(); err != nil {
+			return Expr{}, err
+		}
+		operands = append(operands, operand)
+	}
+	return Or(operands...), nil
+}
+
+func (p *exprParser) parseAnd() (Expr, error) {
+	operand, err := p.parseUnary()
+	if err != nil || !p.peek('&') {
+		return operand, err
+	}
+	operands := []Expr{operand}
+	for p.consume('&') {
+		if operand, err = p.parseUnary(); err != nil {
+			return Expr{}, err
+		}
+		operands = append(operands, operand)
+	}
+	return And(operands...), nil
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	switch {
+	case p.consume('!'):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return Expr{}, err
+		}
+		return Not(operand), nil
+	case p.consume('('):
+		expr, err := p.parseOr()
+		if err != nil {
+			return Expr{}, err
+		}
+		if !p.consume(')') {
+			return Expr{}, p.errorf("expected %q", ')')
+		}
+		return expr, nil
+	default:
+		return p.parseName()
+	}
+}
+
+func (p *exprParser) parseName() (Expr, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && isNameByte(p.s[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos == len(p.s) {
+			return Expr{}, p.errorf("unexpected end of expression")
+		}
+		return Expr{}, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	name := p.s[start:p.pos]
+	qf, ok := p.resolve(name)
+	if !ok {
+		p.pos = start
+		return Expr{}, p.errorf("unknown filter %q", name)
+	}
+	return Leaf(qf), nil
+}
+
+func (p *exprParser) peek(c byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+func (p *exprParser) consume(c byte) bool {
+	if !p.peek(c) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Expr: p.s, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-' || c == '.' || c == ':'
+}
@@ -0,0 +1,159 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestParseExpr(t *testing.T) {
+	fs := quickfilter.NewFilterSet(8)
+	fs = fs.Set("active", quickfilter.New(8).Add(0).Add(1).Add(2).Add(3).Add(4).Add(5))
+	fs = fs.Set("archived", quickfilter.New(8).Add(1).Add(6))
+	fs = fs.Set("gold", quickfilter.New(8).Add(0).Add(1).Add(7))
+	fs = fs.Set("silver", quickfilter.New(8).Add(3).Add(6))
+
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			expr     string
+			expected []int
+		}{
+			{"active", []int{0, 1, 2, 3, 4, 5}},
+			{"active & !archived & (gold | silver)", []int{0, 3}},
+			{"gold | silver & archived", []int{0, 1, 6, 7}},
+			{"!!gold", []int{0, 1, 7}},
+			{"!(gold|silver)", []int{2, 4, 5}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.expr, func(t *testing.T) {
+				expr, err := quickfilter.ParseExpr(tt.expr, fs.Get)
+				if err != nil {
+					t.Fatal(err)
+				}
+				received := collect(expr.Eval(quickfilter.New(0)))
+
+				if !reflect.DeepEqual(tt.expected, received) {
+					t.Errorf("expected %v, got %v", tt.expected, received)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			expr           string
+			expectedOffset int
+		}{
+			{"", 0},
+			{"active &", 8},
+			{"(active", 7},
+			{"active bronze", 7},
+			{"active & bronze", 9},
+			{"active & & gold", 9},
+		}
+		for _, tt := range tests {
+			t.Run(tt.expr, func(t *testing.T) {
+				_, err := quickfilter.ParseExpr(tt.expr, fs.Get)
+				parseErr, ok := err.(*quickfilter.ParseError)
+				if !ok {
+					t.Fatalf("expected a *ParseError, got %v", err)
+				}
+
+				if tt.expectedOffset != parseErr.Offset {
+					t.Errorf("expected %d, got %d", tt.expectedOffset, parseErr.Offset)
+				}
+			})
+		}
+	})
+}
+
+func TestMustParseExpr(t *testing.T) {
+	fs := quickfilter.NewFilterSet(8)
+	fs = fs.Set("gold", quickfilter.New(8).Add(0).Add(7))
+
+	t.Run("valid", func(t *testing.T) {
+		expected := []int{1, 2, 3, 4, 5, 6}
+
+		received := collect(quickfilter.MustParseExpr("!gold", fs.Get).Eval(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("invalid should panic", func(t *testing.T) {
+		defer func() {
+			if _, ok := recover().(*quickfilter.ParseError); !ok {
+				t.Error("expected a panic with a ParseError")
+			}
+		}()
+
+		quickfilter.MustParseExpr("silver", fs.Get)
+	})
+}
+
+func TestParseRanges(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			ranges   string
+			expected []int
+		}{
+			{"", []int{}},
+			{"7", []int{7}},
+			{"1-3, 7, 10-12", []int{1, 2, 3, 7, 10, 11, 12}},
+			{"10 - 12,2-3,11-13", []int{2, 3, 10, 11, 12, 13}},
+			{"95-99", []int{95, 96, 97, 98, 99}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.ranges, func(t *testing.T) {
+				qf, err := quickfilter.ParseRanges(100, tt.ranges)
+				if err != nil {
+					t.Fatal(err)
+				}
+				received := collect(qf)
+
+				if !reflect.DeepEqual(tt.expected, received) || qf.Len() != len(tt.expected) {
+					t.Errorf("expected %v, got %v", tt.expected, received)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			ranges         string
+			expectedOffset int
+		}{
+			{"x", 0},
+			{"1-3, 4-", 5},
+			{"1-3,, 5", 4},
+			{"1-3, 100", 5},
+			{"-1", 0},
+			{"5-3", 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.ranges, func(t *testing.T) {
+				_, err := quickfilter.ParseRanges(100, tt.ranges)
+				parseErr, ok := err.(*quickfilter.ParseError)
+				if !ok {
+					t.Fatalf("expected a *ParseError, got %v", err)
+				}
+
+				if tt.expectedOffset != parseErr.Offset {
+					t.Errorf("expected %d, got %d", tt.expectedOffset, parseErr.Offset)
+				}
+			})
+		}
+	})
+
+	t.Run("MustParseRanges invalid should panic", func(t *testing.T) {
+		defer func() {
+			if _, ok := recover().(*quickfilter.ParseError); !ok {
+				t.Error("expected a panic with a ParseError")
+			}
+		}()
+
+		quickfilter.MustParseRanges(10, "5-10")
+	})
+}
@@ -0,0 +1,214 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+const (
+	persistentBlockBits  = 4096 * 8
+	persistentBlockWords = persistentBlockBits / bits.UintSize
+	persistentFanout     = 32
+)
+
+// PersistentFilter is an immutable variant of QuickFilter. Add and Delete
+// return a new PersistentFilter that shares all the unchanged parts of the
+// old one, so keeping many versions of a filter alive only costs the
+// difference between them rather than a full copy of each.
+//
+// The bits are stored in 4KB blocks behind a small tree with a fanout of 32.
+// Blocks and subtrees without any values set are not allocated at all.
+// Changing a value copies one block and the path of tree nodes leading to it.
+type PersistentFilter struct {
+	sourceLen int
+	len       int
+	depth     int
+	root      *persistentNode
+}
+
+type persistentNode struct {
+	children []*persistentNode
+	words    []uint
+}
+
+// NewPersistentFilter returns a new empty PersistentFilter for a source
+// slice of length sourceLen.
+func NewPersistentFilter(sourceLen int) PersistentFilter {
+	blocks := (sourceLen + persistentBlockBits - 1) / persistentBlockBits
+	depth := 0
+	for span := 1; span < blocks; span *= persistentFanout {
+		depth++
+	}
+	return PersistentFilter{sourceLen: sourceLen, depth: depth}
+}
+
+// NewPersistentFilterFrom returns a new PersistentFilter with the same values
+// set as qf.
+func NewPersistentFilterFrom(qf QuickFilter) PersistentFilter {
+	pf := NewPersistentFilter(qf.sourceLen)
+	words := make([]uint, len(qf.bits))
+	copy(words, qf.bits)
+	words[len(words)-1] &= lastWordMask(qf.sourceLen)
+	pf.root = newPersistentNode(pf.depth, words)
+	pf.len = qf.Recount().len
+	return pf
+}
+
+// Len returns the number of offsets stored.
+func (pf PersistentFilter) Len() int {
+	return pf.len
+}
+
+// Cap returns the maximum number of values that can be stored.
+func (pf PersistentFilter) Cap() int {
+	return pf.sourceLen
+}
+
+// Has returns a boolean indicating whether the PersistentFilter has the bit
+// at given index set.
+//
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+func (pf PersistentFilter) Has(index int) bool {
+	if uint(index) >= uint(pf.sourceLen) {
+		panic(&IndexError{Index: index, Cap: pf.sourceLen})
+	}
+	n := pf.root
+	block := index / persistentBlockBits
+	for level := pf.depth; level > 0 && n != nil; level-- {
+		span := persistentSpan(level - 1)
+		n = n.children[block/span]
+		block %= span
+	}
+	if n == nil {
+		return false
+	}
+	wordIndex, mask := offsets(index % persistentBlockBits)
+	return n.words[wordIndex]&mask > 0
+}
+
+// Add returns a new PersistentFilter with the index added. The receiver is
+// left unchanged.
+//
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+func (pf PersistentFilter) Add(index int) PersistentFilter {
+	if pf.Has(index) {
+		return pf
+	}
+	pf.root = pf.root.with(pf.depth, index/persistentBlockBits, index%persistentBlockBits, true)
+	pf.len++
+	return pf
+}
+
+// Delete returns a new PersistentFilter with the index deleted. The receiver
+// is left unchanged.
+//
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+func (pf PersistentFilter) Delete(index int) PersistentFilter {
+	if !pf.Has(index) {
+		return pf
+	}
+	pf.root = pf.root.with(pf.depth, index/persistentBlockBits, index%persistentBlockBits, false)
+	pf.len--
+	return pf
+}
+
+// ToQuickFilter fills dst with the values set in the PersistentFilter. dst is
+// resized to the Cap() of the PersistentFilter.
+func (pf PersistentFilter) ToQuickFilter(dst QuickFilter) QuickFilter {
+	dst = dst.Resize(pf.sourceLen).Clear()
+	pf.root.copyTo(pf.depth, dst.bits)
+	dst.len = pf.len
+	return dst
+}
+
+func newPersistentNode(level int, words []uint) *persistentNode {
+	if len(words) == 0 {
+		return nil
+	}
+	if level == 0 {
+		for _, word := range words {
+			if word != 0 {
+				n := &persistentNode{words: make([]uint, persistentBlockWords)}
+				copy(n.words, words)
+				return n
+			}
+		}
+		return nil
+	}
+	span := persistentSpan(level-1) * persistentBlockWords
+	n := &persistentNode{children: make([]*persistentNode, persistentFanout)}
+	empty := true
+	for i := range n.children {
+		start := i * span
+		if start >= len(words) {
+			break
+		}
+		end := start + span
+		if end > len(words) {
+			end = len(words)
+		}
+		n.children[i] = newPersistentNode(level-1, words[start:end])
+		empty = empty && n.children[i] == nil
+	}
+	if empty {
+		return nil
+	}
+	return n
+}
+
+func (n *persistentNode) with(level, block, bit int, set bool) *persistentNode {
+	if level == 0 {
+		clone := &persistentNode{words: make([]uint, persistentBlockWords)}
+		if n != nil {
+			copy(clone.words, n.words)
+		}
+		wordIndex, mask := offsets(bit)
+		if set {
+			clone.words[wordIndex] |= mask
+		} else {
+			clone.words[wordIndex] &^= mask
+		}
+		return clone
+	}
+	clone := &persistentNode{children: make([]*persistentNode, persistentFanout)}
+	var child *persistentNode
+	span := persistentSpan(level - 1)
+	if n != nil {
+		copy(clone.children, n.children)
+		child = n.children[block/span]
+	}
+	clone.children[block/span] = child.with(level-1, block%span, bit, set)
+	return clone
+}
+
+func (n *persistentNode) copyTo(level int, words []uint) {
+	if n == nil || len(words) == 0 {
+		return
+	}
+	if level == 0 {
+		copy(words, n.words)
+		return
+	}
+	span := persistentSpan(level-1) * persistentBlockWords
+	for i, child := range n.children {
+		start := i * span
+		if start >= len(words) {
+			return
+		}
+		end := start + span
+		if end > len(words) {
+			end = len(words)
+		}
+		child.copyTo(level-1, words[start:end])
+	}
+}
+
+func persistentSpan(level int) int {
+	span := 1
+	for ; level > 0; level-- {
+		span *= persistentFanout
+	}
+	return span
+}
@@ -0,0 +1,78 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestPersistentFilter(t *testing.T) {
+	const sourceLen = 2000000
+
+	t.Run("Add and Delete should not change the receiver", func(t *testing.T) {
+		v1 := quickfilter.NewPersistentFilter(sourceLen).Add(1).Add(1500000)
+		expected := []int{1, 1500000}
+
+		v2 := v1.Add(2).Delete(1500000)
+		v3 := v2.Add(1999999)
+
+		received := collect(v1.ToQuickFilter(quickfilter.New(0)))
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if !v3.Has(1) || !v3.Has(2) || v3.Has(1500000) || !v3.Has(1999999) {
+			t.Errorf("unexpected values %v", collect(v3.ToQuickFilter(quickfilter.New(0))))
+		}
+		if v1.Len() != 2 || v2.Len() != 2 || v3.Len() != 3 {
+			t.Errorf("expected lengths 2, 2 and 3, got %d, %d and %d", v1.Len(), v2.Len(), v3.Len())
+		}
+	})
+
+	t.Run("NewPersistentFilterFrom", func(t *testing.T) {
+		qf := quickfilter.New(sourceLen).Add(0).Add(40000).Add(sourceLen - 1)
+		expected := collect(qf)
+
+		pf := quickfilter.NewPersistentFilterFrom(qf)
+		received := collect(pf.ToQuickFilter(quickfilter.New(0)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if qf.Len() != pf.Len() {
+			t.Errorf("expected %d, got %d", qf.Len(), pf.Len())
+		}
+	})
+
+	t.Run("small", func(t *testing.T) {
+		pf := quickfilter.NewPersistentFilterFrom(quickfilter.NewFilled(10))
+		expectedLen := 10
+
+		receivedLen := pf.ToQuickFilter(quickfilter.New(0)).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+	t.Run("out of range should panic with an IndexError", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fn   func(pf quickfilter.PersistentFilter)
+		}{
+			{"Has", func(pf quickfilter.PersistentFilter) { pf.Has(-1) }},
+			{"Add", func(pf quickfilter.PersistentFilter) { pf.Add(sourceLen) }},
+			{"Delete", func(pf quickfilter.PersistentFilter) { pf.Delete(sourceLen) }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				defer func() {
+					if _, ok := recover().(*quickfilter.IndexError); !ok {
+						t.Error("expected a panic with an IndexError")
+					}
+				}()
+
+				tt.fn(quickfilter.NewPersistentFilter(sourceLen))
+			})
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// TenantPool is a pool of QuickFilter backing buffers shared by multiple
+// tenants, such as the customers of a multi-tenant service. The idle buffers
+// are kept per tenant and bucketed by size class, and their memory is
+// limited both per tenant and globally, so that one busy tenant can't crowd
+// out the buffers of the others.
+//
+// A TenantPool is safe for concurrent use.
+type TenantPool struct {
+	mu           sync.Mutex
+	tenantBudget int
+	globalBudget int
+	bytes        int
+	tenants      map[string]*poolTenant
+	hits         int
+	misses       int
+	evictions    int
+}
+
+type poolTenant struct {
+	bytes int
+	// classes[k] contains the idle buffers with a capacity of at least 2^k
+	// words and less than 2^(k+1) words.
+	classes [][][]uint
+}
+
+// PoolStats contains the occupancy statistics of a TenantPool.
+type PoolStats struct {
+	// Bytes is the memory used by the idle buffers.
+	Bytes int
+	// Buffers is the number of idle buffers.
+	Buffers int
+	// Tenants contains the memory used by the idle buffers of each tenant
+	// that has any.
+	Tenants map[string]int
+	// Hits is the number of calls to Get that reused a buffer.
+	Hits int
+	// Misses is the number of calls to Get that allocated a buffer.
+	Misses int
+	// Evictions is the number of buffers dropped to stay within the budgets.
+	Evictions int
+}
+
+// NewTenantPool returns a new empty TenantPool that keeps at most
+// tenantBudget bytes of idle buffers per tenant and globalBudget bytes in
+// total.
+func NewTenantPool(tenantBudget, globalBudget int) *TenantPool {
+	return &TenantPool{
+		tenantBudget: tenantBudget,
+		globalBudget: globalBudget,
+		tenants:      make(map[string]*poolTenant),
+	}
+}
+
+// Get returns an empty QuickFilter of sourceLen offsets for tenant, reusing
+// an idle buffer of the tenant if one is large enough. Newly allocated
+// buffers are rounded up to a power of two words, so that they fit their
+// size class when returned with Put.
+func (p *TenantPool) Get(tenant string, sourceLen int) QuickFilter {
+	lastIndex, _ := offsets(sourceLen - 1)
+	class := bits.Len(uint(lastIndex))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t := p.tenants[tenant]; t != nil {
+		for k := class; k < len(t.classes); k++ {
+			if n := len(t.classes[k]); n > 0 {
+				buf := t.classes[k][n-1]
+				t.classes[k] = t.classes[k][:n-1]
+				p.release(tenant, t, buf)
+				p.hits++
+				observePoolGet(tenant, true)
+				return New(sourceLen, WithBuffer(buf))
+			}
+		}
+	}
+	p.misses++
+	observePoolGet(tenant, false)
+	return New(sourceLen, WithCapacity(bits.UintSize<<uint(class)))
+}
+
+// Put returns the backing buffer of qf to the idle buffers of tenant,
+// evicting idle buffers of the tenant if it would exceed its budget, and of
+// the tenant with the most idle memory if the pool would exceed the global
+// budget. qf must not be used afterwards.
+func (p *TenantPool) Put(tenant string, qf QuickFilter) {
+	words := cap(qf.bits)
+	if words == 0 {
+		return
+	}
+	size := words * bits.UintSize / 8
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if size > p.tenantBudget || size > p.globalBudget {
+		p.evictions++
+		observePoolEvict(tenant)
+		return
+	}
+	t := p.tenants[tenant]
+	if t == nil {
+		t = &poolTenant{}
+		p.tenants[tenant] = t
+	}
+	for t.bytes+size > p.tenantBudget {
+		p.evict(tenant, t)
+	}
+	for p.bytes+size > p.globalBudget {
+		largest, lt := "", (*poolTenant)(nil)
+		for name, other := range p.tenants {
+			if lt == nil || other.bytes > lt.bytes {
+				largest, lt = name, other
+			}
+		}
+		p.evict(largest, lt)
+	}
+	class := bits.Len(uint(words)) - 1
+	for len(t.classes) <= class {
+		t.classes = append(t.classes, nil)
+	}
+	t.classes[class] = append(t.classes[class], qf.bits[:0])
+	t.bytes += size
+	p.bytes += size
+	p.tenants[tenant] = t
+}
+
+// Stats returns the current occupancy statistics of the TenantPool.
+func (p *TenantPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := PoolStats{
+		Bytes:     p.bytes,
+		Tenants:   make(map[string]int, len(p.tenants)),
+		Hits:      p.hits,
+		Misses:    p.misses,
+		Evictions: p.evictions,
+	}
+	for name, t := range p.tenants {
+		stats.Tenants[name] = t.bytes
+		for _, class := range t.classes {
+			stats.Buffers += len(class)
+		}
+	}
+	return stats
+}
+
+// evict drops an idle buffer of the largest size class of the tenant.
+func (p *TenantPool) evict(tenant string, t *poolTenant) {
+	for k := len(t.classes) - 1; k >= 0; k-- {
+		if n := len(t.classes[k]); n > 0 {
+			buf := t.classes[k][n-1]
+			t.classes[k] = t.classes[k][:n-1]
+			p.release(tenant, t, buf)
+			p.evictions++
+			observePoolEvict(tenant)
+			return
+		}
+	}
+}
+
+// release removes the memory of an idle buffer taken from the tenant from
+// the accounting, forgetting the tenant when it has no idle buffers left.
+func (p *TenantPool) release(tenant string, t *poolTenant, buf []uint) {
+	size := cap(buf) * bits.UintSize / 8
+	t.bytes -= size
+	p.bytes -= size
+	if t.bytes == 0 {
+		delete(p.tenants, tenant)
+	}
+}
@@ -0,0 +1,89 @@
+package quickfilter_test
+
+import (
+	"math/bits"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestTenantPool(t *testing.T) {
+	const wordBytes = bits.UintSize / 8
+
+	t.Run("should reuse buffers of the same tenant", func(t *testing.T) {
+		p := quickfilter.NewTenantPool(1<<20, 1<<20)
+		qf := p.Get("a", 1000).Add(3)
+		p.Put("a", qf)
+
+		qf = p.Get("b", 1000)
+		qf2 := p.Get("a", 900)
+		stats := p.Stats()
+
+		if stats.Hits != 1 || stats.Misses != 2 {
+			t.Errorf("expected 1 hit and 2 misses, got %d and %d", stats.Hits, stats.Misses)
+		}
+		if !qf2.IsEmpty() || qf2.Cap() != 900 {
+			t.Errorf("expected an empty QuickFilter of 900, got %d of %d", qf2.Len(), qf2.Cap())
+		}
+	})
+
+	t.Run("should round allocations up to size classes", func(t *testing.T) {
+		p := quickfilter.NewTenantPool(1<<20, 1<<20)
+		expected := map[string]int{"a": 32 * wordBytes}
+
+		p.Put("a", p.Get("a", 20*bits.UintSize))
+		received := p.Stats().Tenants
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should enforce the tenant budget", func(t *testing.T) {
+		p := quickfilter.NewTenantPool(3*16*wordBytes, 1<<20)
+		for i := 0; i < 5; i++ {
+			p.Put("a", quickfilter.New(16*bits.UintSize))
+		}
+		p.Put("b", quickfilter.New(16*bits.UintSize))
+		expected := quickfilter.PoolStats{
+			Bytes:     4 * 16 * wordBytes,
+			Buffers:   4,
+			Tenants:   map[string]int{"a": 3 * 16 * wordBytes, "b": 16 * wordBytes},
+			Evictions: 2,
+		}
+
+		received := p.Stats()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %+v, got %+v", expected, received)
+		}
+	})
+
+	t.Run("should evict from the largest tenant for the global budget", func(t *testing.T) {
+		p := quickfilter.NewTenantPool(1<<20, 4*16*wordBytes)
+		for i := 0; i < 4; i++ {
+			p.Put("a", quickfilter.New(16*bits.UintSize))
+		}
+		p.Put("b", quickfilter.New(16*bits.UintSize))
+		p.Put("b", quickfilter.New(16*bits.UintSize))
+		expected := map[string]int{"a": 2 * 16 * wordBytes, "b": 2 * 16 * wordBytes}
+
+		received := p.Stats().Tenants
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should drop buffers larger than the budget", func(t *testing.T) {
+		p := quickfilter.NewTenantPool(wordBytes, 1<<20)
+
+		p.Put("a", quickfilter.New(2*bits.UintSize))
+		stats := p.Stats()
+
+		if stats.Buffers != 0 || stats.Evictions != 1 {
+			t.Errorf("expected the buffer to be dropped, got %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package quickfilter
+
+import (
+	"math"
+	"math/bits"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AddWhereMatch adds the indices of the strings in src that match re to qf.
+// The matches are collected a word at a time, so that each word of qf is
+// written once.
+//
+// src must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereMatch(qf QuickFilter, src []string, re *regexp.Regexp) QuickFilter {
+	mustColumn(qf, len(src))
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return re.MatchString(src[i])
+	})
+}
+
+// AddWhereMatchParallel is like AddWhereMatch, but matches the strings in
+// parallel on up to GOMAXPROCS goroutines, each handling a contiguous range
+// of words of qf. This pays off for long columns or expensive regular
+// expressions.
+//
+// src must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereMatchParallel(qf QuickFilter, src []string, re *regexp.Regexp) QuickFilter {
+	mustColumn(qf, len(src))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(qf.bits) {
+		workers = len(qf.bits)
+	}
+	if workers == 0 {
+		return qf
+	}
+	wordsPerWorker := (len(qf.bits) + workers - 1) / workers
+	added := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := w*wordsPerWorker, (w+1)*wordsPerWorker
+		if hi > len(qf.bits) {
+			hi = len(qf.bits)
+		}
+		wg.Add(1)
+		go func(w, lo, hi int) {
+			defer wg.Done()
+			part := QuickFilter{sourceLen: qf.sourceLen, bits: qf.bits}
+			added[w] = part.addWhereWords(lo, hi, func(i int) bool {
+				return re.MatchString(src[i])
+			}).len
+		}(w, lo, hi)
+	}
+	wg.Wait()
+	for _, n := range added {
+		qf.len += n
+	}
+	return qf
+}
+
+// Collator maps a string to its collation key, so that strings that are
+// considered equal in a locale map to the same key. strings.ToLower is a
+// simple, locale-independent Collator; locale-aware ones can be built on a
+// collation library, for example by wrapping the Key method of
+// golang.org/x/text/collate.
+type Collator func(s string) string
+
+// AddWhereEqualFold adds the indices of the strings in src that are equal to
+// s under Unicode case-folding to qf.
+//
+// src must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereEqualFold(qf QuickFilter, src []string, s string) QuickFilter {
+	mustColumn(qf, len(src))
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return strings.EqualFold(src[i], s)
+	})
+}
+
+// AddWhereHasPrefix adds the indices of the strings in src whose collation
+// key under collate begins with that of prefix to qf. The key of each string
+// is computed as it is visited, so the column doesn't need to be normalized
+// up front. If collate is nil, the strings are compared as is.
+//
+// src must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereHasPrefix(qf QuickFilter, src []string, prefix string, collate Collator) QuickFilter {
+	mustColumn(qf, len(src))
+	collate = orIdentity(collate)
+	prefix = collate(prefix)
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return strings.HasPrefix(collate(src[i]), prefix)
+	})
+}
+
+// AddWhereContains adds the indices of the strings in src whose collation
+// key under collate contains that of substr to qf. If collate is nil, the
+// strings are compared as is.
+//
+// src must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereContains(qf QuickFilter, src []string, substr string, collate Collator) QuickFilter {
+	mustColumn(qf, len(src))
+	collate = orIdentity(collate)
+	substr = collate(substr)
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return strings.Contains(collate(src[i]), substr)
+	})
+}
+
+func orIdentity(collate Collator) Collator {
+	if collate == nil {
+		return func(s string) string { return s }
+	}
+	return collate
+}
+
+// AddWhereTimeBetween adds the indices of the times in col that are in the
+// window from from (inclusive) to to (exclusive) to qf.
+//
+// col must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereTimeBetween(qf QuickFilter, col []time.Time, from, to time.Time) QuickFilter {
+	mustColumn(qf, len(col))
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return !col[i].Before(from) && col[i].Before(to)
+	})
+}
+
+// AddWhereTimeBetweenSorted is like AddWhereTimeBetween, but for columns
+// whose times are non-decreasing, such as event logs. The bounds of the
+// window are found by binary search and the indices between them are added
+// a word at a time, so the cost is O(log n) plus the number of words in the
+// window. The result is undefined if the column isn't sorted.
+//
+// col must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereTimeBetweenSorted(qf QuickFilter, col []time.Time, from, to time.Time) QuickFilter {
+	mustColumn(qf, len(col))
+	lo := sort.Search(len(col), func(i int) bool { return !col[i].Before(from) })
+	hi := sort.Search(len(col), func(i int) bool { return !col[i].Before(to) })
+	return qf.addRange(lo, hi)
+}
+
+// AddWhereTimeBefore adds the indices of the times in col that are before t
+// to qf.
+//
+// col must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereTimeBefore(qf QuickFilter, col []time.Time, t time.Time) QuickFilter {
+	mustColumn(qf, len(col))
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return col[i].Before(t)
+	})
+}
+
+// AddWhereTimeSince adds the indices of the times in col that are at or
+// after t to qf.
+//
+// col must have a length equal to Cap() or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereTimeSince(qf QuickFilter, col []time.Time, t time.Time) QuickFilter {
+	mustColumn(qf, len(col))
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		return !col[i].Before(t)
+	})
+}
+
+// AddWhereTopPercentile adds the indices of the values in col that fall in
+// the top p fraction of the column to qf, for example the top 5% with p =
+// 0.05. The number of indices selected is p*Cap() rounded up, with ties at
+// the boundary broken in favor of lower indices.
+//
+// The boundary value is found with a single quickselect pass over a copy of
+// the column, so the cost is O(n) on average rather than that of sorting it.
+//
+// col must have a length equal to Cap() and must not contain NaNs, and p must
+// be between 0 and 1, or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func AddWhereTopPercentile(qf QuickFilter, col []float64, p float64) QuickFilter {
+	mustColumn(qf, len(col))
+	if !(p >= 0 && p <= 1) {
+		panic("p must be between 0 and 1")
+	}
+	for _, v := range col {
+		if math.IsNaN(v) {
+			panic("col must not contain NaNs")
+		}
+	}
+	k := int(math.Ceil(p * float64(len(col))))
+	if k == 0 {
+		return qf
+	}
+	scratch := append([]float64(nil), col...)
+	threshold := quickselect(scratch, len(scratch)-k)
+	// Everything above the threshold is in the top k, so the remaining room
+	// goes to the ties.
+	ties := k
+	for _, v := range scratch[len(scratch)-k:] {
+		if v > threshold {
+			ties--
+		}
+	}
+	return qf.addWhereWords(0, len(qf.bits), func(i int) bool {
+		if col[i] > threshold {
+			return true
+		}
+		if col[i] == threshold && ties > 0 {
+			ties--
+			return true
+		}
+		return false
+	})
+}
+
+// quickselect partially sorts a so that a[k] holds the value it would have
+// if a was sorted, with no greater values before it and no smaller ones
+// after it, and returns a[k].
+func quickselect(a []float64, k int) float64 {
+	lo, hi := 0, len(a)-1
+	for lo < hi {
+		// Median of three, moved to a[lo] as the pivot.
+		mid := lo + (hi-lo)/2
+		if a[mid] < a[lo] {
+			a[mid], a[lo] = a[lo], a[mid]
+		}
+		if a[hi] < a[lo] {
+			a[hi], a[lo] = a[lo], a[hi]
+		}
+		if a[hi] < a[mid] {
+			a[hi], a[mid] = a[mid], a[hi]
+		}
+		a[lo], a[mid] = a[mid], a[lo]
+		pivot := a[lo]
+		// Hoare partition: a[lo:j+1] <= pivot <= a[j+1:hi+1].
+		i, j := lo-1, hi+1
+		for {
+			for i++; a[i] < pivot; i++ {
+			}
+			for j--; a[j] > pivot; j-- {
+			}
+			if i >= j {
+				break
+			}
+			a[i], a[j] = a[j], a[i]
+		}
+		if k <= j {
+			hi = j
+		} else {
+			lo = j + 1
+		}
+	}
+	return a[k]
+}
+
+// addWhereWords adds the indices in the words from lo (inclusive) to hi
+// (exclusive) for which pred returns true, building each word before
+// writing it.
+func (qf QuickFilter) addWhereWords(lo, hi int, pred func(i int) bool) QuickFilter {
+	for w := lo; w < hi; w++ {
+		base := w * bits.UintSize
+		end := base + bits.UintSize
+		if end > qf.sourceLen {
+			end = qf.sourceLen
+		}
+		var word uint
+		for i := base; i < end; i++ {
+			if pred(i) {
+				word |= 1 << uint(i-base)
+			}
+		}
+		qf.len += bits.OnesCount(word &^ qf.bits[w])
+		qf.bits[w] |= word
+	}
+	return qf
+}
+
+func mustColumn(qf QuickFilter, n int) {
+	if n != qf.sourceLen {
+		panic("column must be the same size as the QuickFilter")
+	}
+}
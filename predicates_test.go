@@ -0,0 +1,224 @@
+package quickfilter_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestAddWhereMatch(t *testing.T) {
+	src := make([]string, 1000)
+	expected := []int{}
+	for i := range src {
+		src[i] = fmt.Sprintf("item-%d", i)
+		if i%7 == 3 || i == 5 {
+			src[i] = fmt.Sprintf("error-%d", i)
+			expected = append(expected, i)
+		}
+	}
+	re := regexp.MustCompile(`^error-\d+$`)
+	tests := []struct {
+		name string
+		fn   func(qf quickfilter.QuickFilter, src []string, re *regexp.Regexp) quickfilter.QuickFilter
+	}{
+		{"AddWhereMatch", quickfilter.AddWhereMatch},
+		{"AddWhereMatchParallel", quickfilter.AddWhereMatchParallel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qf := tt.fn(quickfilter.New(len(src)).Add(5), src, re)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+			if err := qf.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.AddWhereMatch(quickfilter.New(3), src, re)
+	})
+}
+
+func TestAddWhereCollated(t *testing.T) {
+	src := []string{"Straße", "STRASSE", "strand", "Ölstraße", "straw"}
+	tests := []struct {
+		name     string
+		fn       func(qf quickfilter.QuickFilter) quickfilter.QuickFilter
+		expected []int
+	}{
+		{"AddWhereEqualFold", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereEqualFold(qf, src, "strasse")
+		}, []int{1}},
+		{"AddWhereHasPrefix", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereHasPrefix(qf, src, "STRA", strings.ToLower)
+		}, []int{0, 1, 2, 4}},
+		{"AddWhereHasPrefix without collator", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereHasPrefix(qf, src, "Stra", nil)
+		}, []int{0}},
+		{"AddWhereContains", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereContains(qf, src, "SS", func(s string) string {
+				return strings.Replace(strings.ToLower(s), "ß", "ss", -1)
+			})
+		}, []int{0, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := collect(tt.fn(quickfilter.New(len(src))))
+
+			if !reflect.DeepEqual(tt.expected, received) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+}
+
+func TestAddWhereTopPercentile(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, p := range []float64{0, 0.01, 0.1, 0.5, 0.99, 1} {
+		t.Run(fmt.Sprint(p), func(t *testing.T) {
+			col := make([]float64, 1000)
+			for i := range col {
+				col[i] = float64(rng.Intn(100))
+			}
+			k := int(math.Ceil(p * float64(len(col))))
+			order := make([]int, len(col))
+			for i := range order {
+				order[i] = i
+			}
+			sort.SliceStable(order, func(a, b int) bool {
+				return col[order[a]] > col[order[b]]
+			})
+			expected := append([]int{}, order[:k]...)
+			sort.Ints(expected)
+
+			received := collect(quickfilter.AddWhereTopPercentile(quickfilter.New(len(col)), col, p))
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+	}
+
+	t.Run("invalid p should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.AddWhereTopPercentile(quickfilter.New(1), []float64{1}, 1.5)
+	})
+
+	t.Run("NaN should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.AddWhereTopPercentile(quickfilter.New(4), []float64{1, math.NaN(), 3, 2}, 0.5)
+	})
+}
+
+func TestAddWhereTime(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	col := make([]time.Time, 200)
+	for i := range col {
+		col[i] = base.Add(time.Duration(i/2) * time.Hour)
+	}
+	from, to := base.Add(10*time.Hour), base.Add(80*time.Hour)
+	indicesFrom := func(lo, hi int) []int {
+		indices := []int{}
+		for i := lo; i < hi; i++ {
+			indices = append(indices, i)
+		}
+		return indices
+	}
+	tests := []struct {
+		name     string
+		fn       func(qf quickfilter.QuickFilter) quickfilter.QuickFilter
+		expected []int
+	}{
+		{"AddWhereTimeBetween", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereTimeBetween(qf, col, from, to)
+		}, indicesFrom(20, 160)},
+		{"AddWhereTimeBetweenSorted", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereTimeBetweenSorted(qf, col, from, to)
+		}, indicesFrom(20, 160)},
+		{"AddWhereTimeBetweenSorted empty", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereTimeBetweenSorted(qf, col, to, from)
+		}, []int{}},
+		{"AddWhereTimeBefore", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereTimeBefore(qf, col, from)
+		}, indicesFrom(0, 20)},
+		{"AddWhereTimeSince", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter {
+			return quickfilter.AddWhereTimeSince(qf, col, to)
+		}, indicesFrom(160, 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qf := tt.fn(quickfilter.New(len(col)))
+			received := collect(qf)
+
+			if !reflect.DeepEqual(tt.expected, received) || qf.Len() != len(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+}
+
+func BenchmarkAddWhereMatch(b *testing.B) {
+	const size = 100000
+	src := make([]string, size)
+	for i := range src {
+		src[i] = fmt.Sprintf("2020-01-%02d INFO request %d served", i%28+1, i)
+		if i%10 == 0 {
+			src[i] = fmt.Sprintf("2020-01-%02d ERROR request %d failed", i%28+1, i)
+		}
+	}
+	re := regexp.MustCompile(`ERROR .* failed`)
+
+	b.Run("Add", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			qf := quickfilter.New(size)
+			for i := range src {
+				if re.MatchString(src[i]) {
+					qf = qf.Add(i)
+				}
+			}
+		}
+	})
+
+	b.Run("AddWhereMatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			quickfilter.AddWhereMatch(quickfilter.New(size), src, re)
+		}
+	})
+
+	b.Run("AddWhereMatchParallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			quickfilter.AddWhereMatchParallel(quickfilter.New(size), src, re)
+		}
+	})
+}
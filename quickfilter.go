@@ -30,6 +30,7 @@ package quickfilter
 
 import (
 	"math/bits"
+	"time"
 )
 
 // QuickFilter is a utility module that stores offsets and allows you to
@@ -41,12 +42,20 @@ type QuickFilter struct {
 }
 
 // New returns a new QuickFilter with enough space reserved to store sourceLen
-// offsets.
+// offsets. A sourceLen of zero is valid and results in a QuickFilter that
+// can't store any offsets. The construction can be customized with options,
+// for example:
+//
+//	qf := quickfilter.New(len(items), quickfilter.WithFilled(), quickfilter.WithBuffer(buf))
 //
 // In a filtering operation, sourceLen should be the len() of the original
 // slice.
-func New(sourceLen int) QuickFilter {
+func New(sourceLen int, opts ...Option) QuickFilter {
+	if len(opts) > 0 {
+		return newWithOptions(sourceLen, opts)
+	}
 	lastIndex, _ := offsets(sourceLen - 1)
+	observeAlloc(lastIndex + 1)
 	return QuickFilter{
 		sourceLen: sourceLen,
 		bits:      make([]uint, lastIndex+1),
@@ -61,24 +70,135 @@ func NewFilled(sourceLen int) QuickFilter {
 	return New(sourceLen).Fill()
 }
 
-// Add an index to the offset list.
+// NewFromFunc returns a new QuickFilter of sourceLen offsets where the
+// offsets for which pred returns true are stored. The bits are assembled a
+// word at a time, so this is faster than calling Add for each index.
+func NewFromFunc(sourceLen int, pred func(i int) bool) QuickFilter {
+	qf := New(sourceLen)
+	for wordIndex := range qf.bits {
+		base := wordIndex * bits.UintSize
+		end := base + bits.UintSize
+		if end > sourceLen {
+			end = sourceLen
+		}
+		var word uint
+		for i := base; i < end; i++ {
+			if pred(i) {
+				word |= 1 << uint(i-base)
+			}
+		}
+		qf.bits[wordIndex] = word
+		qf.len += bits.OnesCount(word)
+	}
+	qf.debugValidate("NewFromFunc")
+	return qf
+}
+
+// NewFromIndices returns a new QuickFilter of sourceLen offsets where the
+// given indices are stored.
+//
+// The indices must be at least zero and less than sourceLen or this will
+// panic with an *IndexError.
+func NewFromIndices(sourceLen int, indices []int) QuickFilter {
+	return New(sourceLen).AddMany(indices)
+}
+
+// NewRange returns a new QuickFilter of sourceLen offsets where the offsets
+// from lo (inclusive) to hi (exclusive) are stored, set a word at a time.
+//
+// lo and hi must satisfy 0 <= lo <= hi <= sourceLen or this will panic.
+func NewRange(sourceLen, lo, hi int) QuickFilter {
+	if lo < 0 || lo > hi || hi > sourceLen {
+		panic("range must be within the source length")
+	}
+	qf := New(sourceLen).addRange(lo, hi)
+	qf.debugValidate("NewRange")
+	return qf
+}
+
+// NewEveryNth returns a new QuickFilter of sourceLen offsets where every nth
+// offset starting from offset is stored, such as for downsampling. When n
+// divides the word size, the words are filled with a precomputed pattern.
+//
+// n must be at least 1 and offset must be at least 0 or this will panic.
+func NewEveryNth(sourceLen, n, offset int) QuickFilter {
+	if n < 1 || offset < 0 {
+		panic("n must be at least 1 and offset must be at least 0")
+	}
+	qf := New(sourceLen)
+	if offset >= sourceLen {
+		return qf
+	}
+	if bits.UintSize%n != 0 {
+		for i := offset; i < sourceLen; i += n {
+			index, mask := offsets(i)
+			qf.bits[index] |= mask
+			qf.len++
+		}
+		qf.debugValidate("NewEveryNth")
+		return qf
+	}
+	var pattern uint
+	for i := offset % n; i < bits.UintSize; i += n {
+		pattern |= 1 << uint(i)
+	}
+	for i := range qf.bits {
+		qf.bits[i] = pattern
+	}
+	qf.bits[len(qf.bits)-1] &= lastWordMask(sourceLen)
+	qf = qf.Recount().deleteRange(0, offset)
+	qf.debugValidate("NewEveryNth")
+	return qf
+}
+
+// Add an index to the offset list. Adding an index that is already set has
+// no effect.
+//
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
 //
 // The original QuickFilter is no longer usable and must be replaced with the
 // returned one. This approach prevents the QuickFilter from escaping to the
 // heap.
 func (qf QuickFilter) Add(index int) QuickFilter {
+	qf.mustIndex(index)
 	index, mask := offsets(index)
+	if qf.bits[index]&mask != 0 {
+		return qf
+	}
 	qf.bits[index] |= mask
 	qf.len++
+	qf.debugValidate("Add")
 	return qf
 }
 
+// AddGrow adds an index to the offset list like Add, but if the index is
+// beyond Cap(), the QuickFilter is first grown to a source length of
+// index+1, keeping its set values. The backing buffer grows geometrically,
+// so a producer that keeps exceeding its estimated source length doesn't
+// reallocate on every call. Adding an index that is already set has no
+// effect.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AddGrow(index int) QuickFilter {
+	if index >= qf.sourceLen {
+		qf = qf.Grow(index + 1)
+	}
+	return qf.Add(index)
+}
+
 // Delete an index from the offset list.
 //
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
 // The original QuickFilter is no longer usable and must be replaced with the
 // returned one. This approach prevents the QuickFilter from escaping to the
 // heap.
 func (qf QuickFilter) Delete(index int) QuickFilter {
+	qf.mustIndex(index)
 	index, mask := offsets(index)
 	oldValue := qf.bits[index]
 	if oldValue&mask == 0 {
@@ -86,6 +206,111 @@ func (qf QuickFilter) Delete(index int) QuickFilter {
 	}
 	qf.bits[index] = oldValue ^ mask
 	qf.len--
+	qf.debugValidate("Delete")
+	return qf
+}
+
+// AddMany adds the indices to the offset list. The indices are all checked
+// before any are added, so an invalid index leaves the QuickFilter
+// unchanged. Adding an index that is already set has no effect.
+//
+// The indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AddMany(indices []int) QuickFilter {
+	for _, index := range indices {
+		qf.mustIndex(index)
+	}
+	added := 0
+	for _, index := range indices {
+		index, mask := offsets(index)
+		if qf.bits[index]&mask == 0 {
+			qf.bits[index] |= mask
+			added++
+		}
+	}
+	qf.len += added
+	qf.debugValidate("AddMany")
+	return qf
+}
+
+// DeleteMany deletes the indices from the offset list. The indices are all
+// checked before any are deleted, so an invalid index leaves the QuickFilter
+// unchanged.
+//
+// The indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) DeleteMany(indices []int) QuickFilter {
+	for _, index := range indices {
+		qf.mustIndex(index)
+	}
+	deleted := 0
+	for _, index := range indices {
+		index, mask := offsets(index)
+		if qf.bits[index]&mask != 0 {
+			qf.bits[index] &^= mask
+			deleted++
+		}
+	}
+	qf.len -= deleted
+	qf.debugValidate("DeleteMany")
+	return qf
+}
+
+// MoveBit sets the bit at index to to the bit at index from, and clears the
+// bit at index from. This keeps a QuickFilter consistent with its source
+// slice when an element is moved, such as in the delete-by-swap idiom:
+//
+//	items[i] = items[len(items)-1]
+//	items = items[:len(items)-1]
+//	qf = qf.MoveBit(len(items), i)
+//
+// Both indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) MoveBit(from, to int) QuickFilter {
+	qf.mustIndex(to)
+	if from == to {
+		qf.mustIndex(from)
+		return qf
+	}
+	set := qf.Has(from)
+	qf = qf.Delete(from).Delete(to)
+	if set {
+		qf = qf.Add(to)
+	}
+	qf.debugValidate("MoveBit")
+	return qf
+}
+
+// SwapBits swaps the bits at indices i and j, keeping a QuickFilter
+// consistent with its source slice when two elements are swapped.
+//
+// Both indices must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) SwapBits(i, j int) QuickFilter {
+	if qf.Has(i) == qf.Has(j) {
+		return qf
+	}
+	iIndex, iMask := offsets(i)
+	jIndex, jMask := offsets(j)
+	qf.bits[iIndex] ^= iMask
+	qf.bits[jIndex] ^= jMask
+	qf.debugValidate("SwapBits")
 	return qf
 }
 
@@ -94,11 +319,53 @@ func (qf QuickFilter) Len() int {
 	return qf.len
 }
 
+// Recount recomputes Len() by counting the set bits, ignoring any bits
+// beyond the source length. This restores the invariant of Len() matching the
+// set values after the bits have been modified by other means than the
+// methods of QuickFilter.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) Recount() QuickFilter {
+	qf.len = 0
+	for i := range qf.bits[:len(qf.bits)-1] {
+		qf.len += bits.OnesCount(qf.bits[i])
+	}
+	qf.len += onesCountLastWord(qf.bits[len(qf.bits)-1], qf.sourceLen)
+	return qf
+}
+
 // Cap returns the maximum number of values that can be stored.
 func (qf QuickFilter) Cap() int {
 	return qf.sourceLen
 }
 
+// TrailingMask returns a mask of the bits of the last word of the backing
+// buffer that are within Cap(). Every method of QuickFilter that writes whole
+// words clears the bits outside of the mask, and code that operates on the
+// words directly should do the same.
+func (qf QuickFilter) TrailingMask() uint {
+	return lastWordMask(qf.sourceLen)
+}
+
+// UsedBitsInLastWord returns the number of bits of the last word of the
+// backing buffer that are within Cap().
+func (qf QuickFilter) UsedBitsInLastWord() int {
+	return bits.OnesCount(lastWordMask(qf.sourceLen))
+}
+
+// IsEmpty returns a boolean indicating whether no offsets are stored.
+func (qf QuickFilter) IsEmpty() bool {
+	return qf.len == 0
+}
+
+// IsFull returns a boolean indicating whether all the offsets up to Cap() are
+// stored. A QuickFilter with a Cap() of zero is both empty and full.
+func (qf QuickFilter) IsFull() bool {
+	return qf.len == qf.sourceLen
+}
+
 // Clear the entries in the QuickFilter.
 //
 // The original QuickFilter is no longer usable and must be replaced with the
@@ -109,6 +376,7 @@ func (qf QuickFilter) Clear() QuickFilter {
 		qf.bits[i] = 0
 	}
 	qf.len = 0
+	qf.debugValidate("Clear")
 	return qf
 }
 
@@ -121,7 +389,9 @@ func (qf QuickFilter) Fill() QuickFilter {
 	for i := 0; i < len(qf.bits); i++ {
 		qf.bits[i] = ^uint(0)
 	}
+	qf.bits[len(qf.bits)-1] = lastWordMask(qf.sourceLen)
 	qf.len = qf.sourceLen
+	qf.debugValidate("Fill")
 	return qf
 }
 
@@ -142,11 +412,49 @@ func (qf QuickFilter) CopyFrom(qf2 QuickFilter) QuickFilter {
 	qf = qf.Resize(qf2.Cap())
 	qf.len = qf2.len
 	copy(qf.bits, qf2.bits)
+	qf.debugValidate("CopyFrom")
+	return qf
+}
+
+// Remap a QuickFilter through a permutation to a new QuickFilter, where
+// perm[i] is set if i is set in the original. This allows carrying a filter
+// computed on a slice through a subsequent reordering of the slice, such as a
+// sort, without recomputing the predicates.
+func (qf QuickFilter) Remap(perm []int) QuickFilter {
+	return New(qf.Cap()).RemapFrom(qf, perm)
+}
+
+// RemapFrom fills the QuickFilter with the values of qf2 remapped through a
+// permutation, where perm[i] is set if i is set in qf2.
+//
+// If the two QuickFilters are not of the same Cap(), the receiver will be
+// resized. The receiver must not share its backing buffer with qf2, and perm
+// must have a length equal to the Cap() of qf2 or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) RemapFrom(qf2 QuickFilter, perm []int) QuickFilter {
+	if len(perm) != qf2.sourceLen {
+		panic("perm must be the same size as the QuickFilter")
+	}
+	qf = qf.Resize(qf2.Cap()).Clear()
+	for it := qf2.Iterate(); !it.Done(); it = it.Next() {
+		index, mask := offsets(perm[it.Value()])
+		if qf.bits[index]&mask == 0 {
+			qf.bits[index] |= mask
+			qf.len++
+		}
+	}
+	qf.debugValidate("RemapFrom")
 	return qf
 }
 
 // Resize a QuickFilter to a new source length. Will allocate a new backing
-// buffer if the source length won't fit in the old one.
+// buffer if the source length won't fit in the old one. When the old buffer
+// is reused, the values it holds below the new source length are kept,
+// including any stale ones from a previous use of the buffer, and Len() is
+// recomputed; use Clear to start from an empty QuickFilter.
 //
 // The original QuickFilter is no longer usable and must be replaced with the
 // returned one. This approach prevents the QuickFilter from escaping to the
@@ -154,12 +462,84 @@ func (qf QuickFilter) CopyFrom(qf2 QuickFilter) QuickFilter {
 func (qf QuickFilter) Resize(sourceLen int) QuickFilter {
 	lastIndex, _ := offsets(sourceLen - 1)
 	bitsLen := lastIndex + 1
+	observeResize(qf.sourceLen, sourceLen)
 	qf.sourceLen = sourceLen
 	if cap(qf.bits) < bitsLen {
+		observeAlloc(bitsLen)
 		qf.bits = make([]uint, bitsLen)
+		qf.len = 0
 	} else {
 		qf.bits = qf.bits[:bitsLen]
+		qf.bits[lastIndex] &= lastWordMask(sourceLen)
+		qf = qf.Recount()
 	}
+	qf.debugValidate("Resize")
+	return qf
+}
+
+// Grow a QuickFilter to a larger source length, keeping its set values and
+// Len(). Unlike Resize, any words of the backing buffer that are reused for
+// the new offsets are zeroed, so no stale values from a previous use of the
+// buffer are exposed. If a new backing buffer is needed, it is allocated with
+// at least double the capacity. A source length not larger than Cap() leaves
+// the QuickFilter unchanged.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) Grow(sourceLen int) QuickFilter {
+	if sourceLen <= qf.sourceLen {
+		return qf
+	}
+	lastIndex, _ := offsets(sourceLen - 1)
+	bitsLen := lastIndex + 1
+	oldLen := len(qf.bits)
+	if oldLen > 0 {
+		qf.bits[oldLen-1] &= lastWordMask(qf.sourceLen)
+	}
+	observeResize(qf.sourceLen, sourceLen)
+	if cap(qf.bits) < bitsLen {
+		bitsCap := 2 * cap(qf.bits)
+		if bitsCap < bitsLen {
+			bitsCap = bitsLen
+		}
+		observeAlloc(bitsCap)
+		bits := make([]uint, bitsLen, bitsCap)
+		copy(bits, qf.bits)
+		qf.bits = bits
+	} else {
+		qf.bits = qf.bits[:bitsLen]
+		for i := oldLen; i < bitsLen; i++ {
+			qf.bits[i] = 0
+		}
+	}
+	qf.sourceLen = sourceLen
+	qf.debugValidate("Grow")
+	return qf
+}
+
+// Truncate a QuickFilter to a smaller source length, clearing the values
+// beyond it and recomputing Len(). Unlike Resize, the backing buffer is kept
+// even if its capacity doesn't change. A source length not smaller than
+// Cap() leaves the QuickFilter unchanged.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) Truncate(sourceLen int) QuickFilter {
+	if sourceLen >= qf.sourceLen {
+		return qf
+	}
+	if sourceLen < 0 {
+		sourceLen = 0
+	}
+	observeResize(qf.sourceLen, sourceLen)
+	lastIndex, _ := offsets(sourceLen - 1)
+	qf.bits = qf.bits[:lastIndex+1]
+	qf.bits[lastIndex] &= lastWordMask(sourceLen)
+	qf.sourceLen = sourceLen
+	qf = qf.Recount()
+	qf.debugValidate("Truncate")
 	return qf
 }
 
@@ -173,6 +553,9 @@ func (qf QuickFilter) Resize(sourceLen int) QuickFilter {
 // returned one. This approach prevents the QuickFilter from escaping to the
 // heap.
 func (qf QuickFilter) UnionOf(qf1, qf2 QuickFilter) QuickFilter {
+	if instrumentation != nil {
+		defer observeSetOp("UnionOf", time.Now())
+	}
 	if len(qf.bits) != len(qf1.bits) || len(qf.bits) != len(qf2.bits) {
 		panic("receiver and passed QuickFilters must be the same size")
 	}
@@ -183,9 +566,10 @@ func (qf QuickFilter) UnionOf(qf1, qf2 QuickFilter) QuickFilter {
 	}
 
 	i := len(qf.bits) - 1
-	qf.bits[i] = qf1.bits[i] | qf2.bits[i]
-	qf.len += onesCountLastWord(qf.bits[i], qf.sourceLen)
+	qf.bits[i] = (qf1.bits[i] | qf2.bits[i]) & lastWordMask(qf.sourceLen)
+	qf.len += bits.OnesCount(qf.bits[i])
 
+	qf.debugValidate("UnionOf")
 	return qf
 }
 
@@ -199,6 +583,9 @@ func (qf QuickFilter) UnionOf(qf1, qf2 QuickFilter) QuickFilter {
 // returned one. This approach prevents the QuickFilter from escaping to the
 // heap.
 func (qf QuickFilter) IntersectionOf(qf1, qf2 QuickFilter) QuickFilter {
+	if instrumentation != nil {
+		defer observeSetOp("IntersectionOf", time.Now())
+	}
 	if len(qf.bits) != len(qf1.bits) || len(qf.bits) != len(qf2.bits) {
 		panic("receiver and passed QuickFilters must be the same size")
 	}
@@ -209,15 +596,38 @@ func (qf QuickFilter) IntersectionOf(qf1, qf2 QuickFilter) QuickFilter {
 	}
 
 	i := len(qf.bits) - 1
-	qf.bits[i] = qf1.bits[i] & qf2.bits[i]
-	qf.len += onesCountLastWord(qf.bits[i], qf.sourceLen)
+	qf.bits[i] = (qf1.bits[i] & qf2.bits[i]) & lastWordMask(qf.sourceLen)
+	qf.len += bits.OnesCount(qf.bits[i])
 
+	qf.debugValidate("IntersectionOf")
 	return qf
 }
 
+// IntersectionCount returns the number of values set in both the receiver
+// and qf2 without storing the intersection.
+//
+// The receiver and passed QuickFilter must be the same size or this will
+// panic.
+func (qf QuickFilter) IntersectionCount(qf2 QuickFilter) int {
+	if len(qf.bits) != len(qf2.bits) {
+		panic("receiver and passed QuickFilters must be the same size")
+	}
+	count := 0
+	for i := range qf.bits[:len(qf.bits)-1] {
+		count += bits.OnesCount(qf.bits[i] & qf2.bits[i])
+	}
+	i := len(qf.bits) - 1
+	count += onesCountLastWord(qf.bits[i]&qf2.bits[i], qf.sourceLen)
+	return count
+}
+
 // Has returns a boolean indicating whether the QuickFilter has the bit at
 // given index set.
+//
+// The index must be at least zero and less than Cap() or this will panic
+// with an *IndexError.
 func (qf QuickFilter) Has(index int) bool {
+	qf.mustIndex(index)
 	wordIndex, mask := offsets(index)
 	return qf.bits[wordIndex]&mask > 0
 }
@@ -262,11 +672,119 @@ func (it Iterator) Next() Iterator {
 	return it
 }
 
+// Reset returns the Iterator at the first offset of the QuickFilter it was
+// obtained from, allowing a loop to scan the same QuickFilter repeatedly:
+//
+//	it := qf.Iterate()
+//	for pass := 0; pass < passes; pass++ {
+//		for it = it.Reset(); !it.Done(); it = it.Next() {
+//			// ...
+//		}
+//	}
+func (it Iterator) Reset() Iterator {
+	it.index = -1
+	return it.Next()
+}
+
 // Value returns the currently found offset.
 func (it Iterator) Value() int {
 	return it.index
 }
 
+// equal returns a boolean indicating whether the QuickFilters have the same
+// source length and set values.
+func (qf QuickFilter) equal(qf2 QuickFilter) bool {
+	if qf.sourceLen != qf2.sourceLen || qf.len != qf2.len {
+		return false
+	}
+	last := len(qf.bits) - 1
+	for i := range qf.bits[:last] {
+		if qf.bits[i] != qf2.bits[i] {
+			return false
+		}
+	}
+	mask := lastWordMask(qf.sourceLen)
+	return qf.bits[last]&mask == qf2.bits[last]&mask
+}
+
+// addRange returns the QuickFilter with the indices from lo (inclusive) to
+// hi (exclusive) added, setting whole words at a time.
+func (qf QuickFilter) addRange(lo, hi int) QuickFilter {
+	if lo >= hi {
+		return qf
+	}
+	loIndex, hiIndex := lo/bits.UintSize, (hi-1)/bits.UintSize
+	loMask := ^uint(0) << (uint(lo) % bits.UintSize)
+	hiMask := ^uint(0) >> (bits.UintSize - 1 - uint(hi-1)%bits.UintSize)
+	if loIndex == hiIndex {
+		loMask &= hiMask
+	}
+	qf.len += bits.OnesCount(loMask &^ qf.bits[loIndex])
+	qf.bits[loIndex] |= loMask
+	if loIndex == hiIndex {
+		return qf
+	}
+	for i := loIndex + 1; i < hiIndex; i++ {
+		qf.len += bits.UintSize - bits.OnesCount(qf.bits[i])
+		qf.bits[i] = ^uint(0)
+	}
+	qf.len += bits.OnesCount(hiMask &^ qf.bits[hiIndex])
+	qf.bits[hiIndex] |= hiMask
+	return qf
+}
+
+// countRange returns the number of set values from lo (inclusive) to hi
+// (exclusive), counting whole words at a time.
+func (qf QuickFilter) countRange(lo, hi int) int {
+	if lo >= hi {
+		return 0
+	}
+	loIndex, hiIndex := lo/bits.UintSize, (hi-1)/bits.UintSize
+	loMask := ^uint(0) << (uint(lo) % bits.UintSize)
+	hiMask := ^uint(0) >> (bits.UintSize - 1 - uint(hi-1)%bits.UintSize)
+	if loIndex == hiIndex {
+		return bits.OnesCount(qf.bits[loIndex] & loMask & hiMask)
+	}
+	count := bits.OnesCount(qf.bits[loIndex]&loMask) + bits.OnesCount(qf.bits[hiIndex]&hiMask)
+	for _, word := range qf.bits[loIndex+1 : hiIndex] {
+		count += bits.OnesCount(word)
+	}
+	return count
+}
+
+// deleteRange returns the QuickFilter with the indices from lo (inclusive) to
+// hi (exclusive) deleted, clearing whole words at a time.
+func (qf QuickFilter) deleteRange(lo, hi int) QuickFilter {
+	if lo >= hi {
+		return qf
+	}
+	loIndex, hiIndex := lo/bits.UintSize, (hi-1)/bits.UintSize
+	loMask := ^uint(0) << (uint(lo) % bits.UintSize)
+	hiMask := ^uint(0) >> (bits.UintSize - 1 - uint(hi-1)%bits.UintSize)
+	if loIndex == hiIndex {
+		loMask &= hiMask
+	}
+	qf.len -= bits.OnesCount(loMask & qf.bits[loIndex])
+	qf.bits[loIndex] &^= loMask
+	if loIndex == hiIndex {
+		return qf
+	}
+	for i := loIndex + 1; i < hiIndex; i++ {
+		qf.len -= bits.OnesCount(qf.bits[i])
+		qf.bits[i] = 0
+	}
+	qf.len -= bits.OnesCount(hiMask & qf.bits[hiIndex])
+	qf.bits[hiIndex] &^= hiMask
+	return qf
+}
+
+// mustIndex panics with an *IndexError if the index is out of range.
+func (qf QuickFilter) mustIndex(index int) {
+	if uint(index) >= uint(qf.sourceLen) {
+		panic(&IndexError{Index: index, Cap: qf.sourceLen})
+	}
+}
+
 func offsets(pos int) (index int, mask uint) {
 	return pos / bits.UintSize, 1 << (uint(pos) % bits.UintSize)
 }
@@ -278,9 +796,25 @@ func offsets(pos int) (index int, mask uint) {
 //
 // We shift by the number of unused bits to have only first usedBitsCount bits left and then count.
 func onesCountLastWord(word uint, sourceLen int) int {
+	if sourceLen == 0 {
+		return 0
+	}
 	countOfBitsInLastWord := sourceLen % bits.UintSize
 	if countOfBitsInLastWord == 0 {
 		countOfBitsInLastWord = bits.UintSize
 	}
 	return bits.OnesCount(word << uint(bits.UintSize - countOfBitsInLastWord))
 }
+
+// lastWordMask returns a mask of the bits in the last word that are within
+// sourceLen. A zero sourceLen still has a last word, with no bits within it.
+func lastWordMask(sourceLen int) uint {
+	if sourceLen == 0 {
+		return 0
+	}
+	countOfBitsInLastWord := sourceLen % bits.UintSize
+	if countOfBitsInLastWord == 0 {
+		return ^uint(0)
+	}
+	return 1<<uint(countOfBitsInLastWord) - 1
+}
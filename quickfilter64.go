@@ -0,0 +1,329 @@
+package quickfilter
+
+import (
+	"sort"
+)
+
+const (
+	chunk64Bits     = 16
+	chunk64Size     = 1 << chunk64Bits
+	chunk64ArrayMax = 4096
+)
+
+// QuickFilter64 is a set of uint64 keys for sparse key spaces, such as
+// snowflake IDs or hashes, that are too large to be filtered with a
+// QuickFilter without first building a dense Dictionary.
+//
+// The keys are split into chunks by their upper 48 bits. Each chunk stores
+// the lower 16 bits of its keys either as a sorted array, when the chunk has
+// few keys, or as a QuickFilter of 65536 bits, when it has many, so that both
+// sparse and dense regions of the key space are stored compactly.
+type QuickFilter64 struct {
+	len    int
+	keys   []uint64
+	chunks []chunk64
+}
+
+type chunk64 struct {
+	array  []uint16
+	bitmap QuickFilter
+}
+
+// NewQuickFilter64 returns a new empty QuickFilter64.
+func NewQuickFilter64() QuickFilter64 {
+	return QuickFilter64{}
+}
+
+// Len returns the number of keys stored.
+func (qf QuickFilter64) Len() int {
+	return qf.len
+}
+
+// Has returns a boolean indicating whether the key is set.
+func (qf QuickFilter64) Has(key uint64) bool {
+	i, ok := qf.find(key >> chunk64Bits)
+	return ok && qf.chunks[i].has(uint16(key))
+}
+
+// Add a key.
+//
+// The original QuickFilter64 is no longer usable and must be replaced with
+// the returned one. This approach prevents the QuickFilter64 from escaping to
+// the heap.
+func (qf QuickFilter64) Add(key uint64) QuickFilter64 {
+	hi, lo := key>>chunk64Bits, uint16(key)
+	i, ok := qf.find(hi)
+	if !ok {
+		qf.keys = append(qf.keys, 0)
+		copy(qf.keys[i+1:], qf.keys[i:])
+		qf.keys[i] = hi
+		qf.chunks = append(qf.chunks, chunk64{})
+		copy(qf.chunks[i+1:], qf.chunks[i:])
+		qf.chunks[i] = chunk64{array: []uint16{lo}}
+		qf.len++
+		return qf
+	}
+	var added bool
+	qf.chunks[i], added = qf.chunks[i].add(lo)
+	if added {
+		qf.len++
+	}
+	return qf
+}
+
+// Delete a key.
+//
+// The original QuickFilter64 is no longer usable and must be replaced with
+// the returned one. This approach prevents the QuickFilter64 from escaping to
+// the heap.
+func (qf QuickFilter64) Delete(key uint64) QuickFilter64 {
+	i, ok := qf.find(key >> chunk64Bits)
+	if !ok {
+		return qf
+	}
+	var deleted bool
+	qf.chunks[i], deleted = qf.chunks[i].delete(uint16(key))
+	if !deleted {
+		return qf
+	}
+	qf.len--
+	if qf.chunks[i].len() == 0 {
+		qf.keys = append(qf.keys[:i], qf.keys[i+1:]...)
+		qf.chunks = append(qf.chunks[:i], qf.chunks[i+1:]...)
+	}
+	return qf
+}
+
+// Union returns a new QuickFilter64 of the keys set in either qf or other.
+func (qf QuickFilter64) Union(other QuickFilter64) QuickFilter64 {
+	var result QuickFilter64
+	i, j := 0, 0
+	for i < len(qf.keys) || j < len(other.keys) {
+		var key uint64
+		var c chunk64
+		switch {
+		case j == len(other.keys) || (i < len(qf.keys) && qf.keys[i] < other.keys[j]):
+			key, c = qf.keys[i], qf.chunks[i].copy()
+			i++
+		case i == len(qf.keys) || other.keys[j] < qf.keys[i]:
+			key, c = other.keys[j], other.chunks[j].copy()
+			j++
+		default:
+			key, c = qf.keys[i], qf.chunks[i].union(other.chunks[j])
+			i++
+			j++
+		}
+		result.keys = append(result.keys, key)
+		result.chunks = append(result.chunks, c)
+		result.len += c.len()
+	}
+	return result
+}
+
+// Intersection returns a new QuickFilter64 of the keys set in both qf and
+// other.
+func (qf QuickFilter64) Intersection(other QuickFilter64) QuickFilter64 {
+	var result QuickFilter64
+	i, j := 0, 0
+	for i < len(qf.keys) && j < len(other.keys) {
+		switch {
+		case qf.keys[i] < other.keys[j]:
+			i++
+		case other.keys[j] < qf.keys[i]:
+			j++
+		default:
+			if c := qf.chunks[i].intersection(other.chunks[j]); c.len() > 0 {
+				result.keys = append(result.keys, qf.keys[i])
+				result.chunks = append(result.chunks, c)
+				result.len += c.len()
+			}
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Iterate over the stored keys in ascending order.
+func (qf QuickFilter64) Iterate() Iterator64 {
+	it := Iterator64{keys: qf.keys, chunks: qf.chunks, chunk: -1}
+	return it.nextChunk()
+}
+
+// Iterator64 over the keys of a QuickFilter64.
+type Iterator64 struct {
+	keys   []uint64
+	chunks []chunk64
+	chunk  int
+	pos    int
+	bitmap Iterator
+}
+
+// Done returns a boolean indicating whether the Iterator64 has been
+// exhausted.
+func (it Iterator64) Done() bool {
+	return it.chunk >= len(it.chunks)
+}
+
+// Next returns the Iterator64 at the next key.
+func (it Iterator64) Next() Iterator64 {
+	c := it.chunks[it.chunk]
+	if c.array == nil {
+		it.bitmap = it.bitmap.Next()
+		if it.bitmap.Done() {
+			return it.nextChunk()
+		}
+		return it
+	}
+	it.pos++
+	if it.pos >= len(c.array) {
+		return it.nextChunk()
+	}
+	return it
+}
+
+// Value returns the current key.
+func (it Iterator64) Value() uint64 {
+	c := it.chunks[it.chunk]
+	lo := uint64(it.bitmap.Value())
+	if c.array != nil {
+		lo = uint64(c.array[it.pos])
+	}
+	return it.keys[it.chunk]<<chunk64Bits | lo
+}
+
+func (it Iterator64) nextChunk() Iterator64 {
+	it.chunk++
+	it.pos = 0
+	if it.chunk < len(it.chunks) && it.chunks[it.chunk].array == nil {
+		it.bitmap = it.chunks[it.chunk].bitmap.Iterate()
+	}
+	return it
+}
+
+func (qf QuickFilter64) find(hi uint64) (int, bool) {
+	i := sort.Search(len(qf.keys), func(i int) bool {
+		return qf.keys[i] >= hi
+	})
+	return i, i < len(qf.keys) && qf.keys[i] == hi
+}
+
+func (c chunk64) len() int {
+	if c.array == nil {
+		return c.bitmap.len
+	}
+	return len(c.array)
+}
+
+func (c chunk64) has(lo uint16) bool {
+	if c.array == nil {
+		return c.bitmap.Has(int(lo))
+	}
+	i := c.search(lo)
+	return i < len(c.array) && c.array[i] == lo
+}
+
+func (c chunk64) add(lo uint16) (chunk64, bool) {
+	if c.array == nil {
+		if c.bitmap.Has(int(lo)) {
+			return c, false
+		}
+		c.bitmap = c.bitmap.Add(int(lo))
+		return c, true
+	}
+	i := c.search(lo)
+	if i < len(c.array) && c.array[i] == lo {
+		return c, false
+	}
+	if len(c.array) == chunk64ArrayMax {
+		c.bitmap = c.toBitmap()
+		c.array = nil
+		c.bitmap = c.bitmap.Add(int(lo))
+		return c, true
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = lo
+	return c, true
+}
+
+func (c chunk64) delete(lo uint16) (chunk64, bool) {
+	if c.array == nil {
+		if !c.bitmap.Has(int(lo)) {
+			return c, false
+		}
+		c.bitmap = c.bitmap.Delete(int(lo))
+		return c, true
+	}
+	i := c.search(lo)
+	if i == len(c.array) || c.array[i] != lo {
+		return c, false
+	}
+	c.array = append(c.array[:i], c.array[i+1:]...)
+	return c, true
+}
+
+func (c chunk64) search(lo uint16) int {
+	return sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] >= lo
+	})
+}
+
+func (c chunk64) toBitmap() QuickFilter {
+	if c.array == nil {
+		return c.bitmap.Copy()
+	}
+	bitmap := New(chunk64Size)
+	for _, lo := range c.array {
+		bitmap = bitmap.Add(int(lo))
+	}
+	return bitmap
+}
+
+func (c chunk64) copy() chunk64 {
+	if c.array == nil {
+		return chunk64{bitmap: c.bitmap.Copy()}
+	}
+	return chunk64{array: append([]uint16(nil), c.array...)}
+}
+
+func (c chunk64) union(other chunk64) chunk64 {
+	if c.array != nil && other.array != nil && len(c.array)+len(other.array) <= chunk64ArrayMax {
+		array := make([]uint16, 0, len(c.array)+len(other.array))
+		i, j := 0, 0
+		for i < len(c.array) || j < len(other.array) {
+			switch {
+			case j == len(other.array) || (i < len(c.array) && c.array[i] < other.array[j]):
+				array = append(array, c.array[i])
+				i++
+			case i == len(c.array) || other.array[j] < c.array[i]:
+				array = append(array, other.array[j])
+				j++
+			default:
+				array = append(array, c.array[i])
+				i++
+				j++
+			}
+		}
+		return chunk64{array: array}
+	}
+	bitmap := c.toBitmap()
+	return chunk64{bitmap: bitmap.UnionOf(bitmap, other.toBitmap())}
+}
+
+func (c chunk64) intersection(other chunk64) chunk64 {
+	if c.array == nil && other.array == nil {
+		bitmap := New(chunk64Size)
+		return chunk64{bitmap: bitmap.IntersectionOf(c.bitmap, other.bitmap)}
+	}
+	if c.array == nil {
+		c, other = other, c
+	}
+	array := []uint16{}
+	for _, lo := range c.array {
+		if other.has(lo) {
+			array = append(array, lo)
+		}
+	}
+	return chunk64{array: array}
+}
@@ -0,0 +1,108 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestQuickFilter64(t *testing.T) {
+	collect64 := func(qf quickfilter.QuickFilter64) []uint64 {
+		result := []uint64{}
+		for it := qf.Iterate(); !it.Done(); it = it.Next() {
+			result = append(result, it.Value())
+		}
+		return result
+	}
+	newDense := func(offset uint64) quickfilter.QuickFilter64 {
+		qf := quickfilter.NewQuickFilter64()
+		for i := uint64(0); i < 10000; i += 2 {
+			qf = qf.Add(offset + i)
+		}
+		return qf
+	}
+
+	t.Run("Add and Has", func(t *testing.T) {
+		qf := quickfilter.NewQuickFilter64()
+		keys := []uint64{1 << 62, 7, 1<<40 + 3, 7}
+
+		for _, key := range keys {
+			qf = qf.Add(key)
+		}
+
+		for _, key := range keys {
+			if !qf.Has(key) {
+				t.Errorf("expected %d to be set", key)
+			}
+		}
+		if qf.Has(8) {
+			t.Errorf("expected %d not to be set", 8)
+		}
+		if qf.Len() != 3 {
+			t.Errorf("expected %d, got %d", 3, qf.Len())
+		}
+	})
+
+	t.Run("Iterate", func(t *testing.T) {
+		qf := quickfilter.NewQuickFilter64().Add(1 << 62).Add(7).Add(1<<40 + 3).Add(70000)
+		expected := []uint64{7, 70000, 1<<40 + 3, 1 << 62}
+
+		received := collect64(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("dense chunk", func(t *testing.T) {
+		qf := newDense(1 << 50)
+		expectedLen := 5000
+
+		qf = qf.Delete(1 << 50).Delete(1<<50 + 1)
+		receivedLen := len(collect64(qf))
+
+		if qf.Len() != expectedLen-1 || receivedLen != expectedLen-1 {
+			t.Errorf("expected %d, got %d and %d", expectedLen-1, qf.Len(), receivedLen)
+		}
+		if qf.Has(1<<50) || !qf.Has(1<<50+2) {
+			t.Error("unexpected membership after Delete")
+		}
+	})
+
+	t.Run("Delete last key in chunk", func(t *testing.T) {
+		qf := quickfilter.NewQuickFilter64().Add(1 << 40).Add(3)
+		expected := []uint64{3}
+
+		qf = qf.Delete(1 << 40)
+		received := collect64(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		qf1 := newDense(0).Add(1 << 60)
+		qf2 := quickfilter.NewQuickFilter64().Add(1).Add(2).Add(1 << 61)
+		expectedLen := 5000 + 1 + 2
+
+		received := qf1.Union(qf2)
+
+		if received.Len() != expectedLen || len(collect64(received)) != expectedLen {
+			t.Errorf("expected %d, got %d", expectedLen, received.Len())
+		}
+	})
+
+	t.Run("Intersection", func(t *testing.T) {
+		qf1 := newDense(0).Add(1 << 60)
+		qf2 := quickfilter.NewQuickFilter64().Add(1).Add(2).Add(4).Add(1 << 60).Add(1 << 61)
+		expected := []uint64{2, 4, 1 << 60}
+
+		received := collect64(qf1.Intersection(qf2))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
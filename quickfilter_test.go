@@ -2,6 +2,8 @@ package quickfilter_test
 
 import (
 	"fmt"
+	"math/bits"
+	"reflect"
 	"testing"
 
 	"github.com/jussi-kalliokoski/quickfilter"
@@ -25,6 +27,91 @@ func Test(t *testing.T) {
 		validate(t, len(data), newData)
 	})
 
+	t.Run("NewFromFunc", func(t *testing.T) {
+		expected := []int{}
+		for i := 0; i < 150; i += 7 {
+			expected = append(expected, i)
+		}
+
+		qf := quickfilter.NewFromFunc(150, func(i int) bool { return i%7 == 0 })
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("NewFromIndices", func(t *testing.T) {
+		expected := []int{1, 5, 80}
+
+		qf := quickfilter.NewFromIndices(100, []int{80, 1, 5, 1})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("NewRange", func(t *testing.T) {
+		for _, r := range [][2]int{{0, 0}, {0, 200}, {3, 5}, {60, 130}, {64, 128}, {199, 200}} {
+			expected := []int{}
+			for i := r[0]; i < r[1]; i++ {
+				expected = append(expected, i)
+			}
+
+			qf := quickfilter.NewRange(200, r[0], r[1])
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+
+	t.Run("NewRange out of bounds should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.NewRange(200, 10, 201)
+	})
+
+	t.Run("NewEveryNth", func(t *testing.T) {
+		for _, c := range [][2]int{{1, 0}, {2, 1}, {4, 70}, {3, 0}, {7, 5}, {16, 200}, {8, 250}} {
+			n, offset := c[0], c[1]
+			expected := []int{}
+			for i := offset; i < 230; i += n {
+				expected = append(expected, i)
+			}
+
+			qf := quickfilter.NewEveryNth(230, n, offset)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+				t.Errorf("n=%d offset=%d: expected %v, got %v", n, offset, expected, received)
+			}
+		}
+	})
+
+	t.Run("Iterator Reset", func(t *testing.T) {
+		qf := quickfilter.NewFromIndices(100, []int{5, 70})
+		expected := []int{5, 70, 5, 70}
+
+		received := []int{}
+		it := qf.Iterate()
+		for pass := 0; pass < 2; pass++ {
+			for it = it.Reset(); !it.Done(); it = it.Next() {
+				received = append(received, it.Value())
+			}
+		}
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
 	t.Run("Fill and Iterate", func(t *testing.T) {
 		data := generateData(20)
 		qf := quickfilter.New(len(data))
@@ -103,6 +190,69 @@ func Test(t *testing.T) {
 		})
 	})
 
+	t.Run("out of range should panic with an IndexError", func(t *testing.T) {
+		qf := quickfilter.New(10)
+		for _, fn := range []func(){
+			func() { qf.Add(10) },
+			func() { qf.Delete(-1) },
+			func() { qf.Has(63) },
+		} {
+			func() {
+				defer func() {
+					if _, ok := recover().(*quickfilter.IndexError); !ok {
+						t.Error("expected a panic with an IndexError")
+					}
+				}()
+
+				fn()
+			}()
+		}
+	})
+
+	t.Run("IsEmpty and IsFull", func(t *testing.T) {
+		qf := quickfilter.New(70)
+		expected := []bool{true, false, false, false, false, true}
+
+		received := []bool{qf.IsEmpty(), qf.IsFull()}
+		qf = qf.Add(3)
+		received = append(received, qf.IsEmpty(), qf.IsFull())
+		qf = qf.Fill()
+		received = append(received, qf.IsEmpty(), qf.IsFull())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("zero Cap", func(t *testing.T) {
+		qf := quickfilter.NewFilled(0)
+		other := quickfilter.NewFilled(0)
+		expected := []int{}
+
+		qf = qf.UnionOf(qf, other)
+		qf = qf.Resize(0).IntersectionOf(qf, other)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != 0 {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if !qf.IsEmpty() || !qf.IsFull() || qf.IntersectionCount(other) != 0 {
+			t.Error("expected a zero Cap QuickFilter to be both empty and full")
+		}
+	})
+
+	t.Run("IntersectionCount", func(t *testing.T) {
+		qf1 := quickfilter.NewFilled(130)
+		qf2 := quickfilter.NewFilled(130).Delete(3).Delete(129)
+		expectedCount := 128
+
+		receivedCount := qf1.IntersectionCount(qf2)
+
+		if expectedCount != receivedCount {
+			t.Errorf("expected %d, got %d", expectedCount, receivedCount)
+		}
+	})
+
 	t.Run("Cap", func(t *testing.T) {
 		expectedCap := 64
 		qf := quickfilter.New(expectedCap)
@@ -168,6 +318,210 @@ func Test(t *testing.T) {
 		})
 	})
 
+	t.Run("AddGrow", func(t *testing.T) {
+		t.Run("within Cap", func(t *testing.T) {
+			qf := quickfilter.New(10)
+			expected := []int{3}
+
+			qf = qf.AddGrow(3).AddGrow(3)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != 1 || qf.Cap() != 10 {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+
+		t.Run("beyond Cap", func(t *testing.T) {
+			qf := quickfilter.NewFilled(10)
+			expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 500}
+
+			for i := 100; i <= 500; i += 100 {
+				qf = qf.AddGrow(i)
+				qf = qf.Delete(i)
+			}
+			qf = qf.AddGrow(500)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) || qf.Cap() != 501 {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+	})
+
+	t.Run("AddMany and DeleteMany", func(t *testing.T) {
+		qf := quickfilter.New(100)
+		expected := []int{1, 64, 99}
+
+		qf = qf.AddMany([]int{99, 1, 64, 1, 30, 70})
+		qf = qf.DeleteMany([]int{30, 70, 70, 50})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("AddMany out of range should leave the QuickFilter unchanged", func(t *testing.T) {
+		qf := quickfilter.New(100)
+		expected := []int{}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+
+			qf.AddMany([]int{1, 2, 100})
+		}()
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("MoveBit", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(10).Add(99)
+		expected := []int{10, 50}
+
+		qf = qf.MoveBit(99, 50).MoveBit(80, 70).MoveBit(10, 10)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("MoveBit over a set bit", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(10).Add(99)
+		expected := []int{}
+
+		qf = qf.MoveBit(5, 10).MoveBit(6, 99)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("SwapBits", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(10).Add(99)
+		expected := []int{3, 99}
+
+		qf = qf.SwapBits(10, 3).SwapBits(99, 50).SwapBits(50, 99)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Grow", func(t *testing.T) {
+		t.Run("should zero reused words", func(t *testing.T) {
+			qf := quickfilter.NewFilled(300).Resize(10).Clear().Add(3)
+			expected := []int{3}
+
+			qf = qf.Grow(300)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) || qf.Cap() != 300 {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+
+		t.Run("should keep values when reallocating", func(t *testing.T) {
+			qf := quickfilter.New(100).Add(3).Add(99)
+			expected := []int{3, 99}
+
+			qf = qf.Grow(1000)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		})
+
+		t.Run("should not shrink", func(t *testing.T) {
+			qf := quickfilter.New(100)
+			expectedCap := 100
+
+			receivedCap := qf.Grow(50).Cap()
+
+			if expectedCap != receivedCap {
+				t.Errorf("expected %d, got %d", expectedCap, receivedCap)
+			}
+		})
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(3).Add(69).Add(70).Add(150)
+		expected := []int{3, 69}
+
+		qf = qf.Truncate(70)
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) || qf.Cap() != 70 {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Truncate and Grow", func(t *testing.T) {
+		qf := quickfilter.NewFilled(200)
+		expectedLen := 70
+
+		qf = qf.Truncate(70).Grow(200)
+		receivedLen := len(collect(qf))
+
+		if expectedLen != receivedLen || qf.Len() != expectedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("Add existing index", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(3).Add(3)
+		expectedLen := 1
+
+		receivedLen := qf.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("Recount", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(3).Add(3).Add(5)
+		expectedLen := 2
+
+		receivedLen := qf.Recount().Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
+	t.Run("TrailingMask", func(t *testing.T) {
+		qf := quickfilter.New(bits.UintSize + 3)
+		expectedMask, expectedUsed := uint(7), 3
+
+		receivedMask, receivedUsed := qf.TrailingMask(), qf.UsedBitsInLastWord()
+
+		if expectedMask != receivedMask || expectedUsed != receivedUsed {
+			t.Errorf("expected %d and %d, got %d and %d", expectedMask, expectedUsed, receivedMask, receivedUsed)
+		}
+	})
+
+	t.Run("Fill should not set bits beyond Cap", func(t *testing.T) {
+		qf := quickfilter.NewFilled(10).Resize(20)
+		expectedLen := 10
+
+		receivedLen := qf.Recount().Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+
 	t.Run("CopyFrom", func(t *testing.T) {
 		qf1 := quickfilter.NewFilled(128)
 		qf2 := quickfilter.New(qf1.Cap())
@@ -181,7 +535,46 @@ func Test(t *testing.T) {
 		}
 	})
 
+	t.Run("Remap", func(t *testing.T) {
+		data := []int{50, 10, 40, 20, 30}
+		perm := []int{4, 0, 3, 1, 2}
+		qf := quickfilter.New(len(data)).Add(0).Add(2).Add(3)
+		expected := []int{20, 40, 50}
+
+		sorted := make([]int, len(data))
+		for i := range data {
+			sorted[perm[i]] = data[i]
+		}
+		qf = qf.Remap(perm)
+		received := make([]int, 0, qf.Len())
+		for it := qf.Iterate(); !it.Done(); it = it.Next() {
+			received = append(received, sorted[it.Value()])
+		}
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if len(expected) != qf.Len() {
+			t.Errorf("expected %d, got %d", len(expected), qf.Len())
+		}
+	})
+
 	t.Run("Resize", func(t *testing.T) {
+		t.Run("shrink should drop values beyond Cap", func(t *testing.T) {
+			qf := quickfilter.New(10).Fill()
+			expected := []int{0, 1, 2}
+
+			qf = qf.Resize(3)
+			received := collect(qf)
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+			if err := qf.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+
 		t.Run("shrink", func(t *testing.T) {
 			expectedCap := 64
 			expectedLen := expectedCap
@@ -306,8 +699,8 @@ func Test(t *testing.T) {
 		sourceLen := 10
 		qf1 := quickfilter.NewFilled(sourceLen)
 		qf2 := quickfilter.NewFilled(sourceLen)
-		qf2.Delete(8)
-		qf2.Delete(1)
+		qf2 = qf2.Delete(8)
+		qf2 = qf2.Delete(1)
 		qf2 = qf1.IntersectionOf(qf1, qf2)
 
 		got := qf2.Len()
@@ -361,8 +754,8 @@ func Test(t *testing.T) {
 		expectedLen := 10
 		qf1 := quickfilter.NewFilled(expectedLen)
 		qf2 := quickfilter.NewFilled(expectedLen)
-		qf2.Delete(8)
-		qf2.Delete(1)
+		qf2 = qf2.Delete(8)
+		qf2 = qf2.Delete(1)
 		qf2 = qf1.UnionOf(qf1, qf2)
 
 		got := qf2.Len()
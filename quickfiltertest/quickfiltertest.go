@@ -0,0 +1,99 @@
+// Package quickfiltertest provides helpers for testing code that produces
+// QuickFilters.
+//
+// Rather than dumping the backing words of both filters, AssertEqual reports
+// the differing indices as ranges in the syntax accepted by
+// quickfilter.ParseRanges, which is also what Ranges builds fixtures from:
+//
+//	want := quickfiltertest.Ranges(100, "1-3, 7, 10-12")
+//	quickfiltertest.AssertEqual(t, want, got)
+package quickfiltertest
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+// AssertEqual reports an error on t if got doesn't have the same Cap() and
+// set indices as want, listing the indices missing from got and the
+// unexpected indices in got as ranges. It also reports an error if got
+// doesn't pass Validate.
+func AssertEqual(t testing.TB, want, got quickfilter.QuickFilter) {
+	t.Helper()
+	if err := got.Validate(); err != nil {
+		t.Errorf("invalid QuickFilter: %v", err)
+		return
+	}
+	if want.Cap() != got.Cap() {
+		t.Errorf("QuickFilters differ: expected Cap() %d, got %d", want.Cap(), got.Cap())
+		return
+	}
+	if want.Compare(got) == 0 {
+		return
+	}
+	wantSet, gotSet := quickfilter.IntervalSetOf(want), quickfilter.IntervalSetOf(got)
+	var b strings.Builder
+	b.WriteString("QuickFilters differ:")
+	if missing := wantSet.Difference(gotSet); missing.Len() > 0 {
+		b.WriteString("\n\tmissing:    ")
+		b.WriteString(FormatRanges(missing))
+	}
+	if unexpected := gotSet.Difference(wantSet); unexpected.Len() > 0 {
+		b.WriteString("\n\tunexpected: ")
+		b.WriteString(FormatRanges(unexpected))
+	}
+	t.Error(b.String())
+}
+
+// AssertEmpty reports an error on t if got has any indices set, listing them
+// as ranges.
+func AssertEmpty(t testing.TB, got quickfilter.QuickFilter) {
+	t.Helper()
+	AssertEqual(t, quickfilter.New(got.Cap()), got)
+}
+
+// FormatRanges formats the intervals of s as a comma-separated list of
+// indices and inclusive index ranges, in the syntax accepted by
+// quickfilter.ParseRanges.
+func FormatRanges(s quickfilter.IntervalSet) string {
+	var b strings.Builder
+	for i, interval := range s.Intervals() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Itoa(interval.Lo))
+		if interval.Hi-1 > interval.Lo {
+			b.WriteByte('-')
+			b.WriteString(strconv.Itoa(interval.Hi - 1))
+		}
+	}
+	return b.String()
+}
+
+// Ranges returns a new QuickFilter of sourceLen offsets with the indices and
+// inclusive index ranges in s set, for example "1-3, 7, 10-12".
+//
+// s must be valid or this will panic.
+func Ranges(sourceLen int, s string) quickfilter.QuickFilter {
+	return quickfilter.MustParseRanges(sourceLen, s)
+}
+
+// Indices returns a new QuickFilter of sourceLen offsets with the given
+// indices set.
+//
+// The indices must be at least zero and less than sourceLen or this will
+// panic.
+func Indices(sourceLen int, indices ...int) quickfilter.QuickFilter {
+	return quickfilter.NewFromIndices(sourceLen, indices)
+}
+
+// EveryNth returns a new QuickFilter of sourceLen offsets with every nth
+// index set, starting from offset.
+//
+// n must be at least 1 and offset must be at least 0 or this will panic.
+func EveryNth(sourceLen, n, offset int) quickfilter.QuickFilter {
+	return quickfilter.NewEveryNth(sourceLen, n, offset)
+}
@@ -0,0 +1,77 @@
+package quickfiltertest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+	"github.com/jussi-kalliokoski/quickfilter/quickfiltertest"
+)
+
+// recorder captures the errors reported to it instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     quickfilter.QuickFilter
+		got      quickfilter.QuickFilter
+		expected string
+	}{
+		{
+			name: "equal",
+			want: quickfiltertest.Ranges(100, "1-3, 70"),
+			got:  quickfiltertest.Indices(100, 70, 2, 1, 3),
+		},
+		{
+			name:     "different indices",
+			want:     quickfiltertest.Ranges(100, "1-3, 10-12, 70"),
+			got:      quickfiltertest.Ranges(100, "1, 3, 10-15"),
+			expected: "QuickFilters differ:\n\tmissing:    2, 70\n\tunexpected: 13-15",
+		},
+		{
+			name:     "different Cap",
+			want:     quickfilter.New(100),
+			got:      quickfilter.New(101),
+			expected: "QuickFilters differ: expected Cap() 100, got 101",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+
+			quickfiltertest.AssertEqual(r, tt.want, tt.got)
+
+			switch {
+			case tt.expected == "" && len(r.errors) != 0:
+				t.Errorf("expected no errors, got %q", r.errors)
+			case tt.expected != "" && (len(r.errors) != 1 || r.errors[0] != tt.expected):
+				t.Errorf("expected %q, got %q", tt.expected, r.errors)
+			}
+		})
+	}
+}
+
+func TestAssertEmpty(t *testing.T) {
+	r := &recorder{TB: t}
+	expected := "QuickFilters differ:\n\tunexpected: 0, 2, 4"
+
+	quickfiltertest.AssertEmpty(r, quickfiltertest.EveryNth(5, 2, 0))
+
+	if len(r.errors) != 1 || r.errors[0] != expected {
+		t.Errorf("expected %q, got %q", expected, r.errors)
+	}
+}
@@ -0,0 +1,37 @@
+package quickfilter
+
+import (
+	"math/rand"
+	"reflect"
+)
+
+// NewRandom returns a new QuickFilter of sourceLen offsets where each offset
+// is stored independently with probability density, using rng as the source
+// of randomness so that the result is reproducible from a seed.
+//
+// density must be between 0 and 1 or this will panic.
+func NewRandom(sourceLen int, density float64, rng *rand.Rand) QuickFilter {
+	if density < 0 || density > 1 {
+		panic("density must be between 0 and 1")
+	}
+	qf := New(sourceLen)
+	for i := 0; i < sourceLen; i++ {
+		if rng.Float64() < density {
+			index, mask := offsets(i)
+			qf.bits[index] |= mask
+			qf.len++
+		}
+	}
+	return qf
+}
+
+// Generate implements quick.Generator, so that property-based tests using
+// testing/quick can take QuickFilters as arguments. The generated
+// QuickFilter has a random Cap() less than size and a random density.
+func (QuickFilter) Generate(rng *rand.Rand, size int) reflect.Value {
+	sourceLen := 0
+	if size > 0 {
+		sourceLen = rng.Intn(size)
+	}
+	return reflect.ValueOf(NewRandom(sourceLen, rng.Float64(), rng))
+}
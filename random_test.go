@@ -0,0 +1,64 @@
+package quickfilter_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestNewRandom(t *testing.T) {
+	t.Run("should be reproducible from a seed", func(t *testing.T) {
+		expected := collect(quickfilter.NewRandom(1000, 0.3, rand.New(rand.NewSource(1))))
+
+		received := collect(quickfilter.NewRandom(1000, 0.3, rand.New(rand.NewSource(1))))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should have about the given density", func(t *testing.T) {
+		qf := quickfilter.NewRandom(10000, 0.3, rand.New(rand.NewSource(1)))
+
+		received := qf.Len()
+
+		if received < 2800 || received > 3200 || qf.Validate() != nil {
+			t.Errorf("expected about 3000, got %d", received)
+		}
+	})
+
+	t.Run("extremes", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+
+		empty := quickfilter.NewRandom(100, 0, rng)
+		full := quickfilter.NewRandom(100, 1, rng)
+
+		if !empty.IsEmpty() || !full.IsFull() {
+			t.Errorf("expected empty and full, got %d and %d", empty.Len(), full.Len())
+		}
+	})
+
+	t.Run("invalid density should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.NewRandom(100, 1.5, rand.New(rand.NewSource(1)))
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	property := func(qf quickfilter.QuickFilter) bool {
+		return qf.Validate() == nil && qf.Len() == len(collect(qf))
+	}
+	config := &quick.Config{Rand: rand.New(rand.NewSource(1)), MaxCount: 200}
+
+	if err := quick.Check(property, config); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,174 @@
+package quickfilter
+
+import (
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+// recordBufferSize is the size of the buffer used for reading records, which
+// bounds the size of a single read of adjacent records.
+const recordBufferSize = 64 * 1024
+
+// FilteredRecords applies a QuickFilter to a source of fixed-size binary
+// records, such as a file whose records mirror an in-memory slice that the
+// filter was computed over. The record at offset i of the filter is stored
+// at i*recordSize bytes in the source.
+type FilteredRecords struct {
+	src        io.ReaderAt
+	recordSize int
+	qf         QuickFilter
+}
+
+// NewFilteredRecords returns a new FilteredRecords of the records in src
+// selected by qf.
+//
+// recordSize must be at least 1 or this will panic.
+func NewFilteredRecords(src io.ReaderAt, recordSize int, qf QuickFilter) FilteredRecords {
+	if recordSize < 1 {
+		panic("recordSize must be at least 1")
+	}
+	return FilteredRecords{src: src, recordSize: recordSize, qf: qf}
+}
+
+// WriteTo implements io.WriterTo, writing the selected records to w in
+// ascending order.
+//
+// Only the selected records are read from the source, and runs of adjacent
+// selected records are coalesced into single reads of up to 64 KiB. If the
+// source ends before a selected record, io.ErrUnexpectedEOF is returned.
+func (r FilteredRecords) WriteTo(w io.Writer) (int64, error) {
+	recordsPerRead := recordBufferSize / r.recordSize
+	if recordsPerRead < 1 {
+		recordsPerRead = 1
+	}
+	buf := make([]byte, recordsPerRead*r.recordSize)
+	var written int64
+	var err error
+	r.qf.eachRun(func(lo, hi int) {
+		for ; err == nil && lo < hi; lo += recordsPerRead {
+			n := hi - lo
+			if n > recordsPerRead {
+				n = recordsPerRead
+			}
+			chunk := buf[:n*r.recordSize]
+			var read int
+			read, err = r.src.ReadAt(chunk, int64(lo)*int64(r.recordSize))
+			if read == len(chunk) {
+				err = nil
+			} else if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return
+			}
+			read, err = w.Write(chunk)
+			written += int64(read)
+		}
+	})
+	return written, err
+}
+
+// AppendRecords appends the records of data selected by qf to dst and
+// returns the extended slice, copying runs of adjacent selected records at
+// once. data can be a memory-mapped file of fixed-size records, for example
+// obtained with syscall.Mmap, in which case only the pages of the selected
+// records are read from disk.
+//
+// data must have a length of Cap() records of recordSize bytes, and
+// recordSize must be at least 1, or this will panic.
+func AppendRecords(dst, data []byte, recordSize int, qf QuickFilter) []byte {
+	mustRecords(data, recordSize, qf)
+	qf.eachRun(func(lo, hi int) {
+		dst = append(dst, data[lo*recordSize:hi*recordSize]...)
+	})
+	return dst
+}
+
+// AppendRecordOffsets appends the byte offsets of the records selected by qf
+// in a source of fixed-size records of recordSize bytes to dst and returns
+// the extended slice, for accessing the selected records of a
+// memory-mapped file without copying them.
+func (qf QuickFilter) AppendRecordOffsets(dst []int64, recordSize int) []int64 {
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		dst = append(dst, int64(it.Value())*int64(recordSize))
+	}
+	return dst
+}
+
+// MapRecords points the slice that slicePtr points to at the records in
+// data, without copying, so that a memory-mapped file of fixed-size structs
+// can be accessed as a slice of them, for example:
+//
+//	var points []Point
+//	quickfilter.MapRecords(data, &points)
+//	selected := make([]Point, 0, qf.Len())
+//	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+//		selected = append(selected, points[it.Value()])
+//	}
+//
+// This uses package unsafe: the slice aliases data and must not be used
+// after data is unmapped, the element type must have the same layout as the
+// records, including padding and byte order, and the element type must not
+// contain pointers, which the garbage collector would otherwise follow into
+// the mapped memory.
+//
+// slicePtr must be a pointer to a slice of a pointer-free type, data must
+// have a length that is a multiple of the element size and be aligned for
+// the element type, or this will panic.
+func MapRecords(data []byte, slicePtr interface{}) {
+	v := reflect.ValueOf(slicePtr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("slicePtr must be a pointer to a slice")
+	}
+	elemType := v.Elem().Type().Elem()
+	if hasPointers(elemType) {
+		panic("slice element type must not contain pointers")
+	}
+	size := int(elemType.Size())
+	if size == 0 || len(data)%size != 0 {
+		panic("data must have a length that is a multiple of the element size")
+	}
+	n := len(data) / size
+	if n == 0 {
+		v.Elem().Set(reflect.MakeSlice(v.Elem().Type(), 0, 0))
+		return
+	}
+	p := unsafe.Pointer(&data[0])
+	if uintptr(p)%uintptr(elemType.Align()) != 0 {
+		panic("data must be aligned for the element type")
+	}
+	array := reflect.NewAt(reflect.ArrayOf(n, elemType), p).Elem()
+	v.Elem().Set(array.Slice3(0, n, n))
+}
+
+func mustRecords(data []byte, recordSize int, qf QuickFilter) {
+	if recordSize < 1 {
+		panic("recordSize must be at least 1")
+	}
+	if len(data) != qf.sourceLen*recordSize {
+		panic("data must have the same number of records as the QuickFilter")
+	}
+}
+
+// hasPointers returns a boolean indicating whether values of t contain
+// pointers.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,131 @@
+package quickfilter_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+// countingReaderAt counts the calls to ReadAt.
+type countingReaderAt struct {
+	io.ReaderAt
+	reads int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.reads++
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+func TestFilteredRecords(t *testing.T) {
+	src := []byte("aaabbbcccdddeeefffggg")
+
+	t.Run("should write the selected records", func(t *testing.T) {
+		qf := quickfilter.New(7).Add(1).Add(2).Add(3).Add(6)
+		r := &countingReaderAt{ReaderAt: bytes.NewReader(src)}
+		var buf bytes.Buffer
+		expected := "bbbcccdddggg"
+		expectedReads := 2
+
+		n, err := quickfilter.NewFilteredRecords(r, 3, qf).WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := buf.String()
+
+		if expected != received || n != int64(len(expected)) {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+		if expectedReads != r.reads {
+			t.Errorf("expected %d, got %d", expectedReads, r.reads)
+		}
+	})
+
+	t.Run("should split long runs into bounded reads", func(t *testing.T) {
+		big := bytes.Repeat([]byte{'x'}, 200*1024)
+		r := &countingReaderAt{ReaderAt: bytes.NewReader(big)}
+		var buf bytes.Buffer
+		expectedReads := 4
+
+		_, err := quickfilter.NewFilteredRecords(r, 1024, quickfilter.NewFilled(200)).WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(big, buf.Bytes()) {
+			t.Errorf("expected %d bytes, got %d", len(big), buf.Len())
+		}
+		if expectedReads != r.reads {
+			t.Errorf("expected %d, got %d", expectedReads, r.reads)
+		}
+	})
+
+	t.Run("short source", func(t *testing.T) {
+		qf := quickfilter.New(8).Add(7)
+		var buf bytes.Buffer
+
+		_, err := quickfilter.NewFilteredRecords(bytes.NewReader(src), 3, qf).WriteTo(&buf)
+
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
+
+func TestAppendRecords(t *testing.T) {
+	data := []byte("aaabbbcccdddeeefffggg")
+	qf := quickfilter.New(7).Add(1).Add(2).Add(3).Add(6)
+	expected := "xbbbcccdddggg"
+
+	received := string(quickfilter.AppendRecords([]byte("x"), data, 3, qf))
+
+	if expected != received {
+		t.Errorf("expected %q, got %q", expected, received)
+	}
+}
+
+func TestAppendRecordOffsets(t *testing.T) {
+	qf := quickfilter.New(7).Add(1).Add(6)
+	expected := []int64{12, 72}
+
+	received := qf.AppendRecordOffsets(nil, 12)
+
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("expected %v, got %v", expected, received)
+	}
+}
+
+func TestMapRecords(t *testing.T) {
+	type point struct {
+		X, Y int32
+	}
+
+	t.Run("should alias the data", func(t *testing.T) {
+		src := []point{{1, 2}, {3, 4}, {5, 6}}
+		data := make([]byte, 24)
+		var points []point
+		quickfilter.MapRecords(data, &points)
+		copy(points, src)
+		var received []point
+
+		quickfilter.MapRecords(data, &received)
+
+		if !reflect.DeepEqual(src, received) {
+			t.Errorf("expected %v, got %v", src, received)
+		}
+	})
+
+	t.Run("pointer element type should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		var strs []string
+
+		quickfilter.MapRecords(make([]byte, 32), &strs)
+	})
+}
@@ -0,0 +1,40 @@
+package quickfilter
+
+// Registry interns QuickFilters, deduplicating filters with equal contents
+// so that workloads with many groups whose membership masks are often equal
+// only store each distinct mask once.
+type Registry struct {
+	filters map[uint64][]QuickFilter
+	len     int
+}
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() Registry {
+	return Registry{filters: make(map[uint64][]QuickFilter)}
+}
+
+// Intern returns the QuickFilter in the Registry with the same Cap() and set
+// values as qf. If there is none, qf is added to the Registry and returned.
+//
+// The Registry takes ownership of qf, and the returned QuickFilter is shared
+// with every other caller that interned an equal filter, so neither must be
+// modified afterwards.
+//
+// The original Registry is no longer usable and must be replaced with the
+// returned one.
+func (r Registry) Intern(qf QuickFilter) (Registry, QuickFilter) {
+	hash := qf.hash()
+	for _, existing := range r.filters[hash] {
+		if existing.equal(qf) {
+			return r, existing
+		}
+	}
+	r.filters[hash] = append(r.filters[hash], qf)
+	r.len++
+	return r, qf
+}
+
+// Len returns the number of distinct QuickFilters in the Registry.
+func (r Registry) Len() int {
+	return r.len
+}
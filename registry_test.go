@@ -0,0 +1,32 @@
+package quickfilter_test
+
+import (
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("Intern should deduplicate", func(t *testing.T) {
+		r := quickfilter.NewRegistry()
+		expectedLen := 3
+
+		r, qf1 := r.Intern(quickfilter.New(10).Add(1).Add(2))
+		r, qf2 := r.Intern(quickfilter.New(10).Add(2).Add(1))
+		r, _ = r.Intern(quickfilter.New(11).Add(1).Add(2))
+		r, _ = r.Intern(quickfilter.NewFilled(10))
+		r, qf3 := r.Intern(quickfilter.NewFilled(10))
+		receivedLen := r.Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+		_ = qf1.Add(5)
+		if !qf2.Has(5) {
+			t.Error("expected interned QuickFilters to share storage")
+		}
+		if qf3.Len() != 10 {
+			t.Errorf("expected %d, got %d", 10, qf3.Len())
+		}
+	})
+}
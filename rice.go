@@ -0,0 +1,95 @@
+package quickfilter
+
+import (
+	"encoding/binary"
+)
+
+// AppendRice appends the Rice coding of the gaps between the set indices to
+// dst and returns the extended slice. The Rice parameter is chosen from the
+// density of the QuickFilter, so the encoding takes about
+// 1.5 + log2(Cap()/Len()) bits per set index when the indices are spread
+// evenly. It is typically smaller than MarshalBinary for all but dense
+// QuickFilters, and a middle ground between MarshalBinary and
+// AppendEliasFano in encoding speed.
+//
+// The encoding is the source length and the number of set indices as
+// unsigned varints and the Rice parameter as a byte, followed by the gap
+// before each index, coded as the quotient in unary and the remainder in the
+// number of bits given by the parameter, packed in little-endian bit order.
+func (qf QuickFilter) AppendRice(dst []byte) []byte {
+	count := qf.countRange(0, qf.sourceLen)
+	dst = appendUvarint(dst, uint64(qf.sourceLen))
+	dst = appendUvarint(dst, uint64(count))
+	k := log2MeanGap(qf.sourceLen, count)
+	w := bitWriter{buf: append(dst, byte(k))}
+	next := uint64(0)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		gap := uint64(it.Value()) - next
+		w.writeUnary(gap >> k)
+		w.write(gap, k)
+		next = uint64(it.Value()) + 1
+	}
+	w.flush()
+	return w.buf
+}
+
+// UnmarshalRice decodes data produced by AppendRice into the QuickFilter,
+// setting the bits as the indices are decoded without materializing them.
+// The data is validated before the QuickFilter is modified, so on error the
+// QuickFilter is left unchanged.
+//
+// The existing backing buffer of the QuickFilter is reused if it is large
+// enough.
+func (qf *QuickFilter) UnmarshalRice(data []byte) error {
+	sourceLen, count, err := decodeRice(data, nil)
+	if err != nil {
+		return err
+	}
+	result := qf.Resize(sourceLen).Clear()
+	decodeRice(data, func(index int) {
+		wordIndex, mask := offsets(index)
+		result.bits[wordIndex] |= mask
+	})
+	result.len = count
+	*qf = result
+	return nil
+}
+
+// decodeRice decodes data produced by AppendRice, calling set, if not nil,
+// with each index, and returns the source length and the number of indices.
+func decodeRice(data []byte, set func(index int)) (int, int, error) {
+	sourceLen, n := binary.Uvarint(data)
+	if n <= 0 || !plausibleSourceLen(sourceLen, len(data)) {
+		return 0, 0, ErrInvalidEncoding
+	}
+	data = data[n:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > sourceLen || len(data) == n || data[n] >= 64 {
+		return 0, 0, ErrInvalidEncoding
+	}
+	k := uint(data[n])
+	r := bitReader{data: data[n+1:]}
+	if count > uint64(len(r.data))*8 {
+		return 0, 0, ErrInvalidEncoding
+	}
+	next := uint64(0)
+	for i := uint64(0); i < count; i++ {
+		quotient, ok := r.readUnary()
+		if !ok || quotient > sourceLen>>k {
+			return 0, 0, ErrInvalidEncoding
+		}
+		remainder, ok := r.read(k)
+		if !ok {
+			return 0, 0, ErrInvalidEncoding
+		}
+		index := next + (quotient<<k | remainder)
+		if index >= sourceLen {
+			return 0, 0, ErrInvalidEncoding
+		}
+		if set != nil {
+			set(int(index))
+		}
+		next = index + 1
+	}
+	return int(sourceLen), int(count), nil
+}
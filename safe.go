@@ -0,0 +1,95 @@
+package quickfilter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IndexError is returned by the Try methods, and used as the panic value of
+// the other methods, when an index is negative or not less than the Cap() of
+// the QuickFilter. BitMatrix uses it likewise for a row or column outside
+// Rows() or Cols().
+type IndexError struct {
+	// Index is the offending index.
+	Index int
+	// Cap is the Cap() of the QuickFilter, or the Rows() or Cols() of the
+	// BitMatrix.
+	Cap int
+}
+
+func (err *IndexError) Error() string {
+	return fmt.Sprintf("quickfilter: index %d out of range for QuickFilter with Cap() %d", err.Index, err.Cap)
+}
+
+// ErrSizeMismatch is returned by the Try methods when QuickFilters that must
+// be the same size are not.
+var ErrSizeMismatch = errors.New("quickfilter: QuickFilters must be the same size")
+
+// TryAdd is like Add, but returns an *IndexError instead of panicking when
+// the index is out of range. Adding an index that is already set has no
+// effect.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) TryAdd(index int) (QuickFilter, error) {
+	if err := qf.checkIndex(index); err != nil {
+		return qf, err
+	}
+	return qf.Add(index), nil
+}
+
+// TryDelete is like Delete, but returns an *IndexError instead of
+// panicking when the index is out of range.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) TryDelete(index int) (QuickFilter, error) {
+	if err := qf.checkIndex(index); err != nil {
+		return qf, err
+	}
+	return qf.Delete(index), nil
+}
+
+// TryHas is like Has, but returns an *IndexError instead of panicking
+// when the index is out of range.
+func (qf QuickFilter) TryHas(index int) (bool, error) {
+	if err := qf.checkIndex(index); err != nil {
+		return false, err
+	}
+	return qf.Has(index), nil
+}
+
+// TryUnionOf is like UnionOf, but returns ErrSizeMismatch instead of
+// panicking when the QuickFilters are not all the same size.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) TryUnionOf(qf1, qf2 QuickFilter) (QuickFilter, error) {
+	if qf.sourceLen != qf1.sourceLen || qf.sourceLen != qf2.sourceLen {
+		return qf, ErrSizeMismatch
+	}
+	return qf.UnionOf(qf1, qf2), nil
+}
+
+// TryIntersectionOf is like IntersectionOf, but returns ErrSizeMismatch
+// instead of panicking when the QuickFilters are not all the same size.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) TryIntersectionOf(qf1, qf2 QuickFilter) (QuickFilter, error) {
+	if qf.sourceLen != qf1.sourceLen || qf.sourceLen != qf2.sourceLen {
+		return qf, ErrSizeMismatch
+	}
+	return qf.IntersectionOf(qf1, qf2), nil
+}
+
+func (qf QuickFilter) checkIndex(index int) error {
+	if uint(index) >= uint(qf.sourceLen) {
+		return &IndexError{Index: index, Cap: qf.sourceLen}
+	}
+	return nil
+}
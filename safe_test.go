@@ -0,0 +1,109 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestTry(t *testing.T) {
+	t.Run("TryAdd", func(t *testing.T) {
+		qf := quickfilter.New(10)
+		expected := []int{3}
+
+		qf, err := qf.TryAdd(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qf, err = qf.TryAdd(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != 1 {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		qf := quickfilter.New(10)
+		for _, index := range []int{-1, 10, 63, 64} {
+			expected := &quickfilter.IndexError{Index: index, Cap: 10}
+
+			_, errAdd := qf.TryAdd(index)
+			_, errDelete := qf.TryDelete(index)
+			_, errHas := qf.TryHas(index)
+
+			for _, received := range []error{errAdd, errDelete, errHas} {
+				if !reflect.DeepEqual(expected, received) {
+					t.Errorf("expected %v, got %v", expected, received)
+				}
+			}
+		}
+	})
+
+	t.Run("IndexError message", func(t *testing.T) {
+		_, err := quickfilter.New(10).TryAdd(12)
+		expected := "quickfilter: index 12 out of range for QuickFilter with Cap() 10"
+
+		received := err.Error()
+
+		if expected != received {
+			t.Errorf("expected %q, got %q", expected, received)
+		}
+	})
+
+	t.Run("TryDelete and TryHas", func(t *testing.T) {
+		qf := quickfilter.New(10).Add(3)
+		expected := false
+
+		qf, err := qf.TryDelete(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received, err := qf.TryHas(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("TryUnionOf and TryIntersectionOf", func(t *testing.T) {
+		qf1 := quickfilter.New(10).Add(1).Add(2)
+		qf2 := quickfilter.New(10).Add(2)
+		expected := []int{2}
+
+		qf, err := quickfilter.New(10).TryUnionOf(qf1, qf2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qf, err = qf.TryIntersectionOf(qf, qf2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("size mismatch", func(t *testing.T) {
+		qf := quickfilter.New(10)
+		expected := quickfilter.ErrSizeMismatch
+
+		_, errUnion := qf.TryUnionOf(qf, quickfilter.New(11))
+		_, errIntersection := qf.TryIntersectionOf(quickfilter.New(11), qf)
+
+		for _, received := range []error{errUnion, errIntersection} {
+			if expected != received {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+		}
+	})
+}
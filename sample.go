@@ -0,0 +1,168 @@
+package quickfilter
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// Sample returns k uniformly random distinct indices set in the QuickFilter,
+// in ascending order. If k is not less than Len(), all the set indices are
+// returned.
+//
+// The ranks of the sampled indices are chosen up front, so the cost is a
+// single pass over the words of the QuickFilter plus O(k log k), regardless
+// of the number of set indices.
+func (qf QuickFilter) Sample(k int, rng *rand.Rand) []int {
+	n := qf.Len()
+	if k >= n {
+		result := make([]int, 0, n)
+		for it := qf.Iterate(); !it.Done(); it = it.Next() {
+			result = append(result, it.Value())
+		}
+		return result
+	}
+	if k <= 0 {
+		return []int{}
+	}
+	// Floyd's algorithm for sampling k distinct ranks out of n.
+	chosen := make(map[int]struct{}, k)
+	ranks := make([]int, 0, k)
+	for j := n - k; j < n; j++ {
+		rank := rng.Intn(j + 1)
+		if _, ok := chosen[rank]; ok {
+			rank = j
+		}
+		chosen[rank] = struct{}{}
+		ranks = append(ranks, rank)
+	}
+	sort.Ints(ranks)
+	return qf.selectRanks(ranks)
+}
+
+// SampleWeighted returns k distinct indices set in the QuickFilter, in
+// ascending order, sampled without replacement with probabilities
+// proportional to weights[index]. Indices with a weight of zero or less are
+// never sampled. If fewer than k indices have a positive weight, all of them
+// are returned.
+//
+// The sampling is done in a single pass over the set indices using the
+// Efraimidis-Spirakis algorithm with a bounded heap, so the memory used is
+// proportional to k.
+//
+// weights must have a length equal to Cap() or this will panic.
+func (qf QuickFilter) SampleWeighted(k int, weights []float64, rng *rand.Rand) []int {
+	if len(weights) != qf.sourceLen {
+		panic("weights must be the same size as the QuickFilter")
+	}
+	if k <= 0 {
+		return []int{}
+	}
+	h := make(weightedHeap, 0, k)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		weight := weights[it.Value()]
+		if weight <= 0 {
+			continue
+		}
+		key := math.Log(1-rng.Float64()) / weight
+		if len(h) < k {
+			h = h.push(weightedEntry{key: key, index: it.Value()})
+		} else if key > h[0].key {
+			h[0] = weightedEntry{key: key, index: it.Value()}
+			h.down(0)
+		}
+	}
+	result := make([]int, len(h))
+	for i := range h {
+		result[i] = h[i].index
+	}
+	sort.Ints(result)
+	return result
+}
+
+// ShuffledIndices appends the set indices to dst in uniformly random order
+// and returns the extended slice, for example to randomize the processing
+// order of filtered work items.
+//
+// dst is grown at most once to fit exactly Len() more indices, and the
+// indices are shuffled while they are appended using the inside-out variant
+// of the Fisher-Yates shuffle, in a single pass.
+func (qf QuickFilter) ShuffledIndices(rng *rand.Rand, dst []int) []int {
+	start := len(dst)
+	dst = growInts(dst, qf.len)
+	dst = dst[:start+qf.len]
+	shuffled := dst[start:]
+	i := 0
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		j := rng.Intn(i + 1)
+		shuffled[i] = shuffled[j]
+		shuffled[j] = it.Value()
+		i++
+	}
+	return dst
+}
+
+// weightedHeap is a min-heap of sampling keys.
+type weightedHeap []weightedEntry
+
+type weightedEntry struct {
+	key   float64
+	index int
+}
+
+func (h weightedHeap) push(e weightedEntry) weightedHeap {
+	h = append(h, e)
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if h[parent].key <= h[i].key {
+			break
+		}
+		h[i], h[parent] = h[parent], h[i]
+		i = parent
+	}
+	return h
+}
+
+func (h weightedHeap) down(i int) {
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(h) && h[left].key < h[smallest].key {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(h) && h[right].key < h[smallest].key {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+}
+
+// selectRanks replaces each of the ascending ranks with the index of the set
+// bit of that rank.
+func (qf QuickFilter) selectRanks(ranks []int) []int {
+	last := len(qf.bits) - 1
+	r, seen := 0, 0
+	for i, word := range qf.bits {
+		if r == len(ranks) {
+			break
+		}
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		count := bits.OnesCount(word)
+		for r < len(ranks) && ranks[r] < seen+count {
+			w := word
+			for j := ranks[r] - seen; j > 0; j-- {
+				w &= w - 1
+			}
+			ranks[r] = i*bits.UintSize + bits.TrailingZeros(w)
+			r++
+		}
+		seen += count
+	}
+	return ranks
+}
@@ -0,0 +1,144 @@
+package quickfilter_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestSample(t *testing.T) {
+	t.Run("should return distinct set indices", func(t *testing.T) {
+		qf := quickfilter.New(1000)
+		for i := 0; i < qf.Cap(); i += 3 {
+			qf = qf.Add(i)
+		}
+		rng := rand.New(rand.NewSource(1))
+		expectedLen := 50
+
+		sample := qf.Sample(expectedLen, rng)
+
+		if expectedLen != len(sample) {
+			t.Errorf("expected %d, got %d", expectedLen, len(sample))
+		}
+		if !sort.IntsAreSorted(sample) {
+			t.Errorf("expected sorted indices, got %v", sample)
+		}
+		for i, index := range sample {
+			if !qf.Has(index) {
+				t.Errorf("unexpected index %d", index)
+			}
+			if i > 0 && sample[i-1] == index {
+				t.Errorf("duplicate index %d", index)
+			}
+		}
+	})
+
+	t.Run("should be uniform", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(3).Add(64).Add(65).Add(130).Add(199)
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[int]int)
+
+		for n := 0; n < 5000; n++ {
+			for _, index := range qf.Sample(2, rng) {
+				counts[index]++
+			}
+		}
+
+		for index, count := range counts {
+			if count < 1800 || count > 2200 {
+				t.Errorf("expected index %d to be sampled about 2000 times, got %d", index, count)
+			}
+		}
+	})
+
+	t.Run("k larger than Len", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(1).Add(99)
+		expected := []int{1, 99}
+
+		received := qf.Sample(10, rand.New(rand.NewSource(1)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
+
+func TestSampleWeighted(t *testing.T) {
+	t.Run("should only sample set indices with positive weight", func(t *testing.T) {
+		qf := quickfilter.New(10).Add(1).Add(2).Add(3).Add(4)
+		weights := []float64{5, 1, 0, 1, -1, 5, 5, 5, 5, 5}
+		expected := []int{1, 3}
+
+		received := qf.SampleWeighted(3, weights, rand.New(rand.NewSource(1)))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should be proportional to weights", func(t *testing.T) {
+		qf := quickfilter.New(4).Add(0).Add(1).Add(3)
+		weights := []float64{1, 3, 100, 0.5}
+		rng := rand.New(rand.NewSource(1))
+		counts := make([]int, 4)
+
+		for n := 0; n < 9000; n++ {
+			for _, index := range qf.SampleWeighted(1, weights, rng) {
+				counts[index]++
+			}
+		}
+
+		if counts[0] < 1700 || counts[0] > 2300 || counts[1] < 5700 || counts[1] > 6300 || counts[3] < 700 || counts[3] > 1300 {
+			t.Errorf("expected counts of about [2000 6000 0 1000], got %v", counts)
+		}
+	})
+}
+
+func TestShuffledIndices(t *testing.T) {
+	t.Run("should return a permutation of the set indices", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(3).Add(64).Add(65).Add(130).Add(199)
+		expected := []int{-1, 3, 64, 65, 130, 199}
+
+		received := qf.ShuffledIndices(rand.New(rand.NewSource(1)), []int{-1})
+		sort.Ints(received)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("should allocate exactly", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(3).Add(64).Add(65).Add(130).Add(199)
+		expectedCap := 6
+
+		received := qf.ShuffledIndices(rand.New(rand.NewSource(1)), []int{-1})
+
+		if expectedCap != cap(received) {
+			t.Errorf("expected %d, got %d", expectedCap, cap(received))
+		}
+	})
+
+	t.Run("should be uniform", func(t *testing.T) {
+		qf := quickfilter.New(10).Add(1).Add(5).Add(9)
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[[3]int]int)
+		buf := make([]int, 0, 3)
+
+		for n := 0; n < 6000; n++ {
+			buf = qf.ShuffledIndices(rng, buf[:0])
+			counts[[3]int{buf[0], buf[1], buf[2]}]++
+		}
+
+		if len(counts) != 6 {
+			t.Errorf("expected 6 permutations, got %d", len(counts))
+		}
+		for permutation, count := range counts {
+			if count < 850 || count > 1150 {
+				t.Errorf("expected permutation %v about 1000 times, got %d", permutation, count)
+			}
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package quickfilter
+
+// Scorer accumulates a score per index across multiple weighted criteria, and
+// produces QuickFilters of the indices whose score exceeds a threshold. The
+// scores are stored in a single slice allocated up front.
+type Scorer struct {
+	scores []float64
+}
+
+// NewScorer returns a new Scorer with a zero score for each of the sourceLen
+// offsets.
+func NewScorer(sourceLen int) Scorer {
+	return Scorer{scores: make([]float64, sourceLen)}
+}
+
+// Cap returns the number of scores.
+func (s Scorer) Cap() int {
+	return len(s.scores)
+}
+
+// Score returns the accumulated score of the index.
+func (s Scorer) Score(index int) float64 {
+	return s.scores[index]
+}
+
+// Add score to the index.
+//
+// The original Scorer is no longer usable and must be replaced with the
+// returned one. This approach prevents the Scorer from escaping to the heap.
+func (s Scorer) Add(index int, score float64) Scorer {
+	s.scores[index] += score
+	return s
+}
+
+// AddWhere adds weight to the score of every index set in qf.
+//
+// The passed QuickFilter must have a Cap() equal to the Cap() of the Scorer
+// or this will panic.
+//
+// The original Scorer is no longer usable and must be replaced with the
+// returned one. This approach prevents the Scorer from escaping to the heap.
+func (s Scorer) AddWhere(weight float64, qf QuickFilter) Scorer {
+	if qf.sourceLen != len(s.scores) {
+		panic("QuickFilter must be the same size as the Scorer")
+	}
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		s.scores[it.Value()] += weight
+	}
+	return s
+}
+
+// AddFunc adds weight multiplied by score(i) to the score of every index i.
+//
+// The original Scorer is no longer usable and must be replaced with the
+// returned one. This approach prevents the Scorer from escaping to the heap.
+func (s Scorer) AddFunc(weight float64, score func(i int) float64) Scorer {
+	for i := range s.scores {
+		s.scores[i] += weight * score(i)
+	}
+	return s
+}
+
+// Clear all the scores.
+//
+// The original Scorer is no longer usable and must be replaced with the
+// returned one. This approach prevents the Scorer from escaping to the heap.
+func (s Scorer) Clear() Scorer {
+	for i := range s.scores {
+		s.scores[i] = 0
+	}
+	return s
+}
+
+// Above fills dst with the indices whose score is greater than threshold. dst
+// is resized to the Cap() of the Scorer.
+func (s Scorer) Above(dst QuickFilter, threshold float64) QuickFilter {
+	dst = dst.Resize(len(s.scores)).Clear()
+	for i, score := range s.scores {
+		if score > threshold {
+			dst = dst.Add(i)
+		}
+	}
+	return dst
+}
+
+// TopKByScore returns the indices of the k highest scores among the indices
+// set in selection, highest first, along with dst filled with the same
+// indices. Ties are broken in favor of the lower index. dst is resized to the
+// Cap() of the Scorer.
+//
+// The selection is scanned with a bounded heap, so the memory used is
+// proportional to k rather than to the size of the selection.
+//
+// The passed selection must have a Cap() equal to the Cap() of the Scorer or
+// this will panic.
+func (s Scorer) TopKByScore(dst, selection QuickFilter, k int) ([]int, QuickFilter) {
+	if selection.sourceLen != len(s.scores) {
+		panic("QuickFilter must be the same size as the Scorer")
+	}
+	if k > selection.len {
+		k = selection.len
+	}
+	if k < 0 {
+		k = 0
+	}
+	h := scoreHeap{scores: s.scores, indices: make([]int, 0, k)}
+	for it := selection.Iterate(); !it.Done() && k > 0; it = it.Next() {
+		index := it.Value()
+		if len(h.indices) < k {
+			h.push(index)
+		} else if h.worse(h.indices[0], index) {
+			h.indices[0] = index
+			h.down(0)
+		}
+	}
+	top := h.indices
+	for n := len(top) - 1; n > 0; n-- {
+		top[0], top[n] = top[n], top[0]
+		h.indices = top[:n]
+		h.down(0)
+	}
+	dst = dst.Resize(len(s.scores)).Clear()
+	for _, index := range top {
+		dst = dst.Add(index)
+	}
+	return top, dst
+}
+
+// scoreHeap is a min-heap of indices by score, keeping the worst of the
+// indices at the root.
+type scoreHeap struct {
+	scores  []float64
+	indices []int
+}
+
+func (h *scoreHeap) worse(a, b int) bool {
+	if h.scores[a] != h.scores[b] {
+		return h.scores[a] < h.scores[b]
+	}
+	return a > b
+}
+
+func (h *scoreHeap) push(index int) {
+	h.indices = append(h.indices, index)
+	for i := len(h.indices) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.worse(h.indices[i], h.indices[parent]) {
+			break
+		}
+		h.indices[i], h.indices[parent] = h.indices[parent], h.indices[i]
+		i = parent
+	}
+}
+
+func (h *scoreHeap) down(i int) {
+	n := len(h.indices)
+	for {
+		worst := i
+		if left := 2*i + 1; left < n && h.worse(h.indices[left], h.indices[worst]) {
+			worst = left
+		}
+		if right := 2*i + 2; right < n && h.worse(h.indices[right], h.indices[worst]) {
+			worst = right
+		}
+		if worst == i {
+			return
+		}
+		h.indices[i], h.indices[worst] = h.indices[worst], h.indices[i]
+		i = worst
+	}
+}
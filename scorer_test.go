@@ -0,0 +1,122 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestScorer(t *testing.T) {
+	t.Run("Add and Score", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expectedScore := 1.5
+
+		s = s.Add(3, 1).Add(3, 0.5)
+		receivedScore := s.Score(3)
+
+		if expectedScore != receivedScore {
+			t.Errorf("expected %v, got %v", expectedScore, receivedScore)
+		}
+	})
+
+	t.Run("Above", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expected := []int{0, 6}
+
+		s = s.AddWhere(2, quickfilter.New(10).Add(0).Add(2).Add(4).Add(6))
+		s = s.AddWhere(1, quickfilter.New(10).Add(0).Add(3).Add(6).Add(9))
+		s = s.AddFunc(0.5, func(i int) float64 { return float64(i % 2) })
+		received := collect(s.Above(quickfilter.New(0), 2))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expectedLen := 0
+
+		s = s.AddFunc(1, func(i int) float64 { return float64(i) }).Clear()
+		receivedLen := s.Above(quickfilter.New(0), 0).Len()
+
+		if expectedLen != receivedLen {
+			t.Errorf("expected %d, got %d", expectedLen, receivedLen)
+		}
+	})
+}
+
+func TestScorerTopKByScore(t *testing.T) {
+	t.Run("top k", func(t *testing.T) {
+		s := quickfilter.NewScorer(100)
+		s = s.AddFunc(1, func(i int) float64 { return float64((i * 37) % 101) })
+		expected := []int{30, 60, 90}
+
+		indices, qf := s.TopKByScore(quickfilter.New(0), quickfilter.NewFilled(100), 3)
+
+		if !reflect.DeepEqual(expected, indices) {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+		if !reflect.DeepEqual(expected, collect(qf)) {
+			t.Errorf("expected %v, got %v", expected, collect(qf))
+		}
+	})
+
+	t.Run("should order by score", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		s = s.AddFunc(1, func(i int) float64 { return float64(i % 7) })
+		expected := []int{6, 5, 4, 3}
+
+		indices, _ := s.TopKByScore(quickfilter.New(0), quickfilter.NewFilled(10), 4)
+
+		if !reflect.DeepEqual(expected, indices) {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+	})
+
+	t.Run("ties and selection", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		s = s.Add(1, 5).Add(2, 5).Add(3, 5).Add(4, 9)
+		expected := []int{2, 3}
+
+		indices, _ := s.TopKByScore(quickfilter.New(0), quickfilter.New(10).Add(2).Add(3).Add(5), 2)
+
+		if !reflect.DeepEqual(expected, indices) {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+	})
+
+	t.Run("k larger than selection", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expected := []int{1, 7}
+
+		indices, _ := s.TopKByScore(quickfilter.New(0), quickfilter.New(10).Add(1).Add(7), 5)
+
+		if !reflect.DeepEqual(expected, indices) {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+	})
+
+	t.Run("k larger than the set bits", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expected := []int{7}
+
+		indices, _ := s.TopKByScore(quickfilter.New(0), quickfilter.New(10).Add(7).Add(7), 5)
+
+		if !reflect.DeepEqual(expected, indices) {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+	})
+
+	t.Run("negative k", func(t *testing.T) {
+		s := quickfilter.NewScorer(10)
+		expected := []int{}
+
+		indices, qf := s.TopKByScore(quickfilter.New(0), quickfilter.NewFilled(10), -1)
+
+		if len(indices) != 0 || qf.Len() != 0 {
+			t.Errorf("expected %v, got %v", expected, indices)
+		}
+	})
+}
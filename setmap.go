@@ -0,0 +1,29 @@
+package quickfilter
+
+// ToSetMap returns the set indices as a map-based set, for interoperating
+// with code that represents index sets as maps.
+func (qf QuickFilter) ToSetMap() map[int]struct{} {
+	m := make(map[int]struct{}, qf.len)
+	for it := qf.Iterate(); !it.Done(); it = it.Next() {
+		m[it.Value()] = struct{}{}
+	}
+	return m
+}
+
+// NewFromSetMap returns a new QuickFilter of sourceLen offsets where the
+// indices in the map-based set m are stored.
+//
+// The indices must be at least zero and less than sourceLen or this will
+// panic with an *IndexError.
+func NewFromSetMap(sourceLen int, m map[int]struct{}) QuickFilter {
+	qf := New(sourceLen)
+	for index := range m {
+		qf.mustIndex(index)
+	}
+	for index := range m {
+		index, mask := offsets(index)
+		qf.bits[index] |= mask
+	}
+	qf.len = len(m)
+	return qf
+}
@@ -0,0 +1,42 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestSetMap(t *testing.T) {
+	t.Run("ToSetMap", func(t *testing.T) {
+		qf := quickfilter.NewFromIndices(100, []int{3, 64, 99})
+		expected := map[int]struct{}{3: {}, 64: {}, 99: {}}
+
+		received := qf.ToSetMap()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("NewFromSetMap", func(t *testing.T) {
+		expected := []int{3, 64, 99}
+
+		qf := quickfilter.NewFromSetMap(100, map[int]struct{}{99: {}, 3: {}, 64: {}})
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) || qf.Len() != len(expected) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("NewFromSetMap out of range should panic", func(t *testing.T) {
+		defer func() {
+			if _, ok := recover().(*quickfilter.IndexError); !ok {
+				t.Error("expected a panic with an IndexError")
+			}
+		}()
+
+		quickfilter.NewFromSetMap(100, map[int]struct{}{100: {}})
+	})
+}
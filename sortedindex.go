@@ -0,0 +1,102 @@
+package quickfilter
+
+import (
+	"sort"
+)
+
+// SortedIndex is an index over a numeric field of a source slice, stored as
+// the sort permutation of the values. Range predicates are answered by
+// binary searching the sorted order and setting the indices of the matching
+// run of it, at a cost of O(log n) plus the number of matching elements.
+//
+// Like BitSlicedIndex, SortedIndex is built once from the source slice and
+// needs to be rebuilt when the slice changes. The values must not contain
+// NaNs.
+type SortedIndex struct {
+	values []float64
+	perm   []int
+}
+
+// NewSortedIndex returns a new SortedIndex of values, where perm is the sort
+// permutation of values: values[perm[0]] is the smallest value and
+// values[perm[len(perm)-1]] the largest. A perm that is already maintained
+// alongside the column, such as by the ordering of a table, can be passed to
+// avoid sorting; if perm is nil, it is computed. Neither slice is copied, and
+// they must not be modified while the SortedIndex is in use.
+//
+// perm must have the same length as values or this will panic.
+func NewSortedIndex(values []float64, perm []int) SortedIndex {
+	if perm == nil {
+		perm = make([]int, len(values))
+		for i := range perm {
+			perm[i] = i
+		}
+		sort.SliceStable(perm, func(a, b int) bool {
+			return values[perm[a]] < values[perm[b]]
+		})
+	}
+	if len(perm) != len(values) {
+		panic("perm must be the same size as values")
+	}
+	return SortedIndex{values: values, perm: perm}
+}
+
+// Cap returns the length of the indexed source slice.
+func (si SortedIndex) Cap() int {
+	return len(si.values)
+}
+
+// LessThan fills dst with the elements whose value is less than v. dst is
+// resized to the Cap() of the SortedIndex.
+func (si SortedIndex) LessThan(dst QuickFilter, v float64) QuickFilter {
+	return si.fill(dst, 0, si.search(func(x float64) bool { return x >= v }))
+}
+
+// LessOrEqual fills dst with the elements whose value is less than or equal
+// to v. dst is resized to the Cap() of the SortedIndex.
+func (si SortedIndex) LessOrEqual(dst QuickFilter, v float64) QuickFilter {
+	return si.fill(dst, 0, si.search(func(x float64) bool { return x > v }))
+}
+
+// GreaterThan fills dst with the elements whose value is greater than v. dst
+// is resized to the Cap() of the SortedIndex.
+func (si SortedIndex) GreaterThan(dst QuickFilter, v float64) QuickFilter {
+	return si.fill(dst, si.search(func(x float64) bool { return x > v }), len(si.perm))
+}
+
+// GreaterOrEqual fills dst with the elements whose value is greater than or
+// equal to v. dst is resized to the Cap() of the SortedIndex.
+func (si SortedIndex) GreaterOrEqual(dst QuickFilter, v float64) QuickFilter {
+	return si.fill(dst, si.search(func(x float64) bool { return x >= v }), len(si.perm))
+}
+
+// Between fills dst with the elements whose value is between lo and hi,
+// inclusive. dst is resized to the Cap() of the SortedIndex.
+func (si SortedIndex) Between(dst QuickFilter, lo, hi float64) QuickFilter {
+	from := si.search(func(x float64) bool { return x >= lo })
+	to := si.search(func(x float64) bool { return x > hi })
+	return si.fill(dst, from, to)
+}
+
+// search returns the smallest position in the sorted order whose value
+// satisfies f, or the Cap() if there is none.
+func (si SortedIndex) search(f func(x float64) bool) int {
+	return sort.Search(len(si.perm), func(k int) bool {
+		return f(si.values[si.perm[k]])
+	})
+}
+
+// fill fills dst with the elements at the positions from (inclusive) to to
+// (exclusive) in the sorted order.
+func (si SortedIndex) fill(dst QuickFilter, from, to int) QuickFilter {
+	if to < from {
+		to = from
+	}
+	dst = dst.Resize(len(si.values)).Clear()
+	for _, index := range si.perm[from:to] {
+		wordIndex, mask := offsets(index)
+		dst.bits[wordIndex] |= mask
+	}
+	dst.len = to - from
+	return dst
+}
@@ -0,0 +1,47 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestSortedIndex(t *testing.T) {
+	prices := []float64{9.5, 3, 7, 3, 1, 12, 7}
+	si := quickfilter.NewSortedIndex(prices, nil)
+	tests := []struct {
+		name     string
+		eval     func(dst quickfilter.QuickFilter) quickfilter.QuickFilter
+		expected []int
+	}{
+		{"LessThan", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.LessThan(dst, 7) }, []int{1, 3, 4}},
+		{"LessOrEqual", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.LessOrEqual(dst, 7) }, []int{1, 2, 3, 4, 6}},
+		{"GreaterThan", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.GreaterThan(dst, 7) }, []int{0, 5}},
+		{"GreaterOrEqual", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.GreaterOrEqual(dst, 7) }, []int{0, 2, 5, 6}},
+		{"Between", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.Between(dst, 3, 9.5) }, []int{0, 1, 2, 3, 6}},
+		{"Between reversed", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.Between(dst, 9, 3) }, []int{}},
+		{"Between none", func(dst quickfilter.QuickFilter) quickfilter.QuickFilter { return si.Between(dst, 13, 20) }, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qf := tt.eval(quickfilter.NewFilled(100))
+			received := collect(qf)
+
+			if !reflect.DeepEqual(tt.expected, received) || qf.Cap() != len(prices) || qf.Validate() != nil {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+		})
+	}
+
+	t.Run("given permutation", func(t *testing.T) {
+		si := quickfilter.NewSortedIndex([]float64{5, 1, 3}, []int{1, 2, 0})
+		expected := []int{0, 2}
+
+		received := collect(si.GreaterThan(quickfilter.New(0), 2))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package quickfilter
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// Density returns the fraction of the offsets up to Cap() that are stored,
+// or zero if the Cap() is zero.
+func (qf QuickFilter) Density() float64 {
+	if qf.sourceLen == 0 {
+		return 0
+	}
+	return float64(qf.len) / float64(qf.sourceLen)
+}
+
+// SizeBytes returns the memory used by the QuickFilter in bytes: the full
+// capacity of its backing buffer, which may be larger than needed for Cap(),
+// plus the QuickFilter value itself. QuickFilters that share a backing buffer
+// each report its full size.
+func (qf QuickFilter) SizeBytes() int {
+	return cap(qf.bits)*(bits.UintSize/8) + int(unsafe.Sizeof(qf))
+}
+
+// Stats contains summary statistics of a QuickFilter.
+type Stats struct {
+	// Len is the number of set values.
+	Len int
+	// Cap is the source length.
+	Cap int
+	// Words is the number of words used for the bits.
+	Words int
+	// Density is Len divided by Cap, or zero if Cap is zero.
+	Density float64
+	// First is the smallest set value, or -1 if there are none.
+	First int
+	// Last is the largest set value, or -1 if there are none.
+	Last int
+}
+
+// Stats returns the summary statistics of the QuickFilter, computed in a
+// single pass over its words. Len is counted from the bits rather than taken
+// from Len().
+func (qf QuickFilter) Stats() Stats {
+	stats := Stats{Cap: qf.sourceLen, Words: len(qf.bits), First: -1, Last: -1}
+	last := len(qf.bits) - 1
+	for i, word := range qf.bits {
+		if i == last {
+			word &= lastWordMask(qf.sourceLen)
+		}
+		if word == 0 {
+			continue
+		}
+		if stats.First < 0 {
+			stats.First = i*bits.UintSize + bits.TrailingZeros(word)
+		}
+		stats.Last = i*bits.UintSize + bits.Len(word) - 1
+		stats.Len += bits.OnesCount(word)
+	}
+	if stats.Cap > 0 {
+		stats.Density = float64(stats.Len) / float64(stats.Cap)
+	}
+	return stats
+}
+
+// RunStats contains statistics of the runs of consecutive set values in a
+// QuickFilter, which are useful for choosing between dense, run-length and
+// sparse representations.
+type RunStats struct {
+	// Runs is the number of runs of set values.
+	Runs int
+	// LongestRun is the length of the longest run of set values.
+	LongestRun int
+	// GapHistogram counts the gaps of unset values between runs by size:
+	// GapHistogram[k] is the number of gaps with a length from 2^k up to
+	// 2^(k+1)-1. The gaps before the first run and after the last run are
+	// not counted.
+	GapHistogram []int
+}
+
+// RunStats returns the statistics of the runs of set values in the
+// QuickFilter.
+func (qf QuickFilter) RunStats() RunStats {
+	var stats RunStats
+	prevHi := -1
+	qf.eachRun(func(lo, hi int) {
+		stats.Runs++
+		if hi-lo > stats.LongestRun {
+			stats.LongestRun = hi - lo
+		}
+		if prevHi >= 0 {
+			k := bits.Len(uint(lo-prevHi)) - 1
+			for len(stats.GapHistogram) <= k {
+				stats.GapHistogram = append(stats.GapHistogram, 0)
+			}
+			stats.GapHistogram[k]++
+		}
+		prevHi = hi
+	})
+	return stats
+}
@@ -0,0 +1,115 @@
+package quickfilter_test
+
+import (
+	"math/bits"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Density", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(1).Add(2)
+		expected := 0.01
+
+		received := qf.Density()
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Density of zero Cap", func(t *testing.T) {
+		expected := 0.0
+
+		received := quickfilter.New(0).Density()
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("SizeBytes", func(t *testing.T) {
+		qf := quickfilter.New(1000).Resize(10)
+		expected := (1000+bits.UintSize-1)/bits.UintSize*(bits.UintSize/8) + int(unsafe.Sizeof(qf))
+
+		received := qf.SizeBytes()
+
+		if expected != received {
+			t.Errorf("expected %d, got %d", expected, received)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		qf := quickfilter.New(200).Add(70).Add(5).Add(130)
+		expected := quickfilter.Stats{
+			Len:     3,
+			Cap:     200,
+			Words:   (200 + bits.UintSize - 1) / bits.UintSize,
+			Density: 0.015,
+			First:   5,
+			Last:    130,
+		}
+
+		received := qf.Stats()
+
+		if expected != received {
+			t.Errorf("expected %+v, got %+v", expected, received)
+		}
+	})
+
+	t.Run("Stats of filled", func(t *testing.T) {
+		qf := quickfilter.NewFilled(70)
+		expectedLen, expectedLast := 70, 69
+
+		stats := qf.Stats()
+		receivedLen, receivedLast := stats.Len, stats.Last
+
+		if expectedLen != receivedLen || expectedLast != receivedLast {
+			t.Errorf("expected %d and %d, got %d and %d", expectedLen, expectedLast, receivedLen, receivedLast)
+		}
+	})
+
+	t.Run("Stats of empty", func(t *testing.T) {
+		expectedFirst, expectedLast := -1, -1
+
+		stats := quickfilter.New(100).Stats()
+		receivedFirst, receivedLast := stats.First, stats.Last
+
+		if expectedFirst != receivedFirst || expectedLast != receivedLast {
+			t.Errorf("expected %d and %d, got %d and %d", expectedFirst, expectedLast, receivedFirst, receivedLast)
+		}
+	})
+
+	t.Run("RunStats", func(t *testing.T) {
+		qf := quickfilter.New(300)
+		for _, interval := range []quickfilter.Interval{{0, 1}, {2, 5}, {8, 70}, {200, 203}, {298, 300}} {
+			for i := interval.Lo; i < interval.Hi; i++ {
+				qf = qf.Add(i)
+			}
+		}
+		expected := quickfilter.RunStats{
+			Runs:         5,
+			LongestRun:   62,
+			GapHistogram: []int{1, 1, 0, 0, 0, 0, 1, 1},
+		}
+
+		received := qf.RunStats()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %+v, got %+v", expected, received)
+		}
+	})
+
+	t.Run("RunStats of empty", func(t *testing.T) {
+		expected := quickfilter.RunStats{}
+
+		received := quickfilter.New(100).RunStats()
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %+v, got %+v", expected, received)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// Stream builds a QuickFilter over a source whose length isn't known up
+// front, such as a stream of log lines. Each item of the source is observed
+// in order as either kept or dropped, growing the source length by one, and
+// the backing buffer grows as needed with amortized allocations.
+type Stream struct {
+	qf QuickFilter
+}
+
+// NewStream returns a new empty Stream with enough space reserved for
+// sizeHint items before it needs to grow.
+func NewStream(sizeHint int) Stream {
+	lastIndex, _ := offsets(sizeHint - 1)
+	return Stream{qf: QuickFilter{bits: make([]uint, 0, lastIndex+1)}}
+}
+
+// ObserveKept records the next item of the source as kept.
+//
+// The original Stream is no longer usable and must be replaced with the
+// returned one. This approach prevents the Stream from escaping to the heap.
+func (s Stream) ObserveKept() Stream {
+	index := s.qf.sourceLen
+	s = s.ObserveDropped()
+	s.qf = s.qf.Add(index)
+	return s
+}
+
+// ObserveDropped records the next item of the source as dropped.
+//
+// The original Stream is no longer usable and must be replaced with the
+// returned one. This approach prevents the Stream from escaping to the heap.
+func (s Stream) ObserveDropped() Stream {
+	if s.qf.sourceLen == len(s.qf.bits)*bits.UintSize {
+		s.qf.bits = append(s.qf.bits, 0)
+	}
+	s.qf.sourceLen++
+	return s
+}
+
+// Len returns the number of items kept so far.
+func (s Stream) Len() int {
+	return s.qf.len
+}
+
+// Cap returns the number of items observed so far.
+func (s Stream) Cap() int {
+	return s.qf.sourceLen
+}
+
+// Freeze returns the QuickFilter of the kept items, with a Cap() equal to the
+// number of items observed. The Stream must not be used after Freeze.
+func (s Stream) Freeze() QuickFilter {
+	if len(s.qf.bits) == 0 {
+		return New(0)
+	}
+	return s.qf
+}
@@ -0,0 +1,48 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestStream(t *testing.T) {
+	t.Run("Freeze", func(t *testing.T) {
+		s := quickfilter.NewStream(0)
+		expected := []int{}
+		expectedCap := 200
+
+		for i := 0; i < expectedCap; i++ {
+			if i%3 == 0 {
+				s = s.ObserveKept()
+				expected = append(expected, i)
+			} else {
+				s = s.ObserveDropped()
+			}
+		}
+		qf := s.Freeze()
+		received := collect(qf)
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if expectedCap != qf.Cap() {
+			t.Errorf("expected %d, got %d", expectedCap, qf.Cap())
+		}
+		if len(expected) != qf.Len() {
+			t.Errorf("expected %d, got %d", len(expected), qf.Len())
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		s := quickfilter.NewStream(100)
+		expectedCap := 0
+
+		receivedCap := s.Freeze().Cap()
+
+		if expectedCap != receivedCap {
+			t.Errorf("expected %d, got %d", expectedCap, receivedCap)
+		}
+	})
+}
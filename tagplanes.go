@@ -0,0 +1,125 @@
+package quickfilter
+
+// TagPlanes stores a small unsigned integer tag, such as a category or a
+// state, for each index as parallel bit planes: plane b is a QuickFilter of
+// the indices whose tag has bit b set. Queries on the tags are answered by
+// combining the planes a word at a time, keeping categorical metadata in the
+// same representation as the QuickFilters it is combined with.
+type TagPlanes struct {
+	sourceLen int
+	planes    []QuickFilter
+}
+
+// NewTagPlanes returns a new TagPlanes with a tag of tagBits bits for each of
+// the sourceLen offsets, all initially zero. tagBits must be between 1 and 8,
+// allowing tags up to 1, 3, 7, ... 255, or this will panic.
+func NewTagPlanes(sourceLen, tagBits int) TagPlanes {
+	if tagBits < 1 || tagBits > 8 {
+		panic("tagBits must be between 1 and 8")
+	}
+	planes := make([]QuickFilter, tagBits)
+	for i := range planes {
+		planes[i] = New(sourceLen)
+	}
+	return TagPlanes{sourceLen: sourceLen, planes: planes}
+}
+
+// Cap returns the number of tags.
+func (tp TagPlanes) Cap() int {
+	return tp.sourceLen
+}
+
+// MaxTag returns the largest tag that can be stored.
+func (tp TagPlanes) MaxTag() int {
+	return 1<<uint(len(tp.planes)) - 1
+}
+
+// Tag returns the tag of the index.
+func (tp TagPlanes) Tag(index int) int {
+	tag := 0
+	for b, plane := range tp.planes {
+		if plane.Has(index) {
+			tag |= 1 << uint(b)
+		}
+	}
+	return tag
+}
+
+// SetTag sets the tag of the index.
+//
+// The tag must be between 0 and MaxTag() or this will panic.
+//
+// The original TagPlanes is no longer usable and must be replaced with the
+// returned one. This approach prevents the TagPlanes from escaping to the
+// heap.
+func (tp TagPlanes) SetTag(index, tag int) TagPlanes {
+	tp.mustTag(tag)
+	for b, plane := range tp.planes {
+		switch {
+		case tag&(1<<uint(b)) == 0:
+			tp.planes[b] = plane.Delete(index)
+		case !plane.Has(index):
+			tp.planes[b] = plane.Add(index)
+		}
+	}
+	return tp
+}
+
+// Plane returns the QuickFilter of the indices whose tag has the given bit
+// set. The returned QuickFilter is owned by the TagPlanes and must not be
+// modified.
+func (tp TagPlanes) Plane(bit int) QuickFilter {
+	return tp.planes[bit]
+}
+
+// WhereTagEquals fills dst with the indices whose tag equals tag. dst is
+// resized to the Cap() of the TagPlanes.
+//
+// The tag must be between 0 and MaxTag() or this will panic.
+func (tp TagPlanes) WhereTagEquals(dst QuickFilter, tag int) QuickFilter {
+	tp.mustTag(tag)
+	dst = dst.Resize(tp.sourceLen)
+	for i := range dst.bits {
+		dst.bits[i] = tp.equalsWord(i, tag)
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(tp.sourceLen)
+	return dst.Recount()
+}
+
+// WhereTagIn fills dst with the indices whose tag equals any of tags. dst is
+// resized to the Cap() of the TagPlanes.
+//
+// The tags must be between 0 and MaxTag() or this will panic.
+func (tp TagPlanes) WhereTagIn(dst QuickFilter, tags ...int) QuickFilter {
+	for _, tag := range tags {
+		tp.mustTag(tag)
+	}
+	dst = dst.Resize(tp.sourceLen)
+	for i := range dst.bits {
+		word := uint(0)
+		for _, tag := range tags {
+			word |= tp.equalsWord(i, tag)
+		}
+		dst.bits[i] = word
+	}
+	dst.bits[len(dst.bits)-1] &= lastWordMask(tp.sourceLen)
+	return dst.Recount()
+}
+
+func (tp TagPlanes) equalsWord(i, tag int) uint {
+	word := ^uint(0)
+	for b, plane := range tp.planes {
+		if tag&(1<<uint(b)) == 0 {
+			word &^= plane.bits[i]
+		} else {
+			word &= plane.bits[i]
+		}
+	}
+	return word
+}
+
+func (tp TagPlanes) mustTag(tag int) {
+	if tag < 0 || tag > tp.MaxTag() {
+		panic("tag out of range for TagPlanes")
+	}
+}
@@ -0,0 +1,85 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestTagPlanes(t *testing.T) {
+	newTagPlanes := func() quickfilter.TagPlanes {
+		tp := quickfilter.NewTagPlanes(100, 4)
+		for i := 0; i < 100; i++ {
+			tp = tp.SetTag(i, i%16)
+		}
+		return tp
+	}
+
+	t.Run("Tag", func(t *testing.T) {
+		tp := newTagPlanes()
+		expected := 13
+
+		received := tp.Tag(77)
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("SetTag overwrites", func(t *testing.T) {
+		tp := newTagPlanes()
+		expected := 2
+
+		tp = tp.SetTag(77, 2)
+		received := tp.Tag(77)
+
+		if expected != received {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("WhereTagEquals", func(t *testing.T) {
+		tp := newTagPlanes()
+		expected := []int{5, 21, 37, 53, 69, 85}
+
+		received := collect(tp.WhereTagEquals(quickfilter.New(0), 5))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("WhereTagEquals zero", func(t *testing.T) {
+		tp := newTagPlanes()
+		expected := []int{0, 16, 32, 48, 64, 80, 96}
+
+		received := collect(tp.WhereTagEquals(quickfilter.New(0), 0))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("WhereTagIn", func(t *testing.T) {
+		tp := newTagPlanes()
+		expected := []int{14, 15, 30, 31, 46, 47, 62, 63, 78, 79, 94, 95}
+
+		received := collect(tp.WhereTagIn(quickfilter.New(0), 14, 15))
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("tag out of range should panic", func(t *testing.T) {
+		tp := newTagPlanes()
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		tp.SetTag(0, 16)
+	})
+}
@@ -0,0 +1,173 @@
+package quickfilter
+
+// Tri is a three-valued truth value, as produced by predicates over nullable
+// columns.
+type Tri uint8
+
+// The three truth values. The zero value is TriUnknown.
+const (
+	TriUnknown Tri = iota
+	TriFalse
+	TriTrue
+)
+
+// String returns the name of the truth value.
+func (v Tri) String() string {
+	switch v {
+	case TriFalse:
+		return "false"
+	case TriTrue:
+		return "true"
+	default:
+		return "unknown"
+	}
+}
+
+// TriFilter stores a three-valued truth value for each index as two planes:
+// the QuickFilter of the indices whose value is known, and the QuickFilter of
+// the indices whose value is true, which is a subset of the known ones. The
+// planes are combined a word at a time with the three-valued logic of SQL,
+// so that NOT of an unknown stays unknown instead of being conflated with
+// false.
+type TriFilter struct {
+	known QuickFilter
+	truth QuickFilter
+}
+
+// NewTriFilter returns a new TriFilter for sourceLen offsets, all initially
+// unknown.
+func NewTriFilter(sourceLen int) TriFilter {
+	return TriFilter{known: New(sourceLen), truth: New(sourceLen)}
+}
+
+// NewTriFilterFrom returns a new TriFilter whose known indices are those of
+// known and whose true indices are those of truth that are also known, for
+// example with known being the non-null rows of a column and truth the rows
+// matching a predicate. The QuickFilters are copied.
+//
+// The passed QuickFilters must be the same size or this will panic.
+func NewTriFilterFrom(truth, known QuickFilter) TriFilter {
+	return TriFilter{
+		known: known.Copy(),
+		truth: truth.Copy().IntersectionOf(truth, known),
+	}
+}
+
+// Cap returns the number of values.
+func (tf TriFilter) Cap() int {
+	return tf.known.sourceLen
+}
+
+// Get returns the value of the index.
+func (tf TriFilter) Get(index int) Tri {
+	switch {
+	case tf.truth.Has(index):
+		return TriTrue
+	case tf.known.Has(index):
+		return TriFalse
+	default:
+		return TriUnknown
+	}
+}
+
+// Set sets the value of the index.
+//
+// The original TriFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the TriFilter from escaping to the
+// heap.
+func (tf TriFilter) Set(index int, v Tri) TriFilter {
+	switch v {
+	case TriTrue:
+		tf.known = tf.known.Add(index)
+		tf.truth = tf.truth.Add(index)
+	case TriFalse:
+		tf.known = tf.known.Add(index)
+		tf.truth = tf.truth.Delete(index)
+	default:
+		tf.known = tf.known.Delete(index)
+		tf.truth = tf.truth.Delete(index)
+	}
+	return tf
+}
+
+// And sets the value of each index to the three-valued AND of its value and
+// that in tf2: false if either is false, otherwise unknown if either is
+// unknown, otherwise true.
+//
+// The receiver and passed TriFilter must be the same size or this will panic.
+//
+// The original TriFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the TriFilter from escaping to the
+// heap.
+func (tf TriFilter) And(tf2 TriFilter) TriFilter {
+	tf.mustSameSize(tf2)
+	for i := range tf.known.bits {
+		truth := tf.truth.bits[i] & tf2.truth.bits[i]
+		falsity := tf.falsityWord(i) | tf2.falsityWord(i)
+		tf.truth.bits[i] = truth
+		tf.known.bits[i] = truth | falsity
+	}
+	return tf.recount()
+}
+
+// Or sets the value of each index to the three-valued OR of its value and
+// that in tf2: true if either is true, otherwise unknown if either is
+// unknown, otherwise false.
+//
+// The receiver and passed TriFilter must be the same size or this will panic.
+//
+// The original TriFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the TriFilter from escaping to the
+// heap.
+func (tf TriFilter) Or(tf2 TriFilter) TriFilter {
+	tf.mustSameSize(tf2)
+	for i := range tf.known.bits {
+		truth := tf.truth.bits[i] | tf2.truth.bits[i]
+		falsity := tf.falsityWord(i) & tf2.falsityWord(i)
+		tf.truth.bits[i] = truth
+		tf.known.bits[i] = truth | falsity
+	}
+	return tf.recount()
+}
+
+// Not negates the known values, leaving the unknown ones unknown.
+//
+// The original TriFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the TriFilter from escaping to the
+// heap.
+func (tf TriFilter) Not() TriFilter {
+	for i := range tf.known.bits {
+		tf.truth.bits[i] = tf.falsityWord(i)
+	}
+	tf.truth.len = tf.known.len - tf.truth.len
+	return tf
+}
+
+// Known returns the QuickFilter of the indices whose value is known. The
+// returned QuickFilter is owned by the TriFilter and must not be modified.
+func (tf TriFilter) Known() QuickFilter {
+	return tf.known
+}
+
+// True returns the QuickFilter of the indices whose value is true, which is
+// what a WHERE clause selects. The returned QuickFilter is owned by the
+// TriFilter and must not be modified.
+func (tf TriFilter) True() QuickFilter {
+	return tf.truth
+}
+
+func (tf TriFilter) falsityWord(i int) uint {
+	return tf.known.bits[i] &^ tf.truth.bits[i]
+}
+
+func (tf TriFilter) recount() TriFilter {
+	tf.known = tf.known.Recount()
+	tf.truth = tf.truth.Recount()
+	return tf
+}
+
+func (tf TriFilter) mustSameSize(tf2 TriFilter) {
+	if tf.known.sourceLen != tf2.known.sourceLen {
+		panic("receiver and passed TriFilters must be the same size")
+	}
+}
@@ -0,0 +1,111 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func newTriFilter(values ...quickfilter.Tri) quickfilter.TriFilter {
+	tf := quickfilter.NewTriFilter(len(values))
+	for i, v := range values {
+		tf = tf.Set(i, v)
+	}
+	return tf
+}
+
+func triValues(tf quickfilter.TriFilter) []quickfilter.Tri {
+	values := make([]quickfilter.Tri, tf.Cap())
+	for i := range values {
+		values[i] = tf.Get(i)
+	}
+	return values
+}
+
+func TestTriFilter(t *testing.T) {
+	const (
+		U = quickfilter.TriUnknown
+		F = quickfilter.TriFalse
+		T = quickfilter.TriTrue
+	)
+	a := []quickfilter.Tri{T, T, T, F, F, F, U, U, U}
+	b := []quickfilter.Tri{T, F, U, T, F, U, T, F, U}
+	tests := []struct {
+		name     string
+		fn       func() quickfilter.TriFilter
+		expected []quickfilter.Tri
+	}{
+		{"And", func() quickfilter.TriFilter {
+			return newTriFilter(a...).And(newTriFilter(b...))
+		}, []quickfilter.Tri{T, F, U, F, F, F, U, F, U}},
+		{"Or", func() quickfilter.TriFilter {
+			return newTriFilter(a...).Or(newTriFilter(b...))
+		}, []quickfilter.Tri{T, T, T, T, F, U, T, U, U}},
+		{"Not", func() quickfilter.TriFilter {
+			return newTriFilter(a...).Not()
+		}, []quickfilter.Tri{F, F, F, T, T, T, U, U, U}},
+		{"NewTriFilterFrom", func() quickfilter.TriFilter {
+			truth := quickfilter.New(4).Add(0).Add(2)
+			known := quickfilter.New(4).Add(0).Add(1)
+			return quickfilter.NewTriFilterFrom(truth, known)
+		}, []quickfilter.Tri{T, F, U, U}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf := tt.fn()
+			received := triValues(tf)
+
+			if !reflect.DeepEqual(tt.expected, received) {
+				t.Errorf("expected %v, got %v", tt.expected, received)
+			}
+			if err := tf.Known().Validate(); err != nil {
+				t.Error(err)
+			}
+			if err := tf.True().Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("NOT should not conflate false and unknown", func(t *testing.T) {
+		tf := newTriFilter(T, F, U).Not()
+		expected := []int{1}
+
+		received := collect(tf.True())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	})
+
+	t.Run("Set same index repeatedly", func(t *testing.T) {
+		tf := quickfilter.NewTriFilter(10).Set(0, T).Set(0, F).Set(0, F).Set(1, T).Set(1, T)
+		expectedKnown, expectedTrue := 2, 1
+
+		tf = tf.Not()
+
+		if expectedKnown != tf.Known().Len() {
+			t.Errorf("expected %d, got %d", expectedKnown, tf.Known().Len())
+		}
+		if expectedTrue != tf.True().Len() {
+			t.Errorf("expected %d, got %d", expectedTrue, tf.True().Len())
+		}
+		if err := tf.Known().Validate(); err != nil {
+			t.Error(err)
+		}
+		if err := tf.True().Validate(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("mismatched sizes should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.NewTriFilter(3).And(quickfilter.NewTriFilter(4))
+	})
+}
@@ -0,0 +1,48 @@
+package quickfilter
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Validate checks the invariants of the QuickFilter: that Len() matches the
+// number of set bits, and that no bits are set beyond Cap() in the last word
+// of the backing buffer. It returns an error describing the first violation
+// found, or nil.
+//
+// Building with the quickfilter_debug build tag makes the mutating methods
+// validate their result and panic with the error on a violation, which
+// catches bugs such as stale bits close to where they are introduced:
+//
+//	go test -tags quickfilter_debug ./...
+func (qf QuickFilter) Validate() error {
+	if len(qf.bits) == 0 {
+		if qf.sourceLen != 0 || qf.len != 0 {
+			return fmt.Errorf("quickfilter: no backing buffer for Cap() %d and Len() %d", qf.sourceLen, qf.len)
+		}
+		return nil
+	}
+	lastIndex, _ := offsets(qf.sourceLen - 1)
+	if len(qf.bits) != lastIndex+1 {
+		return fmt.Errorf("quickfilter: %d words in backing buffer for Cap() %d, expected %d", len(qf.bits), qf.sourceLen, lastIndex+1)
+	}
+	last := len(qf.bits) - 1
+	if junk := qf.bits[last] &^ lastWordMask(qf.sourceLen); junk != 0 {
+		return fmt.Errorf("quickfilter: %d bits set beyond Cap() %d, mask %#x", bits.OnesCount(junk), qf.sourceLen, junk)
+	}
+	if count := qf.Recount().len; count != qf.len {
+		return fmt.Errorf("quickfilter: Len() is %d but %d bits are set", qf.len, count)
+	}
+	return nil
+}
+
+// debugValidate panics if debug checks are enabled and the QuickFilter
+// resulting from op is not valid.
+func (qf QuickFilter) debugValidate(op string) {
+	if !debugChecks {
+		return
+	}
+	if err := qf.Validate(); err != nil {
+		panic(fmt.Sprintf("%v after %s", err, op))
+	}
+}
@@ -0,0 +1,49 @@
+package quickfilter_test
+
+import (
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, qf := range []quickfilter.QuickFilter{
+			quickfilter.New(0),
+			quickfilter.NewFilled(70),
+			quickfilter.NewFromIndices(100, []int{1, 99}),
+			{},
+		} {
+			if err := qf.Validate(); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+		}
+	})
+
+	t.Run("Len mismatch", func(t *testing.T) {
+		qf := quickfilter.New(100).Add(3).Add(70)
+		// Discarding the result of Delete leaves qf sharing the modified
+		// buffer with a stale Len().
+		qf.Delete(70)
+		expected := "quickfilter: Len() is 2 but 1 bits are set"
+
+		err := qf.Validate()
+
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("bits beyond Cap", func(t *testing.T) {
+		qf := quickfilter.New(5)
+		// The grown QuickFilter shares the last word with qf.
+		qf.Resize(10).Add(7)
+		expected := "quickfilter: 1 bits set beyond Cap() 5, mask 0x80"
+
+		err := qf.Validate()
+
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected %q, got %v", expected, err)
+		}
+	})
+}
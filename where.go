@@ -0,0 +1,400 @@
+package quickfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Where compiles a restricted SQL WHERE clause over the indexed fields of the
+// BitmapIndex into an Expr, for example:
+//
+//	status = 'active' AND (region IN ('eu', 'us') OR NOT tier < 2)
+//
+// The supported grammar consists of comparisons of a field with a literal
+// using =, !=, <>, <, <=, > and >=, [NOT] IN with a parenthesized list of
+// literals, AND, OR, NOT and parentheses. Keywords are case-insensitive.
+// Literals are either numbers or single-quoted strings, where a doubled
+// quote escapes a quote. Numeric literals match values of any integer or
+// floating point type, and string literals match string values. A value of
+// a type incomparable with the literal fails every comparison, including !=
+// and NOT IN, and a NaN is unordered, failing every comparison but != and
+// NOT IN.
+//
+// Comparisons are evaluated against the distinct values of the field, so the
+// resulting Expr is a combination of the equality bitmaps of the index.
+func (bi BitmapIndex) Where(clause string) (Expr, error) {
+	p := whereParser{bi: bi, s: clause}
+	if err := p.next(); err != nil {
+		return Expr{}, err
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return Expr{}, err
+	}
+	if p.tok.kind != whereEOF {
+		return Expr{}, p.errorf("unexpected %q", p.tok.text)
+	}
+	return expr, nil
+}
+
+type whereTokenKind int
+
+const (
+	whereEOF whereTokenKind = iota
+	whereIdent
+	whereString
+	whereNumber
+	whereOp
+	wherePunct
+)
+
+type whereToken struct {
+	kind whereTokenKind
+	text string
+	pos  int
+}
+
+type whereParser struct {
+	bi  BitmapIndex
+	s   string
+	pos int
+	tok whereToken
+}
+
+func (p *whereParser) parseOr() (Expr, error) {
+	operand, err := p.parseAnd()
+	if err != nil || !p.isKeyword("OR") {
+		return operand, err
+	}
+	operands := []Expr{operand}
+	for p.isKeyword("OR") {
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		if operand, err = p.parseAnd(); err != nil {
+			return Expr{}, err
+		}
+		operands = append(operands, operand)
+	}
+	return Or(operands...), nil
+}
+
+func (p *whereParser) parseAnd() (Expr, error) {
+	operand, err := p.parseNot()
+	if err != nil || !p.isKeyword("AND") {
+		return operand, err
+	}
+	operands := []Expr{operand}
+	for p.isKeyword("AND") {
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		if operand, err = p.parseNot(); err != nil {
+			return Expr{}, err
+		}
+		operands = append(operands, operand)
+	}
+	return And(operands...), nil
+}
+
+func (p *whereParser) parseNot() (Expr, error) {
+	if p.isKeyword("NOT") {
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		operand, err := p.parseNot()
+		if err != nil {
+			return Expr{}, err
+		}
+		return Not(operand), nil
+	}
+	if p.tok.kind == wherePunct && p.tok.text == "(" {
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return Expr{}, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return Expr{}, err
+		}
+		return expr, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *whereParser) parsePredicate() (Expr, error) {
+	if p.tok.kind != whereIdent {
+		return Expr{}, p.unexpected()
+	}
+	field := p.tok
+	if _, ok := p.bi.fields[field.text]; !ok {
+		return Expr{}, &ParseError{Expr: p.s, Offset: field.pos, Msg: fmt.Sprintf("unknown field %q", field.text)}
+	}
+	if err := p.next(); err != nil {
+		return Expr{}, err
+	}
+	negate := false
+	if p.isKeyword("NOT") {
+		negate = true
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		if !p.isKeyword("IN") {
+			return Expr{}, p.errorf("expected IN")
+		}
+	}
+	if p.isKeyword("IN") {
+		if err := p.next(); err != nil {
+			return Expr{}, err
+		}
+		literals, err := p.parseList()
+		if err != nil {
+			return Expr{}, err
+		}
+		// IN matches as the OR of = with each literal and NOT IN as the AND
+		// of != with each literal.
+		return p.match(field.text, func(v interface{}) bool {
+			for _, literal := range literals {
+				if compareOp(v, literal, "=") {
+					return !negate
+				}
+				if negate && !compareOp(v, literal, "!=") {
+					return false
+				}
+			}
+			return negate
+		}), nil
+	}
+	if p.tok.kind != whereOp {
+		return Expr{}, p.errorf("expected comparison operator")
+	}
+	op := p.tok.text
+	if err := p.next(); err != nil {
+		return Expr{}, err
+	}
+	literal, err := p.parseLiteral()
+	if err != nil {
+		return Expr{}, err
+	}
+	return p.match(field.text, func(v interface{}) bool {
+		return compareOp(v, literal, op)
+	}), nil
+}
+
+func (p *whereParser) parseList() ([]interface{}, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var literals []interface{}
+	for {
+		literal, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		literals = append(literals, literal)
+		if p.tok.kind == wherePunct && p.tok.text == "," {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return literals, p.expectPunct(")")
+	}
+}
+
+func (p *whereParser) parseLiteral() (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case whereString:
+		return tok.text, p.next()
+	case whereNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		return f, p.next()
+	default:
+		return nil, p.errorf("expected literal")
+	}
+}
+
+// match returns an Expr of the elements whose field value satisfies pred.
+func (p *whereParser) match(field string, pred func(v interface{}) bool) Expr {
+	var operands []Expr
+	for v, qf := range p.bi.fields[field] {
+		if pred(v) {
+			operands = append(operands, Leaf(qf))
+		}
+	}
+	if len(operands) == 0 {
+		return Leaf(p.bi.empty)
+	}
+	return Or(operands...)
+}
+
+func (p *whereParser) isKeyword(keyword string) bool {
+	return p.tok.kind == whereIdent && strings.EqualFold(p.tok.text, keyword)
+}
+
+func (p *whereParser) expectPunct(punct string) error {
+	if p.tok.kind != wherePunct || p.tok.text != punct {
+		return p.errorf("expected %q", punct)
+	}
+	return p.next()
+}
+
+func (p *whereParser) unexpected() error {
+	if p.tok.kind == whereEOF {
+		return p.errorf("unexpected end of clause")
+	}
+	return p.errorf("unexpected %q", p.tok.text)
+}
+
+func (p *whereParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Expr: p.s, Offset: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next scans the next token.
+func (p *whereParser) next() error {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos == len(p.s) {
+		p.tok = whereToken{kind: whereEOF, pos: start}
+		return nil
+	}
+	c := p.s[p.pos]
+	switch {
+	case c == '(' || c == ')' || c == ',':
+		p.pos++
+		p.tok = whereToken{kind: wherePunct, text: p.s[start:p.pos], pos: start}
+	case c == '=' || c == '<' || c == '>' || c == '!':
+		p.pos++
+		if p.pos < len(p.s) && (p.s[p.pos] == '=' || (c == '<' && p.s[p.pos] == '>')) {
+			p.pos++
+		}
+		p.tok = whereToken{kind: whereOp, text: p.s[start:p.pos], pos: start}
+		if p.tok.text == "!" {
+			return &ParseError{Expr: p.s, Offset: start, Msg: "unexpected \"!\""}
+		}
+	case c == '\'':
+		var b strings.Builder
+		for p.pos++; ; p.pos++ {
+			if p.pos == len(p.s) {
+				return &ParseError{Expr: p.s, Offset: start, Msg: "unterminated string"}
+			}
+			if p.s[p.pos] == '\'' {
+				if p.pos+1 < len(p.s) && p.s[p.pos+1] == '\'' {
+					p.pos++
+				} else {
+					break
+				}
+			}
+			b.WriteByte(p.s[p.pos])
+		}
+		p.pos++
+		p.tok = whereToken{kind: whereString, text: b.String(), pos: start}
+	case c == '-' || c == '.' || '0' <= c && c <= '9':
+		for p.pos++; p.pos < len(p.s) && strings.IndexByte("0123456789.eE+-", p.s[p.pos]) >= 0; p.pos++ {
+		}
+		p.tok = whereToken{kind: whereNumber, text: p.s[start:p.pos], pos: start}
+	case isNameByte(c):
+		for p.pos < len(p.s) && isNameByte(p.s[p.pos]) {
+			p.pos++
+		}
+		p.tok = whereToken{kind: whereIdent, text: p.s[start:p.pos], pos: start}
+	default:
+		return &ParseError{Expr: p.s, Offset: start, Msg: fmt.Sprintf("unexpected %q", c)}
+	}
+	return nil
+}
+
+// compareOp reports whether a field value compares with a literal as op
+// does. A value of a type incomparable with the literal fails every
+// comparison, and a NaN value is unordered, failing every comparison but !=.
+func compareOp(v, literal interface{}, op string) bool {
+	c, ok := compareLiteral(v, literal)
+	if !ok {
+		return false
+	}
+	switch op {
+	case "=":
+		return c == 0
+	case "!=", "<>":
+		return c != 0
+	case "<":
+		return c == -1
+	case "<=":
+		return c == -1 || c == 0
+	case ">":
+		return c == 1
+	default:
+		return c == 1 || c == 0
+	}
+}
+
+// compareUnordered is the result of compareLiteral for a NaN value.
+const compareUnordered = 2
+
+// compareLiteral compares a field value with a literal, returning -1, 0, 1 or
+// compareUnordered, or false if they are of incomparable types.
+func compareLiteral(v, literal interface{}) (int, bool) {
+	switch literal := literal.(type) {
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(s, literal), true
+	case float64:
+		f, ok := toFloat64(v)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case f < literal:
+			return -1, true
+		case f > literal:
+			return 1, true
+		case f == literal:
+			return 0, true
+		default:
+			return compareUnordered, true
+		}
+	}
+	return 0, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package quickfilter_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestWhere(t *testing.T) {
+	bi := newUserIndex()
+
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			clause   string
+			expected []int
+		}{
+			{"status = 'active'", []int{0, 2, 3}},
+			{"status != 'active'", []int{1, 4}},
+			{"status <> 'active' OR tier >= 3", []int{1, 3, 4}},
+			{"tier < 2", []int{0, 4}},
+			{"tier > 1 and tier <= 2", []int{1, 2}},
+			{"region IN ('us', 'apac')", []int{2, 4}},
+			{"region NOT IN ('us')", []int{0, 1, 3}},
+			{"status = 'active' AND (region IN ('eu') OR NOT tier < 2)", []int{0, 2, 3}},
+			{"NOT (status = 'active')", []int{1, 4}},
+			{"status = 'it''s'", []int{}},
+			{"tier = 'one'", []int{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.clause, func(t *testing.T) {
+				expr, err := bi.Where(tt.clause)
+				if err != nil {
+					t.Fatal(err)
+				}
+				received := collect(expr.Eval(quickfilter.New(0)))
+
+				if !reflect.DeepEqual(tt.expected, received) {
+					t.Errorf("expected %v, got %v", tt.expected, received)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			clause         string
+			expectedOffset int
+		}{
+			{"", 0},
+			{"email = 'x'", 0},
+			{"status = ", 9},
+			{"status 'active'", 7},
+			{"status = 'active", 9},
+			{"status IN 'active'", 10},
+			{"(status = 'active'", 18},
+			{"status = 'active' tier", 18},
+		}
+		for _, tt := range tests {
+			t.Run(tt.clause, func(t *testing.T) {
+				_, err := bi.Where(tt.clause)
+				parseErr, ok := err.(*quickfilter.ParseError)
+				if !ok {
+					t.Fatalf("expected a *ParseError, got %v", err)
+				}
+
+				if tt.expectedOffset != parseErr.Offset {
+					t.Errorf("expected %d, got %d", tt.expectedOffset, parseErr.Offset)
+				}
+			})
+		}
+	})
+	t.Run("NaN and incomparable values", func(t *testing.T) {
+		scores := []interface{}{5.0, math.NaN(), 7.0, "n/a"}
+		bi := quickfilter.NewBitmapIndex(len(scores))
+		bi = bi.AddField("score", func(i int) interface{} { return scores[i] })
+		tests := []struct {
+			clause   string
+			expected []int
+		}{
+			{"score = 5", []int{0}},
+			{"score >= 5", []int{0, 2}},
+			{"score <= 5", []int{0}},
+			{"score != 5", []int{1, 2}},
+			{"score <> 5", []int{1, 2}},
+			{"score IN (5, 7)", []int{0, 2}},
+			{"score NOT IN (5)", []int{1, 2}},
+			{"score != 'n/a'", []int{}},
+			{"score NOT IN ('n/a', 5)", []int{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.clause, func(t *testing.T) {
+				expr, err := bi.Where(tt.clause)
+				if err != nil {
+					t.Fatal(err)
+				}
+				received := collect(expr.Eval(quickfilter.New(0)))
+
+				if !reflect.DeepEqual(tt.expected, received) {
+					t.Errorf("expected %v, got %v", tt.expected, received)
+				}
+			})
+		}
+	})
+}
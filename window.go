@@ -0,0 +1,96 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// WindowFilter is a sliding window filter, such as "items seen in the last N
+// minutes". It consists of a ring of buckets, each a QuickFilter of the
+// values added during one step of the window, and maintains the union of the
+// live buckets so that it can be queried like a regular QuickFilter.
+type WindowFilter struct {
+	buckets []QuickFilter
+	current int
+	union   QuickFilter
+}
+
+// NewWindowFilter returns a new WindowFilter of the given number of buckets
+// with enough space reserved to store sourceLen offsets in each.
+func NewWindowFilter(sourceLen, buckets int) WindowFilter {
+	if buckets < 1 {
+		panic("WindowFilter must have at least one bucket")
+	}
+	w := WindowFilter{
+		buckets: make([]QuickFilter, buckets),
+		union:   New(sourceLen),
+	}
+	for i := range w.buckets {
+		w.buckets[i] = New(sourceLen)
+	}
+	return w
+}
+
+// Add an index to the current bucket.
+//
+// The original WindowFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the WindowFilter from escaping to the
+// heap.
+func (w WindowFilter) Add(index int) WindowFilter {
+	if !w.buckets[w.current].Has(index) {
+		w.buckets[w.current] = w.buckets[w.current].Add(index)
+	}
+	if !w.union.Has(index) {
+		w.union = w.union.Add(index)
+	}
+	return w
+}
+
+// Advance the window by one bucket, expiring the values that were only set
+// in the oldest bucket. Only the words where the oldest bucket had values
+// set are recomputed.
+//
+// The original WindowFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the WindowFilter from escaping to the
+// heap.
+func (w WindowFilter) Advance() WindowFilter {
+	w.current = (w.current + 1) % len(w.buckets)
+	expired := w.buckets[w.current]
+	for i, word := range expired.bits {
+		if word == 0 {
+			continue
+		}
+		union := uint(0)
+		for j := range w.buckets {
+			if j != w.current {
+				union |= w.buckets[j].bits[i]
+			}
+		}
+		w.union.len += bits.OnesCount(union) - bits.OnesCount(w.union.bits[i])
+		w.union.bits[i] = union
+	}
+	w.buckets[w.current] = expired.Clear()
+	return w
+}
+
+// Has returns a boolean indicating whether the index is set in any of the
+// live buckets.
+func (w WindowFilter) Has(index int) bool {
+	return w.union.Has(index)
+}
+
+// Len returns the number of offsets set in any of the live buckets.
+func (w WindowFilter) Len() int {
+	return w.union.Len()
+}
+
+// Cap returns the maximum number of values that can be stored.
+func (w WindowFilter) Cap() int {
+	return w.union.Cap()
+}
+
+// Filter returns the union of the live buckets as a QuickFilter. The
+// returned QuickFilter is owned by the WindowFilter and must not be
+// modified; it is only valid until the next Add or Advance.
+func (w WindowFilter) Filter() QuickFilter {
+	return w.union
+}
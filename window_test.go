@@ -0,0 +1,66 @@
+package quickfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestWindowFilter(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		w := quickfilter.NewWindowFilter(100, 3)
+		expected := []int{1, 70}
+
+		w = w.Add(1).Add(70).Add(1)
+		received := collect(w.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if len(expected) != w.Len() {
+			t.Errorf("expected %d, got %d", len(expected), w.Len())
+		}
+	})
+
+	t.Run("Advance", func(t *testing.T) {
+		w := quickfilter.NewWindowFilter(100, 3)
+		expected := []int{2, 3, 4}
+
+		w = w.Add(1).Add(2)
+		w = w.Advance()
+		w = w.Add(3)
+		w = w.Advance()
+		w = w.Add(4)
+		w = w.Advance()
+		w = w.Add(2)
+		received := collect(w.Filter())
+
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+		if len(expected) != w.Len() {
+			t.Errorf("expected %d, got %d", len(expected), w.Len())
+		}
+	})
+
+	t.Run("value in a live bucket should not expire", func(t *testing.T) {
+		w := quickfilter.NewWindowFilter(100, 2)
+
+		w = w.Add(5)
+		w = w.Advance()
+		w = w.Add(5)
+		w = w.Advance()
+
+		if !w.Has(5) {
+			t.Error("expected Has to return true")
+		}
+		w = w.Advance()
+		if w.Has(5) {
+			t.Error("expected Has to return false")
+		}
+		if w.Len() != 0 {
+			t.Errorf("expected %d, got %d", 0, w.Len())
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package quickfilter
+
+import (
+	"math/bits"
+)
+
+// AndWords keeps only the values that are also set in the packed mask
+// words, where the value at index i is bit i%64 of words[i/64], such as the
+// output of a SIMD kernel or a foreign function. The bits of the last word
+// beyond Cap() are ignored.
+//
+// words must have a length of Cap() rounded up to a multiple of 64, divided
+// by 64, or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AndWords(words []uint64) QuickFilter {
+	qf.mustWordMask(words)
+	qf.len = 0
+	for i := range qf.bits {
+		qf.bits[i] &= qf.maskWord(words, i)
+		qf.len += bits.OnesCount(qf.bits[i])
+	}
+	return qf
+}
+
+// OrWords adds the values that are set in the packed mask words, like
+// AndWords.
+//
+// words must have a length of Cap() rounded up to a multiple of 64, divided
+// by 64, or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) OrWords(words []uint64) QuickFilter {
+	qf.mustWordMask(words)
+	for i := range qf.bits {
+		word := qf.maskWord(words, i)
+		qf.len += bits.OnesCount(word &^ qf.bits[i])
+		qf.bits[i] |= word
+	}
+	return qf
+}
+
+// AndNotWords deletes the values that are set in the packed mask words, like
+// AndWords.
+//
+// words must have a length of Cap() rounded up to a multiple of 64, divided
+// by 64, or this will panic.
+//
+// The original QuickFilter is no longer usable and must be replaced with the
+// returned one. This approach prevents the QuickFilter from escaping to the
+// heap.
+func (qf QuickFilter) AndNotWords(words []uint64) QuickFilter {
+	qf.mustWordMask(words)
+	for i := range qf.bits {
+		word := qf.maskWord(words, i)
+		qf.len -= bits.OnesCount(word & qf.bits[i])
+		qf.bits[i] &^= word
+	}
+	return qf
+}
+
+func (qf QuickFilter) mustWordMask(words []uint64) {
+	if len(words) != (qf.sourceLen+63)/64 {
+		panic("words must be the same size as the QuickFilter")
+	}
+}
+
+// maskWord returns the bits of the packed mask words for the word at index,
+// with the bits beyond Cap() cleared.
+func (qf QuickFilter) maskWord(words []uint64, index int) uint {
+	if index == len(qf.bits)-1 {
+		return rawMaskWord(words, index) & lastWordMask(qf.sourceLen)
+	}
+	return rawMaskWord(words, index)
+}
+
+func rawMaskWord(words []uint64, index int) uint {
+	const wordsPerUint64 = 64 / bits.UintSize
+	i := index / wordsPerUint64
+	if i >= len(words) {
+		return 0
+	}
+	return uint(words[i] >> (uint(index%wordsPerUint64) * bits.UintSize))
+}
@@ -0,0 +1,57 @@
+package quickfilter_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jussi-kalliokoski/quickfilter"
+)
+
+func TestWords(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []uint64{rng.Uint64(), rng.Uint64(), rng.Uint64(), ^uint64(0)}
+	masked := func(i int) bool {
+		return words[i/64]&(1<<uint(i%64)) != 0
+	}
+	qf := quickfilter.NewRandom(203, 0.5, rng)
+	tests := []struct {
+		name  string
+		apply func(qf quickfilter.QuickFilter) quickfilter.QuickFilter
+		keep  func(has, masked bool) bool
+	}{
+		{"AndWords", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.AndWords(words) }, func(has, masked bool) bool { return has && masked }},
+		{"OrWords", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.OrWords(words) }, func(has, masked bool) bool { return has || masked }},
+		{"AndNotWords", func(qf quickfilter.QuickFilter) quickfilter.QuickFilter { return qf.AndNotWords(words) }, func(has, masked bool) bool { return has && !masked }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := []int{}
+			for i := 0; i < qf.Cap(); i++ {
+				if tt.keep(qf.Has(i), masked(i)) {
+					expected = append(expected, i)
+				}
+			}
+
+			result := tt.apply(qf.Copy())
+			received := collect(result)
+
+			if !reflect.DeepEqual(expected, received) {
+				t.Errorf("expected %v, got %v", expected, received)
+			}
+			if err := result.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("size mismatch should panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		quickfilter.New(65).AndWords([]uint64{0})
+	})
+}